@@ -205,43 +205,41 @@ func (cdc *Codec) MarshalBinaryBare(o interface{}) ([]byte, error) {
 	}
 
 	// Encode Amino:binary bytes.
-	var bz []byte
-	buf := new(bytes.Buffer)
+	buf := new(messageBuffer)
 	rt := rv.Type()
 	info, err := cdc.getTypeInfoWlock(rt)
 	if err != nil {
 		return nil, err
 	}
-	// in the case of of a repeated struct (e.g. type Alias []SomeStruct),
-	// we do not need to prepend with `(field_number << 3) | wire_type` as this
-	// would need to be done for each struct and not only for the first.
-	if rv.Kind() != reflect.Struct && !isStructOrRepeatedStruct(info) {
-		writeEmpty := false
-		typ3 := typeToTyp3(info.Type, FieldOptions{})
-		bare := typ3 != Typ3ByteLength
-		if err = cdc.writeFieldIfNotEmpty(buf, 1, info, FieldOptions{}, FieldOptions{}, rv, writeEmpty, bare); err != nil {
-			return nil, err
-		}
-		bz = buf.Bytes()
-	} else {
-		err = cdc.encodeReflectBinary(buf, info, rv, FieldOptions{BinFieldNum: 1}, true)
-		if err != nil {
-			return nil, err
-		}
-		bz = buf.Bytes()
+	if err = cdc.encodeBinaryBare(buf, info, rv); err != nil {
+		return nil, err
 	}
-	// If registered concrete, prepend prefix bytes.
+	return buf.Bytes(), nil
+}
+
+// Writes the encoding of MarshalBinaryBare to w.
+func (cdc *Codec) encodeBinaryBare(w messageWriter, info *TypeInfo, rv reflect.Value) (err error) {
+	// If registered concrete, write prefix bytes.
 	if info.Registered {
 		// TODO: https://github.com/tendermint/go-amino/issues/267
 		//return MarshalBinaryBare(RegisteredAny{
 		//	AminoPreOrDisfix: info.Prefix.Bytes(),
 		//	Value: bz,
 		//})
-		pb := info.Prefix.Bytes()
-		bz = append(pb, bz...)
+		if _, err = w.Write(info.Prefix.Bytes()); err != nil {
+			return
+		}
 	}
-
-	return bz, nil
+	// in the case of of a repeated struct (e.g. type Alias []SomeStruct),
+	// we do not need to prepend with `(field_number << 3) | wire_type` as this
+	// would need to be done for each struct and not only for the first.
+	if rv.Kind() != reflect.Struct && !isStructOrRepeatedStruct(info) {
+		writeEmpty := false
+		typ3 := typeToTyp3(info.Type, FieldOptions{})
+		bare := typ3 != Typ3ByteLength
+		return cdc.writeFieldIfNotEmpty(w, 1, info, FieldOptions{}, FieldOptions{BinFieldNum: 1}, rv, writeEmpty, bare)
+	}
+	return cdc.encodeReflectBinary(w, info, rv, FieldOptions{BinFieldNum: 1}, true)
 }
 
 //type RegisteredAny struct {
@@ -359,6 +357,22 @@ func (cdc *Codec) MustUnmarshalBinaryLengthPrefixed(bz []byte, ptr interface{})
 
 // UnmarshalBinaryBare will panic if ptr is a nil-pointer.
 func (cdc *Codec) UnmarshalBinaryBare(bz []byte, ptr interface{}) error {
+	return cdc.unmarshalBinaryBare(bz, ptr, false)
+}
+
+// UnmarshalBinaryMerge is like UnmarshalBinaryBare, but merges the decoded
+// value into the existing value of ptr with proto merge semantics:
+//   - fields present in bz overwrite scalar fields,
+//   - lists are appended to,
+//   - structs, and interfaces holding the same concrete type, are merged recursively,
+//   - fields absent from bz are left untouched.
+//
+// This is useful for applying partial updates to existing state.
+func (cdc *Codec) UnmarshalBinaryMerge(bz []byte, ptr interface{}) error {
+	return cdc.unmarshalBinaryBare(bz, ptr, true)
+}
+
+func (cdc *Codec) unmarshalBinaryBare(bz []byte, ptr interface{}, merge bool) error {
 
 	rv := reflect.ValueOf(ptr)
 	if rv.Kind() != reflect.Ptr {
@@ -421,7 +435,7 @@ func (cdc *Codec) UnmarshalBinaryBare(bz []byte, ptr interface{}) error {
 	}
 
 	// Decode contents into rv.
-	n, err := cdc.decodeReflectBinary(bz, info, rv, FieldOptions{BinFieldNum: 1}, bare)
+	n, err := cdc.decodeReflectBinary(bz, info, rv, FieldOptions{BinFieldNum: 1}, bare, merge)
 	if err != nil {
 		return fmt.Errorf(
 			"unmarshal to %v failed after %d bytes (%v): %X",
@@ -497,33 +511,11 @@ func (cdc *Codec) MarshalJSON(o interface{}) ([]byte, error) {
 	if rv.Kind() == reflect.Invalid {
 		return []byte("null"), nil
 	}
-	rt := rv.Type()
+	// Write the disfix wrapper too if it is a registered concrete type.
 	w := new(bytes.Buffer)
-	info, err := cdc.getTypeInfoWlock(rt)
-	if err != nil {
+	if err := cdc.EncodeFormat(w, newJSONFormat(), o); err != nil {
 		return nil, err
 	}
-
-	// Write the disfix wrapper if it is a registered concrete type.
-	if info.Registered {
-		err = writeStr(w, _fmt(`{"type":"%s","value":`, info.Name))
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// Write the rest from rv.
-	if err = cdc.encodeReflectJSON(w, info, rv, FieldOptions{}); err != nil {
-		return nil, err
-	}
-
-	// disfix wrapper continued...
-	if info.Registered {
-		err = writeStr(w, `}`)
-		if err != nil {
-			return nil, err
-		}
-	}
 	return w.Bytes(), nil
 }
 
@@ -537,6 +529,16 @@ func (cdc *Codec) MustMarshalJSON(o interface{}) []byte {
 }
 
 func (cdc *Codec) UnmarshalJSON(bz []byte, ptr interface{}) error {
+	return cdc.unmarshalJSON(bz, ptr, false)
+}
+
+// UnmarshalJSONMerge is like UnmarshalJSON, but merges the decoded value into
+// the existing value of ptr.  See UnmarshalBinaryMerge for the semantics.
+func (cdc *Codec) UnmarshalJSONMerge(bz []byte, ptr interface{}) error {
+	return cdc.unmarshalJSON(bz, ptr, true)
+}
+
+func (cdc *Codec) unmarshalJSON(bz []byte, ptr interface{}, merge bool) error {
 	if len(bz) == 0 {
 		return errors.New("cannot decode empty bytes")
 	}
@@ -564,7 +566,7 @@ func (cdc *Codec) UnmarshalJSON(bz []byte, ptr interface{}) error {
 		}
 		bz = data
 	}
-	return cdc.decodeReflectJSON(bz, info, rv, FieldOptions{})
+	return cdc.decodeReflectJSON(bz, info, rv, FieldOptions{}, merge)
 }
 
 // MustUnmarshalJSON panics if an error occurs. Besides that behaves exactly like UnmarshalJSON.
@@ -588,3 +590,73 @@ func (cdc *Codec) MarshalJSONIndent(o interface{}, prefix, indent string) ([]byt
 	}
 	return out.Bytes(), nil
 }
+
+// MarshalText encodes o in the amino text format, a human-readable form of
+// the JSON encoding.  Interface values are written as `@name value`, e.g.
+//
+//	@bank/MsgSend {
+//	  from: "cosmos1..."
+//	  amount: [10, 20]
+//	  time: "2019-01-01T00:00:00Z"
+//	  memo: 0x0102
+//	}
+//
+// Unlike JSON, integers are never quoted, bytes are written in hex, and map
+// keys are sorted.  Lines may end with a "#" comment.
+func (cdc *Codec) MarshalText(o interface{}) ([]byte, error) {
+	w := new(bytes.Buffer)
+	if err := cdc.EncodeFormat(w, newTextFormat(), o); err != nil {
+		return nil, err
+	}
+	if err := writeStr(w, "\n"); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+// MustMarshalText panics if an error occurs. Besides that behaves exactly like MarshalText.
+func (cdc *Codec) MustMarshalText(o interface{}) []byte {
+	bz, err := cdc.MarshalText(o)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// UnmarshalText decodes bz, in the amino text format, into ptr.  See
+// MarshalText.
+func (cdc *Codec) UnmarshalText(bz []byte, ptr interface{}) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("expected a pointer")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	info, err := cdc.getTypeInfoWlock(rt)
+	if err != nil {
+		return err
+	}
+	tv, err := parseText(bz)
+	if err != nil {
+		return err
+	}
+	// If registered concrete, consume and verify the name.
+	if info.Registered {
+		if tv.kind != textInterface {
+			return errors.Errorf("wanted to decode @%v but found %v", info.Name, tv.kindString())
+		}
+		// Check name against info.
+		if tv.name != info.Name {
+			return errors.Errorf("wanted to decode %v but found %v", info.Name, tv.name)
+		}
+		tv = tv.value
+	}
+	return cdc.decodeReflectText(tv, info, rv, FieldOptions{})
+}
+
+// MustUnmarshalText panics if an error occurs. Besides that behaves exactly like UnmarshalText.
+func (cdc *Codec) MustUnmarshalText(bz []byte, ptr interface{}) {
+	if err := cdc.UnmarshalText(bz, ptr); err != nil {
+		panic(err)
+	}
+}
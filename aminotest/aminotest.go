@@ -0,0 +1,161 @@
+// Package aminotest provides assertions, in the style of testify's assert
+// package, of the properties every amino type should have: that it survives
+// a round trip through each encoding, that its encodings are canonical, and
+// that its binary and JSON encodings agree.  FuzzRegistered checks all of
+// them for samples of every type registered with a codec.
+//
+//	func TestCodec(t *testing.T) {
+//		aminotest.FuzzRegistered(t, cdc, 100)
+//	}
+package aminotest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/vectors"
+)
+
+type tHelper interface {
+	Helper()
+}
+
+// AssertRoundTripBinary asserts that v, a value or a pointer to one, decodes
+// from its binary encoding to an equal value, as amino.Equal.
+func AssertRoundTripBinary(t assert.TestingT, cdc *amino.Codec, v interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	return assertRoundTrip(t, "binary", cdc.MarshalBinaryBare, cdc.UnmarshalBinaryBare, v)
+}
+
+// AssertRoundTripJSON asserts that v, a value or a pointer to one, decodes
+// from its JSON encoding to an equal value, as amino.Equal.
+func AssertRoundTripJSON(t assert.TestingT, cdc *amino.Codec, v interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	return assertRoundTrip(t, "JSON", cdc.MarshalJSON, cdc.UnmarshalJSON, v)
+}
+
+// AssertCanonical asserts that the binary and JSON encodings of v are
+// canonical, i.e. that encoding v, decoding it, and encoding it again gives
+// the same bytes.  Unlike a round trip, v needn't decode to an equal value,
+// e.g. an `amino:"-json"` field decodes as zero from JSON.
+func AssertCanonical(t assert.TestingT, cdc *amino.Codec, v interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	ok := assertCanonical(t, "binary", cdc.MarshalBinaryBare, cdc.UnmarshalBinaryBare, v)
+	return assertCanonical(t, "JSON", cdc.MarshalJSON, cdc.UnmarshalJSON, v) && ok
+}
+
+// AssertBinaryJSONEquivalence asserts that v decodes to equal values from its
+// binary and JSON encodings, as amino.Equal.
+func AssertBinaryJSONEquivalence(t assert.TestingT, cdc *amino.Codec, v interface{}) bool {
+	if h, ok := t.(tHelper); ok {
+		h.Helper()
+	}
+	fromBinary, _, ok := decodeEncoded(t, "binary", cdc.MarshalBinaryBare, cdc.UnmarshalBinaryBare, v)
+	if !ok {
+		return false
+	}
+	fromJSON, _, ok := decodeEncoded(t, "JSON", cdc.MarshalJSON, cdc.UnmarshalJSON, v)
+	if !ok {
+		return false
+	}
+	return assertAminoEqual(t, fromBinary, fromJSON, fmt.Sprintf("%T decodes differently from binary and JSON", v))
+}
+
+// FuzzRegistered runs all of the assertions above, as a subtest, on each of
+// n samples of every type registered with cdc (see vectors.Samples).  The
+// samples are made with a fixed seed, so that failures are reproducible.
+func FuzzRegistered(t *testing.T, cdc *amino.Codec, n int) {
+	t.Helper()
+	if n <= 0 {
+		t.Fatalf("FuzzRegistered needs a positive number of samples, got %v", n)
+	}
+	samples, err := vectors.Samples(cdc, &vectors.Options{Samples: n})
+	require.NoError(t, err)
+	for i, ptr := range samples {
+		ptr := ptr
+		name := fmt.Sprintf("%v/%v", reflect.TypeOf(ptr).Elem(), i%n)
+		t.Run(name, func(t *testing.T) {
+			AssertRoundTripBinary(t, cdc, ptr)
+			AssertRoundTripJSON(t, cdc, ptr)
+			AssertCanonical(t, cdc, ptr)
+			AssertBinaryJSONEquivalence(t, cdc, ptr)
+		})
+	}
+}
+
+//----------------------------------------
+// Misc.
+
+type marshalFunc func(o interface{}) ([]byte, error)
+type unmarshalFunc func(bz []byte, ptr interface{}) error
+
+func assertRoundTrip(t assert.TestingT, enc string, marshal marshalFunc, unmarshal unmarshalFunc, v interface{}) bool {
+	decoded, _, ok := decodeEncoded(t, enc, marshal, unmarshal, v)
+	if !ok {
+		return false
+	}
+	return assertAminoEqual(t, reflect.Indirect(reflect.ValueOf(v)).Interface(), decoded,
+		fmt.Sprintf("%T changes in a %v round trip", v, enc))
+}
+
+// Asserts that expected and actual are equal as amino values (see
+// amino.Equal), e.g. times in any location, or nil and empty slices.
+func assertAminoEqual(t assert.TestingT, expected, actual interface{}, msg string) bool {
+	diffs := amino.Diff(expected, actual)
+	if len(diffs) == 0 {
+		return true
+	}
+	var lines []string
+	for _, diff := range diffs {
+		lines = append(lines, diff.String())
+	}
+	return assert.Fail(t, msg, strings.Join(lines, "\n"))
+}
+
+func assertCanonical(t assert.TestingT, enc string, marshal marshalFunc, unmarshal unmarshalFunc, v interface{}) bool {
+	decoded, bz, ok := decodeEncoded(t, enc, marshal, unmarshal, v)
+	if !ok {
+		return false
+	}
+	bz2, err := marshal(decoded)
+	if !assert.NoError(t, err, "cannot encode decoded %T in %v", v, enc) {
+		return false
+	}
+	if !bytes.Equal(bz, bz2) {
+		return assert.Fail(t, fmt.Sprintf("%T is not canonical in %v", v, enc),
+			"encoded:   %X\nreencoded: %X", bz, bz2)
+	}
+	return true
+}
+
+// Encodes v and decodes it into a new value of the same type, which is
+// returned (not a pointer to it) with the encoding.
+func decodeEncoded(t assert.TestingT, enc string, marshal marshalFunc, unmarshal unmarshalFunc,
+	v interface{}) (decoded interface{}, bz []byte, ok bool) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return nil, nil, assert.Fail(t, "expected a value or a non-nil pointer")
+	}
+	bz, err := marshal(v)
+	if !assert.NoError(t, err, "cannot encode %T in %v", v, enc) {
+		return nil, nil, false
+	}
+	ptr := reflect.New(reflect.Indirect(rv).Type())
+	if !assert.NoError(t, unmarshal(bz, ptr.Interface()), "cannot decode %T from %v", v, enc) {
+		return nil, nil, false
+	}
+	return ptr.Elem().Interface(), bz, true
+}
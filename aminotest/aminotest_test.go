@@ -0,0 +1,99 @@
+package aminotest_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/aminotest"
+	"github.com/tendermint/go-amino/tests"
+)
+
+// Records the failures of assertions.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFuzzRegistered(t *testing.T) {
+	cdc := amino.NewCodec()
+	for _, ptr := range tests.StructTypes {
+		cdc.RegisterConcrete(ptr, "tests/"+reflect.TypeOf(ptr).Elem().Name(), nil)
+	}
+	for _, ptr := range tests.DefTypes {
+		cdc.RegisterConcrete(ptr, "tests/"+reflect.TypeOf(ptr).Elem().Name(), nil)
+	}
+	cdc.RegisterInterface((*tests.Interface1)(nil), nil)
+	cdc.RegisterConcrete((*tests.InterfaceFieldsStruct)(nil), "tests/InterfaceFieldsStruct", nil)
+	cdc.RegisterConcrete(tests.Concrete1{}, "tests/Concrete1", nil)
+	cdc.RegisterConcrete(tests.Concrete2{}, "tests/Concrete2", nil)
+	cdc.RegisterConcrete(tests.ConcreteWrappedBytes{}, "tests/ConcreteWrappedBytes", nil)
+	// NOTE: Not ConcreteTypeDef, the other Interface1, as a byte array in an
+	// interface field doesn't decode in binary.
+
+	aminotest.FuzzRegistered(t, cdc, 20)
+}
+
+type pointers struct {
+	Int8Pt *int8
+	Bytes  []byte
+	Ints   []int8
+	Time   time.Time
+	Binary int8 `amino:"-json"`
+}
+
+func TestAssertions(t *testing.T) {
+	cdc := amino.NewCodec()
+	zero := int8(0)
+	one := int8(1)
+	inZone := time.Date(2018, 1, 2, 3, 4, 5, 6, time.FixedZone("UTC+1", 60*60))
+
+	cases := []struct {
+		name   string
+		assert func(assert.TestingT, *amino.Codec, interface{}) bool
+		v      interface{}
+		errs   []string
+	}{
+		{"RoundTripBinary", aminotest.AssertRoundTripBinary, pointers{Int8Pt: &one}, nil},
+		// Pointers to zero and empty slices aren't encoded in binary, so decode
+		// as nil, which amino.Equal doesn't tell apart.
+		{"RoundTripBinary", aminotest.AssertRoundTripBinary, &pointers{Int8Pt: &zero, Ints: []int8{}}, nil},
+		{"RoundTripBinary", aminotest.AssertRoundTripBinary, &pointers{Binary: 1}, nil},
+		{"RoundTripJSON", aminotest.AssertRoundTripJSON, &pointers{Int8Pt: &zero}, nil},
+		// Times decode in UTC.
+		{"RoundTripJSON", aminotest.AssertRoundTripJSON, &pointers{Time: inZone}, nil},
+		{"RoundTripJSON", aminotest.AssertRoundTripJSON, &pointers{Binary: 1},
+			[]string{"*aminotest_test.pointers changes in a JSON round trip"}},
+		{"RoundTripJSON", aminotest.AssertRoundTripJSON, (*pointers)(nil),
+			[]string{"expected a value or a non-nil pointer"}},
+		{"Canonical", aminotest.AssertCanonical, &pointers{Int8Pt: &zero}, nil},
+		// Empty bytes are written as "", but decode as nil, written as null.
+		{"Canonical", aminotest.AssertCanonical, &pointers{Bytes: []byte{}},
+			[]string{"*aminotest_test.pointers is not canonical in JSON"}},
+		{"Canonical", aminotest.AssertCanonical, struct{ I tests.Interface1 }{tests.Concrete1{}}, []string{
+			"cannot encode struct { I tests.Interface1 } in binary",
+			"cannot encode struct { I tests.Interface1 } in JSON",
+		}},
+		{"BinaryJSONEquivalence", aminotest.AssertBinaryJSONEquivalence, &pointers{Int8Pt: &one}, nil},
+		{"BinaryJSONEquivalence", aminotest.AssertBinaryJSONEquivalence, &pointers{Int8Pt: &zero}, nil},
+		{"BinaryJSONEquivalence", aminotest.AssertBinaryJSONEquivalence, &pointers{Binary: 1},
+			[]string{"*aminotest_test.pointers decodes differently from binary and JSON"}},
+	}
+	for _, tc := range cases {
+		r := new(recorder)
+		ok := tc.assert(r, cdc, tc.v)
+		assert.Equal(t, len(tc.errs) == 0, ok, tc.name)
+		if assert.Len(t, r.errors, len(tc.errs), tc.name) {
+			for i, err := range tc.errs {
+				assert.Contains(t, r.errors[i], err, tc.name)
+			}
+		}
+	}
+}
@@ -27,9 +27,11 @@ const (
 // This is the main entrypoint for decoding all types from binary form. This
 // function calls decodeReflectBinary*, and generally those functions should
 // only call this one, for the prefix bytes are consumed here when present.
+// If merge is true, the decoded value is merged into rv with proto merge
+// semantics (see UnmarshalBinaryMerge).
 // CONTRACT: rv.CanAddr() is true.
 func (cdc *Codec) decodeReflectBinary(bz []byte, info *TypeInfo,
-	rv reflect.Value, fopts FieldOptions, bare bool) (n int, err error) {
+	rv reflect.Value, fopts FieldOptions, bare bool, merge bool) (n int, err error) {
 
 	if !rv.CanAddr() {
 		panic("rv not addressable")
@@ -38,8 +40,8 @@ func (cdc *Codec) decodeReflectBinary(bz []byte, info *TypeInfo,
 		panic("should not happen")
 	}
 	if printLog {
-		spew.Printf("(D) decodeReflectBinary(bz: %X, info: %v, rv: %#v (%v), fopts: %v)\n",
-			bz, info, rv.Interface(), rv.Type(), fopts)
+		spew.Printf("(D) decodeReflectBinary(bz: %X, info: %v, rv: %#v (%v), fopts: %v, merge: %v)\n",
+			bz, info, rv.Interface(), rv.Type(), fopts, merge)
 		defer func() {
 			fmt.Printf("(D) -> n: %v, err: %v\n", n, err)
 		}()
@@ -61,13 +63,17 @@ func (cdc *Codec) decodeReflectBinary(bz []byte, info *TypeInfo,
 	// Handle override if a pointer to rv implements UnmarshalAmino.
 	if info.IsAminoUnmarshaler {
 		// First, decode repr instance from bytes.
-		rrv := reflect.New(info.AminoUnmarshalReprType).Elem()
+		var rrv reflect.Value
+		rrv, err = newReprObjectMerge(info, rv, merge)
+		if err != nil {
+			return
+		}
 		var rinfo *TypeInfo
 		rinfo, err = cdc.getTypeInfoWlock(info.AminoUnmarshalReprType)
 		if err != nil {
 			return
 		}
-		_n, err = cdc.decodeReflectBinary(bz, rinfo, rrv, fopts, bare)
+		_n, err = cdc.decodeReflectBinary(bz, rinfo, rrv, fopts, bare, merge)
 		if slide(&bz, &n, _n) && err != nil {
 			return
 		}
@@ -87,7 +93,7 @@ func (cdc *Codec) decodeReflectBinary(bz []byte, info *TypeInfo,
 	// Complex
 
 	case reflect.Interface:
-		_n, err = cdc.decodeReflectBinaryInterface(bz, info, rv, fopts, bare)
+		_n, err = cdc.decodeReflectBinaryInterface(bz, info, rv, fopts, bare, merge)
 		n += _n
 		return
 
@@ -108,13 +114,13 @@ func (cdc *Codec) decodeReflectBinary(bz []byte, info *TypeInfo,
 			_n, err = cdc.decodeReflectBinaryByteSlice(bz, info, rv, fopts)
 			n += _n
 		} else {
-			_n, err = cdc.decodeReflectBinarySlice(bz, info, rv, fopts, bare)
+			_n, err = cdc.decodeReflectBinarySlice(bz, info, rv, fopts, bare, merge)
 			n += _n
 		}
 		return
 
 	case reflect.Struct:
-		_n, err = cdc.decodeReflectBinaryStruct(bz, info, rv, fopts, bare)
+		_n, err = cdc.decodeReflectBinaryStruct(bz, info, rv, fopts, bare, merge)
 		n += _n
 		return
 
@@ -295,6 +301,15 @@ func (cdc *Codec) decodeReflectBinary(bz []byte, info *TypeInfo,
 		rv.SetFloat(float64(f))
 		return
 
+	case reflect.Complex128, reflect.Complex64:
+		if !fopts.Unsafe {
+			err = errors.New("complex support requires `amino:\"unsafe\"`")
+			return
+		}
+		_n, err = cdc.decodeReflectBinaryComplex(bz, info, rv, fopts, bare)
+		n += _n
+		return
+
 	case reflect.String:
 		var str string
 		str, _n, err = DecodeString(bz)
@@ -312,7 +327,7 @@ func (cdc *Codec) decodeReflectBinary(bz []byte, info *TypeInfo,
 
 // CONTRACT: rv.CanAddr() is true.
 func (cdc *Codec) decodeReflectBinaryInterface(bz []byte, iinfo *TypeInfo, rv reflect.Value,
-	fopts FieldOptions, bare bool) (n int, err error) {
+	fopts FieldOptions, bare bool, merge bool) (n int, err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -322,7 +337,7 @@ func (cdc *Codec) decodeReflectBinaryInterface(bz []byte, iinfo *TypeInfo, rv re
 			fmt.Printf("(d) -> err: %v\n", err)
 		}()
 	}
-	if !rv.IsNil() {
+	if !rv.IsNil() && !merge {
 		// JAE: Heed this note, this is very tricky.
 		// I've forgotten the reason a second time,
 		// but I'm pretty sure that reason exists.
@@ -366,7 +381,8 @@ func (cdc *Codec) decodeReflectBinaryInterface(bz []byte, iinfo *TypeInfo, rv re
 	}
 
 	// Construct the concrete type.
-	var crv, irvSet = constructConcreteType(cinfo)
+	// When merging into a value of the same concrete type, start from it.
+	var crv, irvSet = constructConcreteTypeMerge(cinfo, rv, merge)
 	isKnownType := (cinfo.Type.Kind() != reflect.Map) && (cinfo.Type.Kind() != reflect.Func)
 	if !isStructOrRepeatedStruct(cinfo) &&
 		!isPointerToStructOrToRepeatedStruct(crv, cinfo.Type) &&
@@ -395,7 +411,7 @@ func (cdc *Codec) decodeReflectBinaryInterface(bz []byte, iinfo *TypeInfo, rv re
 	}
 
 	// Decode into the concrete type.
-	_n, err = cdc.decodeReflectBinary(bz, cinfo, crv, fopts, true)
+	_n, err = cdc.decodeReflectBinary(bz, cinfo, crv, fopts, true, merge)
 	if slide(&bz, &n, _n) && err != nil {
 		rv.Set(irvSet) // Helps with debugging
 		return
@@ -501,7 +517,7 @@ func (cdc *Codec) decodeReflectBinaryArray(bz []byte, info *TypeInfo, rv reflect
 		for i := 0; i < length; i++ {
 			erv := rv.Index(i)
 			var _n int
-			_n, err = cdc.decodeReflectBinary(bz, einfo, erv, fopts, false)
+			_n, err = cdc.decodeReflectBinary(bz, einfo, erv, fopts, false, false)
 			if slide(&bz, &n, _n) && err != nil {
 				err = fmt.Errorf("error reading array contents: %v", err)
 				return
@@ -560,10 +576,15 @@ func (cdc *Codec) decodeReflectBinaryArray(bz []byte, info *TypeInfo, rv reflect
 				continue
 			}
 			// Normal case, read next non-nil element from bz.
-			// In case of any inner lists in unpacked form.
-			efopts := fopts
-			efopts.BinFieldNum = 1
-			_n, err = cdc.decodeReflectBinary(bz, einfo, erv, efopts, false)
+			if isPackedNestedList(einfo) {
+				// Read the inner list from field 1 of a wrapper message.
+				_n, err = cdc.decodeReflectBinaryNestedList(bz, einfo, erv, fopts)
+			} else {
+				// In case of any inner lists in unpacked form.
+				efopts := fopts
+				efopts.BinFieldNum = 1
+				_n, err = cdc.decodeReflectBinary(bz, einfo, erv, efopts, false, false)
+			}
 			if slide(&bz, &n, _n) && err != nil {
 				err = fmt.Errorf("error reading array contents: %v", err)
 				return
@@ -587,6 +608,84 @@ func (cdc *Codec) decodeReflectBinaryArray(bz []byte, info *TypeInfo, rv reflect
 	return n, err
 }
 
+// CONTRACT: rv.CanAddr() is true.
+// CONTRACT: isPackedNestedList(info)
+// See encodeReflectBinaryNestedList for the encoding.
+func (cdc *Codec) decodeReflectBinaryNestedList(bz []byte, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (n int, err error) {
+	if !rv.CanAddr() {
+		panic("rv not addressable")
+	}
+	if printLog {
+		fmt.Println("(d) decodeReflectBinaryNestedList")
+		defer func() {
+			fmt.Printf("(d) -> err: %v\n", err)
+		}()
+	}
+
+	// Read byte-length prefixed wrapper message.
+	var (
+		buf []byte
+		_n  int
+	)
+	buf, _n, err = DecodeByteSlice(bz)
+	if slide(&bz, nil, _n) && err != nil {
+		return
+	}
+	// This is a trick for debuggability -- we slide on &n more later.
+	n += UvarintSize(uint64(len(buf)))
+	bz = buf
+
+	// Construct pointers all the way down to the list.
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	// An empty wrapper message is an empty inner list.
+	if len(bz) == 0 {
+		rv.Set(defaultValue(rv.Type()))
+		return
+	}
+
+	// Read field key (number and type).
+	var (
+		fnum uint32
+		typ  Typ3
+	)
+	fnum, typ, _n, err = decodeFieldNumberAndTyp3(bz)
+	if slide(&bz, &n, _n) && err != nil {
+		return
+	}
+	if fnum != 1 {
+		err = fmt.Errorf("expected field # 1 of nested list %v, got %v", info.Type, fnum)
+		return
+	}
+	if typ != Typ3ByteLength {
+		err = fmt.Errorf("expected field type %v for nested list %v, got %v",
+			Typ3ByteLength, info.Type, typ)
+		return
+	}
+
+	// Read the inner list in packed form.
+	efopts := fopts
+	efopts.BinFieldNum = 1
+	_n, err = cdc.decodeReflectBinary(bz, info, rv, efopts, false, false)
+	if slide(&bz, &n, _n) && err != nil {
+		return
+	}
+
+	// Earlier, we set bz to the byteslice read from buf.
+	// Ensure that all of bz was consumed.
+	if len(bz) > 0 {
+		err = errors.New("bytes left over after reading nested list contents")
+		return
+	}
+	return n, err
+}
+
 // CONTRACT: rv.CanAddr() is true.
 func (cdc *Codec) decodeReflectBinaryByteSlice(bz []byte, info *TypeInfo, rv reflect.Value,
 	fopts FieldOptions) (n int, err error) {
@@ -631,7 +730,7 @@ func (cdc *Codec) decodeReflectBinaryByteSlice(bz []byte, info *TypeInfo, rv ref
 // CONTRACT: rv.CanAddr() is true.
 // NOTE: Keep the code structure similar to decodeReflectBinaryArray.
 func (cdc *Codec) decodeReflectBinarySlice(bz []byte, info *TypeInfo, rv reflect.Value,
-	fopts FieldOptions, bare bool) (n int, err error) {
+	fopts FieldOptions, bare bool, merge bool) (n int, err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -654,6 +753,10 @@ func (cdc *Codec) decodeReflectBinarySlice(bz []byte, info *TypeInfo, rv reflect
 	// NOTE: This is due to Proto3.  How to best optimize?
 	esrt := reflect.SliceOf(ert)
 	var srv = reflect.Zero(esrt)
+	if merge {
+		// Append decoded items to the existing ones.
+		srv = rv.Convert(esrt)
+	}
 
 	if !bare {
 		// Read byte-length prefixed byteslice.
@@ -681,7 +784,7 @@ func (cdc *Codec) decodeReflectBinarySlice(bz []byte, info *TypeInfo, rv reflect
 				break
 			}
 			erv, _n := reflect.New(ert).Elem(), int(0)
-			_n, err = cdc.decodeReflectBinary(bz, einfo, erv, fopts, false)
+			_n, err = cdc.decodeReflectBinary(bz, einfo, erv, fopts, false, false)
 			if slide(&bz, &n, _n) && err != nil {
 				err = fmt.Errorf("error reading array contents: %v", err)
 				return
@@ -744,10 +847,15 @@ func (cdc *Codec) decodeReflectBinarySlice(bz []byte, info *TypeInfo, rv reflect
 				continue
 			}
 			// Normal case, read next non-nil element from bz.
-			// In case of any inner lists in unpacked form.
-			efopts := fopts
-			efopts.BinFieldNum = 1
-			_n, err = cdc.decodeReflectBinary(bz, einfo, erv, efopts, false)
+			if isPackedNestedList(einfo) {
+				// Read the inner list from field 1 of a wrapper message.
+				_n, err = cdc.decodeReflectBinaryNestedList(bz, einfo, erv, fopts)
+			} else {
+				// In case of any inner lists in unpacked form.
+				efopts := fopts
+				efopts.BinFieldNum = 1
+				_n, err = cdc.decodeReflectBinary(bz, einfo, erv, efopts, false, false)
+			}
 			if slide(&bz, &n, _n) && err != nil {
 				err = fmt.Errorf("error reading array contents: %v", err)
 				return
@@ -761,7 +869,7 @@ func (cdc *Codec) decodeReflectBinarySlice(bz []byte, info *TypeInfo, rv reflect
 
 // CONTRACT: rv.CanAddr() is true.
 func (cdc *Codec) decodeReflectBinaryStruct(bz []byte, info *TypeInfo, rv reflect.Value,
-	_ FieldOptions, bare bool) (n int, err error) {
+	_ FieldOptions, bare bool, merge bool) (n int, err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -804,6 +912,9 @@ func (cdc *Codec) decodeReflectBinaryStruct(bz []byte, info *TypeInfo, rv reflec
 		var lastFieldNum uint32
 		// Read each field.
 		for _, field := range info.Fields {
+			if field.BinSkip {
+				continue // e.g. amino:"-binary"
+			}
 			// Get field rv and info.
 			var frv = rv.Field(field.Index)
 			var finfo *TypeInfo
@@ -814,14 +925,16 @@ func (cdc *Codec) decodeReflectBinaryStruct(bz []byte, info *TypeInfo, rv reflec
 
 			// We're done if we've consumed all the bytes.
 			if len(bz) == 0 {
-				frv.Set(defaultValue(frv.Type()))
+				if !merge {
+					frv.Set(absentFieldValue(field))
+				}
 				continue
 			}
 
 			if field.UnpackedList {
 				// This is a list that was encoded unpacked, e.g.
 				// with repeated field entries for each list item.
-				_n, err = cdc.decodeReflectBinary(bz, finfo, frv, field.FieldOptions, true)
+				_n, err = cdc.decodeReflectBinary(bz, finfo, frv, field.FieldOptions, true, merge)
 				if slide(&bz, &n, _n) && err != nil {
 					return
 				}
@@ -833,8 +946,10 @@ func (cdc *Codec) decodeReflectBinaryStruct(bz []byte, info *TypeInfo, rv reflec
 				)
 				fnum, typ, _n, err = decodeFieldNumberAndTyp3(bz)
 				if field.BinFieldNum < fnum {
-					// Set zero field value.
-					frv.Set(defaultValue(frv.Type()))
+					// Set zero field value, unless merging.
+					if !merge {
+						frv.Set(absentFieldValue(field))
+					}
 					continue
 					// Do not slide, we will read it again.
 				}
@@ -864,7 +979,7 @@ func (cdc *Codec) decodeReflectBinaryStruct(bz []byte, info *TypeInfo, rv reflec
 					return
 				}
 				// Decode field into frv.
-				_n, err = cdc.decodeReflectBinary(bz, finfo, frv, field.FieldOptions, false)
+				_n, err = cdc.decodeReflectBinary(bz, finfo, frv, field.FieldOptions, false, merge)
 				if slide(&bz, &n, _n) && err != nil {
 					return
 				}
@@ -897,6 +1012,80 @@ func (cdc *Codec) decodeReflectBinaryStruct(bz []byte, info *TypeInfo, rv reflec
 	return n, err
 }
 
+// CONTRACT: rv.CanAddr() is true.
+// See encodeReflectBinaryComplex for the encoding.
+func (cdc *Codec) decodeReflectBinaryComplex(bz []byte, info *TypeInfo, rv reflect.Value,
+	_ FieldOptions, bare bool) (n int, err error) {
+	if !rv.CanAddr() {
+		panic("rv not addressable")
+	}
+	if printLog {
+		fmt.Println("(d) decodeReflectBinaryComplex")
+		defer func() {
+			fmt.Printf("(d) -> err: %v\n", err)
+		}()
+	}
+	_n := 0 // nolint: ineffassign
+
+	if !bare {
+		// Read byte-length prefixed byteslice.
+		var buf []byte
+		buf, _n, err = DecodeByteSlice(bz)
+		if slide(&bz, nil, _n) && err != nil {
+			return
+		}
+		// This is a trick for debuggability -- we slide on &n more later.
+		n += UvarintSize(uint64(len(buf)))
+		bz = buf
+	}
+
+	var typWanted = Typ38Byte
+	if info.Type.Kind() == reflect.Complex64 {
+		typWanted = Typ3_4Byte
+	}
+
+	// Read the real (1) and imaginary (2) parts.
+	var parts [2]float64
+	for i := range parts {
+		var (
+			fnum uint32
+			typ  Typ3
+		)
+		fnum, typ, _n, err = decodeFieldNumberAndTyp3(bz)
+		if slide(&bz, &n, _n) && err != nil {
+			return
+		}
+		if fnum != uint32(i+1) {
+			err = fmt.Errorf("expected field # %v of %v, got %v", i+1, info.Type, fnum)
+			return
+		}
+		if typ != typWanted {
+			err = fmt.Errorf("expected field type %v for # %v of %v, got %v",
+				typWanted, fnum, info.Type, typ)
+			return
+		}
+		if typWanted == Typ3_4Byte {
+			var f float32
+			f, _n, err = DecodeFloat32(bz)
+			parts[i] = float64(f)
+		} else {
+			parts[i], _n, err = DecodeFloat64(bz)
+		}
+		if slide(&bz, &n, _n) && err != nil {
+			return
+		}
+	}
+
+	// Earlier, we set bz to the byteslice read from buf.
+	// Ensure that all of bz was consumed.
+	if !bare && len(bz) > 0 {
+		err = errors.New("bytes left over after reading complex contents")
+		return
+	}
+	rv.SetComplex(complex(parts[0], parts[1]))
+	return n, err
+}
+
 //----------------------------------------
 // consume* for skipping struct fields
 
@@ -67,48 +67,14 @@ func (cdc *Codec) encodeReflectBinary(w io.Writer, info *TypeInfo, rv reflect.Va
 	case reflect.Array:
 		if info.Type.Elem().Kind() == reflect.Uint8 {
 			err = cdc.encodeReflectBinaryByteArray(w, info, rv, fopts)
-		} else if kind := info.Type.Elem().Kind(); kind == reflect.Slice || kind == reflect.Array {
-			// for proto3 compatibility, we do not allow multidimensional arrays,
-			// unless the elements involved are bytes (e.g. [][]byte)
-			if info.Type.Elem().Elem().Kind() != reflect.Uint8 { // byte is an alias for uint8
-				elem := info.Type.Elem()
-				for {
-					if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
-						elem = elem.Elem()
-						continue
-					}
-					if elem.Kind() == reflect.Uint8 { // byte is an alias for uint8
-						break
-					}
-					err = errors.New("multidimensional arrays not allowed")
-					break
-				}
-			}
 		} else {
 			err = cdc.encodeReflectBinaryList(w, info, rv, fopts, bare)
 		}
 
 	case reflect.Slice:
-		switch info.Type.Elem().Kind() {
-
-		case reflect.Uint8:
+		if info.Type.Elem().Kind() == reflect.Uint8 {
 			err = cdc.encodeReflectBinaryByteSlice(w, info, rv, fopts)
-		case reflect.Slice, reflect.Array:
-			// for proto3 compatibility, we do not allow multidimensional slices,
-			// unless the elements involved are bytes (e.g. [][]byte)
-			elem := info.Type.Elem()
-			for {
-				if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
-					elem = elem.Elem()
-					continue
-				}
-				if elem.Kind() == reflect.Uint8 { // byte is an alias for uint8
-					break
-				}
-				err = errors.New("multidimensional slices not allowed")
-				break
-			}
-		default:
+		} else {
 			err = cdc.encodeReflectBinaryList(w, info, rv, fopts, bare)
 		}
 
@@ -187,6 +153,13 @@ func (cdc *Codec) encodeReflectBinary(w io.Writer, info *TypeInfo, rv reflect.Va
 		}
 		err = EncodeFloat32(w, float32(rv.Float()))
 
+	case reflect.Complex128, reflect.Complex64:
+		if !fopts.Unsafe {
+			err = errors.New("amino complex* support requires `amino:\"unsafe\"`")
+			return
+		}
+		err = cdc.encodeReflectBinaryComplex(w, info, rv, fopts, bare)
+
 	case reflect.String:
 		err = EncodeString(w, rv.String())
 
@@ -239,7 +212,10 @@ func (cdc *Codec) encodeReflectBinaryInterface(w io.Writer, iinfo *TypeInfo, rv
 	}
 
 	// For Proto3 compatibility, encode interfaces as ByteLength.
-	buf := bytes.NewBuffer(nil)
+	buf, err := beginMessage(w, bare)
+	if err != nil {
+		return
+	}
 
 	// Write disambiguation bytes if needed.
 	needDisamb := false
@@ -267,14 +243,7 @@ func (cdc *Codec) encodeReflectBinaryInterface(w io.Writer, iinfo *TypeInfo, rv
 		return
 	}
 
-	if bare {
-		// Write byteslice without byte-length prefixing.
-		_, err = w.Write(buf.Bytes())
-	} else {
-		// Write byte-length prefixed byteslice.
-		err = EncodeByteSlice(w, buf.Bytes())
-	}
-	return err
+	return endMessage(w, buf, bare)
 }
 
 func (cdc *Codec) encodeReflectBinaryByteArray(w io.Writer, info *TypeInfo, rv reflect.Value,
@@ -318,7 +287,10 @@ func (cdc *Codec) encodeReflectBinaryList(w io.Writer, info *TypeInfo, rv reflec
 
 	// Proto3 byte-length prefixing incurs alloc cost on the encoder.
 	// Here we incur it for unpacked form for ease of dev.
-	buf := bytes.NewBuffer(nil)
+	buf, err := beginMessage(w, bare)
+	if err != nil {
+		return
+	}
 
 	// If elem is not already a ByteLength type, write in packed form.
 	// This is a Proto wart due to Proto backwards compatibility issues.
@@ -365,6 +337,12 @@ func (cdc *Codec) encodeReflectBinaryList(w io.Writer, info *TypeInfo, rv reflec
 				if err != nil {
 					return
 				}
+			} else if isPackedNestedList(einfo) {
+				// Write the inner list as field 1 of a wrapper message.
+				err = cdc.encodeReflectBinaryNestedList(buf, einfo, erv, fopts)
+				if err != nil {
+					return
+				}
 			} else {
 				// Write the element value as a ByteLength.
 				// In case of any inner lists in unpacked form.
@@ -378,14 +356,40 @@ func (cdc *Codec) encodeReflectBinaryList(w io.Writer, info *TypeInfo, rv reflec
 		}
 	}
 
-	if bare {
-		// Write byteslice without byte-length prefixing.
-		_, err = w.Write(buf.Bytes())
-	} else {
-		// Write byte-length prefixed byteslice.
-		err = EncodeByteSlice(w, buf.Bytes())
+	return endMessage(w, buf, bare)
+}
+
+// Nested lists are encoded as repeated wrapper messages that hold the inner
+// list as field 1, like the following for [][]int64:
+//
+// message Inner { repeated int64 values = 1; }
+// message Outer { repeated Inner values = 1; }
+//
+// Inner lists of ByteLength elements already encode as such a wrapper (the
+// repeated field 1 entries), so this is only needed for packed inner lists.
+// CONTRACT: isPackedNestedList(info)
+func (cdc *Codec) encodeReflectBinaryNestedList(w io.Writer, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(e) encodeReflectBinaryNestedList")
+		defer func() {
+			fmt.Printf("(e) -> err: %v\n", err)
+		}()
 	}
-	return err
+
+	buf, err := beginMessage(w, false)
+	if err != nil {
+		return
+	}
+	efopts := fopts
+	efopts.BinFieldNum = 1
+	err = cdc.writeFieldIfNotEmpty(buf, 1, info, fopts, efopts, rv, false, false)
+	if err != nil {
+		return
+	}
+
+	// Write byte-length prefixed wrapper message.
+	return endMessage(w, buf, false)
 }
 
 // CONTRACT: info.Type.Elem().Kind() == reflect.Uint8
@@ -419,7 +423,10 @@ func (cdc *Codec) encodeReflectBinaryStruct(w io.Writer, info *TypeInfo, rv refl
 
 	// Proto3 incurs a cost in writing non-root structs.
 	// Here we incur it for root structs as well for ease of dev.
-	buf := bytes.NewBuffer(nil)
+	buf, err := beginMessage(w, bare)
+	if err != nil {
+		return
+	}
 
 	switch info.Type {
 
@@ -432,6 +439,9 @@ func (cdc *Codec) encodeReflectBinaryStruct(w io.Writer, info *TypeInfo, rv refl
 
 	default:
 		for _, field := range info.Fields {
+			if field.BinSkip {
+				continue // e.g. amino:"-binary"
+			}
 			// Get type info for field.
 			var finfo *TypeInfo
 			finfo, err = cdc.getTypeInfoWlock(field.Type)
@@ -442,6 +452,17 @@ func (cdc *Codec) encodeReflectBinaryStruct(w io.Writer, info *TypeInfo, rv refl
 			var frv = rv.Field(field.Index)
 			var frvIsPtr = frv.Kind() == reflect.Ptr
 			var dfrv, isDefault = isDefaultValue(frv)
+			if field.Optional {
+				// Optional fields are written iff non-nil, even if zero.
+				if _, _, isNilPtr := derefPointers(frv); isNilPtr {
+					continue
+				}
+				err = cdc.writeFieldIfNotEmpty(buf, field.BinFieldNum, finfo, fopts, field.FieldOptions, dfrv, true, false)
+				if err != nil {
+					return
+				}
+				continue
+			}
 			if isDefault && !field.WriteEmpty {
 				// Do not encode default value fields
 				// (except when `amino:"write_empty"` is set).
@@ -464,14 +485,61 @@ func (cdc *Codec) encodeReflectBinaryStruct(w io.Writer, info *TypeInfo, rv refl
 		}
 	}
 
-	if bare {
-		// Write byteslice without byte-length prefixing.
-		_, err = w.Write(buf.Bytes())
-	} else {
-		// Write byte-length prefixed byteslice.
-		err = EncodeByteSlice(w, buf.Bytes())
+	return endMessage(w, buf, bare)
+}
+
+// Complex numbers are encoded like the following message, where fixed64 is
+// replaced by fixed32 for complex64.  Like floats, both parts are always
+// written, even when zero.
+//
+// message Complex { fixed64 real = 1; fixed64 imag = 2; }
+func (cdc *Codec) encodeReflectBinaryComplex(w io.Writer, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions, bare bool) (err error) {
+	if printLog {
+		fmt.Println("(e) encodeReflectBinaryComplex")
+		defer func() {
+			fmt.Printf("(e) -> err: %v\n", err)
+		}()
 	}
-	return err
+
+	buf, err := beginMessage(w, bare)
+	if err != nil {
+		return
+	}
+	c := rv.Complex()
+
+	switch info.Type.Kind() {
+	case reflect.Complex64:
+		if err = encodeFieldNumberAndTyp3(buf, 1, Typ3_4Byte); err != nil {
+			return
+		}
+		if err = EncodeFloat32(buf, float32(real(c))); err != nil {
+			return
+		}
+		if err = encodeFieldNumberAndTyp3(buf, 2, Typ3_4Byte); err != nil {
+			return
+		}
+		if err = EncodeFloat32(buf, float32(imag(c))); err != nil {
+			return
+		}
+	case reflect.Complex128:
+		if err = encodeFieldNumberAndTyp3(buf, 1, Typ38Byte); err != nil {
+			return
+		}
+		if err = EncodeFloat64(buf, real(c)); err != nil {
+			return
+		}
+		if err = encodeFieldNumberAndTyp3(buf, 2, Typ38Byte); err != nil {
+			return
+		}
+		if err = EncodeFloat64(buf, imag(c)); err != nil {
+			return
+		}
+	default:
+		panic("should not happen")
+	}
+
+	return endMessage(w, buf, bare)
 }
 
 //----------------------------------------
@@ -497,7 +565,7 @@ func encodeFieldNumberAndTyp3(w io.Writer, num uint32, typ Typ3) (err error) {
 }
 
 func (cdc *Codec) writeFieldIfNotEmpty(
-	buf *bytes.Buffer,
+	w messageWriter,
 	fieldNum uint32,
 	finfo *TypeInfo,
 	structsFopts FieldOptions, // the wrapping struct's FieldOptions if any
@@ -506,6 +574,46 @@ func (cdc *Codec) writeFieldIfNotEmpty(
 	isWriteEmpty bool,
 	bare bool,
 ) error {
+	return w.writeFieldIfNotEmpty(cdc, fieldNum, finfo, fieldOpts, derefedVal, isWriteEmpty, bare)
+}
+
+// messageWriter is what messages are encoded into: a messageBuffer, or a
+// binaryStream while hashing (see Codec.Hash).
+type messageWriter interface {
+	io.Writer
+
+	// Writes the field key and value, unless the value is empty and
+	// !isWriteEmpty.
+	writeFieldIfNotEmpty(cdc *Codec, fieldNum uint32, finfo *TypeInfo,
+		fieldOpts FieldOptions, derefedVal reflect.Value, isWriteEmpty bool, bare bool) error
+	// Returns the writer to encode a nested message into.
+	beginMessage(bare bool) (messageWriter, error)
+	// Writes the message, begun by beginMessage, to w, byte-length prefixed
+	// unless bare.
+	endMessage(w io.Writer, bare bool) error
+}
+
+// Returns the writer to encode a message into, which endMessage then writes
+// to w, byte-length prefixed unless bare.
+func beginMessage(w io.Writer, bare bool) (messageWriter, error) {
+	if mw, ok := w.(messageWriter); ok {
+		return mw.beginMessage(bare)
+	}
+	return new(messageBuffer), nil
+}
+
+func endMessage(w io.Writer, mw messageWriter, bare bool) error {
+	return mw.endMessage(w, bare)
+}
+
+// messageBuffer buffers each message, and rolls back empty fields after
+// writing them.
+type messageBuffer struct {
+	bytes.Buffer
+}
+
+func (buf *messageBuffer) writeFieldIfNotEmpty(cdc *Codec, fieldNum uint32, finfo *TypeInfo,
+	fieldOpts FieldOptions, derefedVal reflect.Value, isWriteEmpty bool, bare bool) error {
 	lBeforeKey := buf.Len()
 	// Write field key (number and type).
 	err := encodeFieldNumberAndTyp3(buf, fieldNum, typeToTyp3(finfo.Type, fieldOpts))
@@ -528,3 +636,18 @@ func (cdc *Codec) writeFieldIfNotEmpty(
 	}
 	return nil
 }
+
+func (buf *messageBuffer) beginMessage(bare bool) (messageWriter, error) {
+	return new(messageBuffer), nil
+}
+
+func (buf *messageBuffer) endMessage(w io.Writer, bare bool) (err error) {
+	if bare {
+		// Write byteslice without byte-length prefixing.
+		_, err = w.Write(buf.Bytes())
+	} else {
+		// Write byte-length prefixed byteslice.
+		err = EncodeByteSlice(w, buf.Bytes())
+	}
+	return err
+}
@@ -314,3 +314,239 @@ func TestUnmarshalFuncBinary(t *testing.T) {
 		assert.Fail(t, "should have paniced but got bz: %X err: %v", bz, err)
 	})
 }
+
+func TestComplexBinary(t *testing.T) {
+	type ComplexStruct struct {
+		C64  complex64    `amino:"unsafe"`
+		C128 complex128   `amino:"unsafe"`
+		Cs   []complex128 `amino:"unsafe"`
+	}
+
+	cdc := amino.NewCodec()
+
+	cs := ComplexStruct{
+		C64:  complex(1.5, -2),
+		C128: complex(-0.25, 3),
+		Cs:   []complex128{complex(1, 0), 0},
+	}
+	bz, err := cdc.MarshalBinaryBare(cs)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"0A0A0D0000C03F15000000C0"+
+			"121209000000000000D0BF110000000000000840"+
+			"1A1209000000000000F03F110000000000000000"+
+			"1A12090000000000000000110000000000000000",
+		fmt.Sprintf("%X", bz))
+
+	var cs2 ComplexStruct
+	err = cdc.UnmarshalBinaryBare(bz, &cs2)
+	require.NoError(t, err)
+	assert.Equal(t, cs, cs2)
+
+	// Like floats, zero values are always written.
+	bz, err = cdc.MarshalBinaryBare(ComplexStruct{})
+	require.NoError(t, err)
+	assert.Equal(t,
+		"0A0A0D0000000015000000001212090000000000000000110000000000000000",
+		fmt.Sprintf("%X", bz))
+
+	// Without the unsafe tag complex numbers are rejected.
+	_, err = cdc.MarshalBinaryBare(complex128(1))
+	assert.Error(t, err)
+	type UnsafeComplex struct {
+		C complex128
+	}
+	assert.Panics(t, func() {
+		amino.NewCodec().MarshalBinaryBare(UnsafeComplex{C: 1})
+	})
+}
+
+func TestNestedLists(t *testing.T) {
+	type Foo struct {
+		A int
+	}
+	type Nested struct {
+		Ints    [][]int64
+		Structs [][]Foo
+		Arrays  [2][2]int8
+		Deep    [][][]uint32
+	}
+
+	cdc := amino.NewCodec()
+
+	n := Nested{
+		Ints:    [][]int64{{1, 2}, nil, {3}},
+		Structs: [][]Foo{{{A: 1}}, nil},
+		Arrays:  [2][2]int8{{1, -1}, {0, 2}},
+		Deep:    [][][]uint32{{{7}}},
+	}
+	bz, err := cdc.MarshalBinaryBare(n)
+	require.NoError(t, err)
+	// Each inner list is a wrapper message with the inner list as field 1.
+	assert.Equal(t,
+		"0A040A020102"+"0A00"+"0A030A0103"+
+			"12040A020801"+"1200"+
+			"1A040A0202011A040A020004"+
+			"22050A030A0107",
+		fmt.Sprintf("%X", bz))
+
+	var n2 Nested
+	err = cdc.UnmarshalBinaryBare(bz, &n2)
+	require.NoError(t, err)
+	assert.Equal(t, n, n2)
+
+	// Top-level nested lists are supported as well.
+	ints := [][]int64{{1, 2}, {3}}
+	bz, err = cdc.MarshalBinaryLengthPrefixed(ints)
+	require.NoError(t, err)
+	var ints2 [][]int64
+	err = cdc.UnmarshalBinaryLengthPrefixed(bz, &ints2)
+	require.NoError(t, err)
+	assert.Equal(t, ints, ints2)
+
+	strs := []string{"a", "b"}
+	bz, err = cdc.MarshalBinaryBare(strs)
+	require.NoError(t, err)
+	var strs2 []string
+	err = cdc.UnmarshalBinaryBare(bz, &strs2)
+	require.NoError(t, err)
+	assert.Equal(t, strs, strs2)
+}
+
+func TestOptionalFields(t *testing.T) {
+	type Inner struct {
+		A int
+	}
+	type Optionals struct {
+		Int    *int64  `amino:"optional"`
+		String *string `amino:"optional"`
+		Bool   *bool   `amino:"optional"`
+		Inner  *Inner  `amino:"optional"`
+		Plain  *int64
+	}
+
+	cdc := amino.NewCodec()
+
+	// Absent fields decode to nil.
+	bz, err := cdc.MarshalBinaryBare(Optionals{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte(nil), bz)
+	var o Optionals
+	err = cdc.UnmarshalBinaryBare(bz, &o)
+	require.NoError(t, err)
+	assert.Nil(t, o.Int)
+	assert.Nil(t, o.String)
+	assert.Nil(t, o.Bool)
+	assert.Nil(t, o.Inner)
+
+	// Set fields are written even if zero, unlike other pointers.
+	var zeroInt, zeroPlain int64
+	var zeroString string
+	var zeroBool bool
+	o = Optionals{
+		Int:    &zeroInt,
+		String: &zeroString,
+		Bool:   &zeroBool,
+		Inner:  &Inner{},
+		Plain:  &zeroPlain,
+	}
+	bz, err = cdc.MarshalBinaryBare(o)
+	require.NoError(t, err)
+	assert.Equal(t, "0800120018002200", fmt.Sprintf("%X", bz))
+	var o2 Optionals
+	err = cdc.UnmarshalBinaryBare(bz, &o2)
+	require.NoError(t, err)
+	require.NotNil(t, o2.Int)
+	assert.Equal(t, int64(0), *o2.Int)
+	require.NotNil(t, o2.String)
+	assert.Equal(t, "", *o2.String)
+	require.NotNil(t, o2.Bool)
+	assert.False(t, *o2.Bool)
+	assert.Equal(t, &Inner{}, o2.Inner)
+	assert.Nil(t, o2.Plain, "non-optional zero pointers are not written")
+
+	// Only pointer fields can be optional.
+	type BadOptional struct {
+		Int int64 `amino:"optional"`
+	}
+	assert.Panics(t, func() {
+		amino.NewCodec().MarshalBinaryBare(BadOptional{})
+	})
+}
+
+type mergeShape interface{}
+
+type mergeSquare struct {
+	Side  int
+	Label string
+}
+
+type mergeCircle struct {
+	Radius int
+}
+
+func TestUnmarshalBinaryMerge(t *testing.T) {
+	type Inner struct {
+		A int
+		B string
+	}
+	type State struct {
+		Num    int64
+		Name   string
+		Inner  Inner
+		PInner *Inner
+		List   []int64
+		Inners []Inner
+		Shape  mergeShape
+	}
+
+	cdc := amino.NewCodec()
+	cdc.RegisterInterface((*mergeShape)(nil), nil)
+	cdc.RegisterConcrete(&mergeSquare{}, "merge/square", nil)
+	cdc.RegisterConcrete(&mergeCircle{}, "merge/circle", nil)
+
+	state := State{
+		Num:    1,
+		Name:   "one",
+		Inner:  Inner{A: 1, B: "b"},
+		PInner: &Inner{A: 2, B: "c"},
+		List:   []int64{1, 2},
+		Inners: []Inner{{A: 1}},
+		Shape:  &mergeSquare{Side: 3, Label: "sq"},
+	}
+
+	update := State{
+		Name:   "two",
+		Inner:  Inner{A: 5},
+		PInner: &Inner{B: "d"},
+		List:   []int64{3},
+		Inners: []Inner{{A: 2}},
+		Shape:  &mergeSquare{Side: 4},
+	}
+	bz, err := cdc.MarshalBinaryBare(update)
+	require.NoError(t, err)
+
+	err = cdc.UnmarshalBinaryMerge(bz, &state)
+	require.NoError(t, err)
+	assert.Equal(t, State{
+		Num:    1,
+		Name:   "two",
+		Inner:  Inner{A: 5, B: "b"},
+		PInner: &Inner{A: 2, B: "d"},
+		List:   []int64{1, 2, 3},
+		Inners: []Inner{{A: 1}, {A: 2}},
+		Shape:  &mergeSquare{Side: 4, Label: "sq"},
+	}, state)
+
+	// Interfaces holding a different concrete type are replaced.
+	bz, err = cdc.MarshalBinaryBare(State{Shape: &mergeCircle{Radius: 1}})
+	require.NoError(t, err)
+	err = cdc.UnmarshalBinaryMerge(bz, &state)
+	require.NoError(t, err)
+	assert.Equal(t, &mergeCircle{Radius: 1}, state.Shape)
+	assert.Equal(t, "two", state.Name)
+
+	// Without merging, decoding into a non-nil interface is not supported.
+	err = cdc.UnmarshalBinaryBare(bz, &state)
+	require.Error(t, err)
+}
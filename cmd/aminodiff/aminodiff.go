@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tendermint/go-amino/cmd/internal/cmdutil"
+	"github.com/tendermint/go-amino/scan"
+)
+
+func main() {
+	// Print help.
+	if len(os.Args) == 1 {
+		fmt.Println(`Usage: aminodiff <HEXBYTES A> <HEXBYTES B> or --help`)
+		return
+	}
+
+	// Parse flags...
+	var registry string
+	var typ string
+	var input string
+	var files bool
+	var isJSON bool
+	var format string
+	flgs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flgs.StringVar(&registry, "registry", "", "Decode using the registry dumped by Codec.ExportRegistry.")
+	flgs.StringVar(&typ, "type", "", "The Go type, e.g. github.com/org/pkg.Foo, or registered name of the bytes (with --registry).")
+	flgs.StringVar(&input, "input", "hex", "Input encoding: hex, base64 or raw.")
+	flgs.BoolVar(&files, "files", false, "The arguments are files to read (\"-\" for stdin).")
+	flgs.BoolVar(&isJSON, "json", false, "The inputs are amino JSON documents.")
+	flgs.StringVar(&format, "format", "text", "Output format: text or json.")
+	err := flgs.Parse(os.Args[1:])
+	if err == flag.ErrHelp {
+		fmt.Println(`Usage: aminodiff <HEXBYTES A> <HEXBYTES B> or --help
+
+		aminodiff aligns two amino encodings by field number and nesting, and
+		prints the added (+), removed (-) and changed (~) fields with their
+		byte offsets in A and B.
+
+		> aminodiff 0A0268691003 0A0268691004
+		~ /@2 @5/@5: 03 i64:-2 u64:3 -> 04 i64:2 u64:4
+
+		With a registry dumped by Codec.ExportRegistry, fields are named.  If
+		no type is given, it is detected from the prefix bytes.
+
+		> aminodiff --registry=registry.json [--type=<TYPE>] <HEXBYTES A> <HEXBYTES B>
+
+		The inputs can also be files, as hex, base64 or raw bytes, or amino
+		JSON documents, whose interface wrappers are compared by type.
+
+		> aminodiff --files --input=raw a.bin b.bin
+		> aminodiff --files --json a.json b.json
+
+		The exit code is 1 if the inputs differ.`)
+		return
+	} else if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if flgs.NArg() != 2 {
+		fmt.Println(`Usage: aminodiff <HEXBYTES A> <HEXBYTES B> or --help`)
+		os.Exit(2)
+	}
+
+	// Read both inputs and diff them.
+	var changes []scan.Change
+	if isJSON {
+		a, b, err := readInputs(flgs.Args(), files, "raw")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		changes, err = scan.DiffJSON(a, b)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+	} else {
+		a, b, err := readInputs(flgs.Args(), files, input)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+		var sc *scan.Schema
+		if registry != "" {
+			sc, err = cmdutil.ReadSchema(registry)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(2)
+			}
+		}
+		nodeA, err := scanInput(a, sc, typ)
+		if err != nil {
+			fmt.Printf("A: %v\n", err)
+		}
+		nodeB, err := scanInput(b, sc, typ)
+		if err != nil {
+			fmt.Printf("B: %v\n", err)
+		}
+		if nodeA == nil || nodeB == nil {
+			os.Exit(2)
+		}
+		changes = scan.Diff(nodeA, nodeB)
+	}
+
+	// Print the changes.
+	switch format {
+	case "text":
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+	case "json":
+		jsonBz, _ := json.MarshalIndent(changes, "", "  ")
+		fmt.Println(string(jsonBz))
+	default:
+		fmt.Printf("Unknown format %v\n", format)
+		os.Exit(2)
+	}
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+// Scans bz, as the given type if we have a schema.
+func scanInput(bz []byte, sc *scan.Schema, typ string) (*scan.Node, error) {
+	if sc != nil {
+		return sc.Scan(bz, typ)
+	}
+	return scan.Scan(bz) // Assume that it's a struct.
+}
+
+//----------------------------------------
+// Misc.
+
+// Reads both inputs from args, or from the files named by args, in the
+// given encoding.
+func readInputs(args []string, files bool, input string) (a, b []byte, err error) {
+	var bzs [2][]byte
+	for i, arg := range args {
+		if files {
+			bzs[i], err = cmdutil.ReadFile(arg)
+		} else {
+			bzs[i] = []byte(arg)
+		}
+		if err != nil {
+			return
+		}
+		bzs[i], err = cmdutil.DecodeInput(bzs[i], input)
+		if err != nil {
+			return
+		}
+	}
+	return bzs[0], bzs[1], nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/tsgen"
+	"github.com/tendermint/go-amino/vectors"
+)
+
+func main() {
+	// Print help.
+	if len(os.Args) < 2 || os.Args[1] != "ts" {
+		fmt.Println(`Usage: aminogen ts --registry=registry.json or --help`)
+		os.Exit(2)
+	}
+
+	// Parse flags...
+	var registry string
+	var vectorsDir string
+	var out string
+	var name string
+	flgs := flag.NewFlagSet(os.Args[0]+" ts", flag.ExitOnError)
+	flgs.StringVar(&registry, "registry", "", "The registry dumped by Codec.ExportRegistry.")
+	flgs.StringVar(&vectorsDir, "vectors", "", "Also generate a test of the golden vectors in a directory.")
+	flgs.StringVar(&out, "out", ".", "The directory to write to.")
+	flgs.StringVar(&name, "name", "amino", "The name of the generated module.")
+	err := flgs.Parse(os.Args[2:])
+	if err == flag.ErrHelp {
+		fmt.Println(`Usage: aminogen ts --registry=registry.json or --help
+
+		aminogen ts generates TypeScript types for the amino JSON encoding of
+		the types in a registry dumped by Codec.ExportRegistry, with helpers
+		to wrap and unwrap registered concrete values.
+
+		> aminogen ts --registry=registry.json --out=src/
+		  (writes src/amino.ts)
+
+		With a directory of golden vectors, as written by aminovectors or
+		vectors.Generate, it also generates a test that the vectors
+		typecheck and round-trip.
+
+		> aminogen ts --registry=registry.json --vectors=vectors/ --out=src/
+		  (writes src/amino.ts and src/amino.test.ts)`)
+		return
+	} else if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if registry == "" {
+		fmt.Println("--registry is required")
+		os.Exit(2)
+	}
+
+	// Read the registry and generate.
+	rd, err := readRegistry(registry)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	g, err := tsgen.NewGenerator(rd)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	var buf bytes.Buffer
+	if err = g.Generate(&buf); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err = ioutil.WriteFile(filepath.Join(out, name+".ts"), buf.Bytes(), 0644); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Generate the test of the vectors.
+	if vectorsDir == "" {
+		return
+	}
+	vs, err := vectors.Read(vectorsDir)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	buf.Reset()
+	if err = g.GenerateTests(&buf, "./"+name, vs); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err = ioutil.WriteFile(filepath.Join(out, name+".test.ts"), buf.Bytes(), 0644); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func readRegistry(path string) (rd amino.RegistryDescription, err error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bz, &rd)
+	return
+}
@@ -1,14 +1,15 @@
 package main
 
 import (
-	"encoding/binary"
-	"encoding/hex"
-	"errors"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/cmd/internal/cmdutil"
+	"github.com/tendermint/go-amino/scan"
 )
 
 func main() {
@@ -21,9 +22,23 @@ func main() {
 	// Parse flags...
 	var colorize bool
 	var concreteName string
+	var registry string
+	var typ string
+	var format string
+	var file string
+	var input string
+	var lengthPrefixed bool
+	var prefixLookup string
 	flgs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	flgs.BoolVar(&colorize, "color", false, "Just print the colored bytes and exit.")
 	flgs.StringVar(&concreteName, "concrete-name", "", "Just print the concrete bytes for a concrete name and exit.")
+	flgs.StringVar(&registry, "registry", "", "Decode using the registry dumped by Codec.ExportRegistry.")
+	flgs.StringVar(&typ, "type", "", "The Go type, e.g. github.com/org/pkg.Foo, or registered name of the bytes (with --registry).")
+	flgs.StringVar(&format, "format", "color", "Output format: color, tree or json.")
+	flgs.StringVar(&file, "file", "", "Read the bytes from a file instead of the argument (\"-\" for stdin).")
+	flgs.StringVar(&input, "input", "hex", "Input encoding: hex, base64 or raw.")
+	flgs.BoolVar(&lengthPrefixed, "length-prefixed", false, "Scan a stream of length-prefixed frames.")
+	flgs.StringVar(&prefixLookup, "prefix-lookup", "", "Look up the prefix bytes among the concrete names in a file.")
 	err := flgs.Parse(os.Args[1:])
 	if err == flag.ErrHelp {
 		fmt.Println(`Usage: aminoscan <STRUCT HEXBYTES> or --help
@@ -32,20 +47,47 @@ func main() {
 		try to display bytes in ascii in a different color if it happens to be
 		a printable character.
 
-		> aminoscan --color <HEXBYTES>`)
+		> aminoscan --color <HEXBYTES>
+
+		With a registry dumped by Codec.ExportRegistry, aminoscan decodes the
+		bytes as the given type, printing field names and decoded values.  If
+		no type is given, it is detected from the prefix bytes.
+
+		> aminoscan --registry=registry.json [--type=<TYPE>] <HEXBYTES>
+
+		The output is a colored tree by default, or a plain tree or JSON.
+
+		> aminoscan --format=json <HEXBYTES>
+
+		The bytes can also be read from a file or stdin, as hex, base64 or raw
+		bytes.  With --length-prefixed, they are a stream of frames as written
+		by MarshalBinaryLengthPrefixed, and each frame is scanned in turn.
+
+		> cat frames.bin | aminoscan --file=- --input=raw --length-prefixed
+
+		With --prefix-lookup, the leading prefix bytes are looked up among
+		the concrete names of a file (one per line).
+
+		> aminoscan --prefix-lookup=names.txt <HEXBYTES>
+
+		The exit code is 2 if the bytes can't be read or decoded.`)
 		return
 	} else if err != nil {
 		fmt.Println(err)
-		return
+		os.Exit(2)
 	}
 
 	// If we just want to show colored bytes...
 	if colorize {
-		if flgs.Arg(0) == "" {
+		if flgs.Arg(0) == "" && file == "" {
 			fmt.Println(`Usage: aminoscan --color <HEXBYTES>`)
-			return
+			os.Exit(2)
+		}
+		bz, err := cmdutil.ReadInput(flgs.Arg(0), file, input)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
 		}
-		bz := hexDecode(flgs.Arg(0))
 		fmt.Println(ColoredBytes(bz, Green, Blue))
 		return
 	}
@@ -57,260 +99,145 @@ func main() {
 		return
 	}
 
-	// Parse struct Amino bytes.
-	bz := hexDecode(os.Args[1]) // Read input hex bytes.
-	fmt.Println(Yellow("## Root Struct (assumed)"))
-	s, n, err := scanStruct(bz, "", true) // Assume that it's  struct.
-	s += Red(fmt.Sprintf("%X", bz[n:]))   // Bytes remaining are red.
-	fmt.Println(Yellow("## Root Struct END"))
-	fmt.Println(s, n, err) // Print color-encoded bytes s.
-}
-
-func scanAny(typ amino.Typ3, bz []byte, indent string) (s string, n int, err error) {
-	switch typ {
-	case amino.Typ3Varint:
-		s, n, err = scanVarint(bz, indent)
-	case amino.Typ38Byte:
-		s, n, err = scan8Byte(bz, indent)
-	case amino.Typ3ByteLength:
-		s, n, err = scanByteLength(bz, indent)
-	case amino.Typ3_4Byte:
-		s, n, err = scan4Byte(bz, indent)
-	default:
-		panic("should not happen")
-	}
-	return
-}
-
-func scanVarint(bz []byte, indent string) (s string, n int, err error) {
-	if len(bz) == 0 {
-		err = fmt.Errorf("EOF while reading (U)Varint")
-	}
-	// First try Varint.
-	okI64 := true
-	i64, n := binary.Varint(bz)
-	if n <= 0 {
-		n = 0
-		okI64 = false
-	}
-	// Then try Uvarint.
-	okU64 := true
-	u64, _n := binary.Uvarint(bz)
-	if n != _n {
-		n = 0
-		okU64 = false
-	}
-	// If neither work, return error.
-	if !okI64 && !okU64 {
-		err = fmt.Errorf("invalid (u)varint")
-		return
-	}
-	// s is the same either way.
-	s = Cyan(fmt.Sprintf("%X", bz[:n]))
-	fmt.Printf("%s%s (", indent, s)
-	if okI64 {
-		fmt.Printf("i64:%v ", i64)
-	}
-	if okU64 {
-		fmt.Printf("u64:%v", u64)
+	// Read the input bytes.
+	bz, err := cmdutil.ReadInput(flgs.Arg(0), file, input)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	var sc *scan.Schema
+	if registry != "" {
+		sc, err = cmdutil.ReadSchema(registry)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
 	}
-	fmt.Print(")\n")
-	return s, n, err
-}
-
-func scan8Byte(bz []byte, indent string) (s string, n int, err error) {
-	if len(bz) < 8 {
-		err = errors.New("while reading 8byte field, EOF was encountered")
-		return
+	var pl scan.PrefixLookup
+	if prefixLookup != "" {
+		pl, err = readPrefixLookup(prefixLookup)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
 	}
-	n = 8
-	s = Blue(fmt.Sprintf("%X", bz[:8]))
-	fmt.Printf("%s%s\n", indent, s)
-	return
-}
 
-func scanByteLength(bz []byte, indent string) (s string, n int, err error) {
-	// Read the length.
-	l64, _n := binary.Uvarint(bz)
-	if n < 0 {
-		n = 0
-		err = errors.New("error decoding uvarint")
-		return
-	}
-	length := int(l64)
-	if length >= len(bz) {
-		err = errors.New("while reading 8byte field, EOF was encountered")
+	// If we just have one frame...
+	if !lengthPrefixed {
+		if err = scanFrame(bz, sc, typ, pl, format); err != nil {
+			os.Exit(2)
+		}
 		return
 	}
-	s = Cyan(fmt.Sprintf("%X", bz[:_n]))
-	slide(&bz, &n, _n)
-	// Read the remaining bytes.
-	s += Green(fmt.Sprintf("%X", bz[:length]))
-	slide(&bz, &n, length)
-	fmt.Printf("%s%s (%v bytes)\n", indent, s, length)
-	return
-}
 
-func scanStruct(bz []byte, indent string, isRoot bool) (s string, n int, err error) {
-	var (
-		_s  string
-		_n  int
-		typ amino.Typ3
-	)
-	for {
-		if isRoot && len(bz) == 0 {
-			return
+	// Scan each length-prefixed frame.
+	var failed bool
+	for i := 0; len(bz) > 0; i++ {
+		frame, n, err := amino.DecodeByteSlice(bz)
+		if err != nil {
+			fmt.Printf("Frame %v: %v\n", i, err)
+			fmt.Println(Red(fmt.Sprintf("%X", bz))) // Bytes remaining are red.
+			os.Exit(2)
 		}
-		_s, typ, _n, err = scanFieldKey(bz, indent+"  ")
-		if slide(&bz, &n, _n) && concat(&s, _s) && err != nil {
-			return
+		switch format {
+		case "color":
+			fmt.Println(Yellow(fmt.Sprintf("## Frame %v (%v bytes)", i, len(frame))))
+		case "tree":
+			fmt.Printf("## Frame %v (%v bytes)\n", i, len(frame))
 		}
-		_s, _n, err = scanAny(typ, bz, indent+"  ")
-		if slide(&bz, &n, _n) && concat(&s, _s) && err != nil {
-			return
+		if err = scanFrame(frame, sc, typ, pl, format); err != nil {
+			failed = true
 		}
+		bz = bz[n:]
 	}
-}
-
-func scanFieldKey(bz []byte, indent string) (s string, typ amino.Typ3, n int, err error) {
-	var u64 uint64
-	u64, n = binary.Uvarint(bz)
-	if n < 0 {
-		n = 0
-		err = errors.New("error decoding uvarint")
-		return
+	if failed {
+		os.Exit(2)
 	}
-	typ = amino.Typ3(u64 & 0x07)
-	number := uint32(u64 >> 3)
-	s = fmt.Sprintf("%X", bz[:n])
-	fmt.Printf("%s%s @%v %v\n", indent, s, number, typ)
-	return
 }
 
-func scan4Byte(bz []byte, indent string) (s string, n int, err error) {
-	if len(bz) < 4 {
-		err = errors.New("while reading 8byte field, EOF was encountered")
-		return
-	}
-	n = 4
-	s = Blue(fmt.Sprintf("%X", bz[:4]))
-	fmt.Printf("%s%s\n", indent, s)
-	return
-}
-
-/*
-func scanList(bz []byte, indent string) (s string, n int, err error) {
-	// Read element Typ4.
-	if len(bz) < 1 {
-		err = errors.New("EOF while reading list element typ4.")
-		return
-	}
-	var typ = amino.Typ4(bz[0])
-	if typ&0xF0 > 0 {
-		err = errors.New("Invalid list element typ4 byte")
-	}
-	s = fmt.Sprintf("%X", bz[:1])
-	if slide(&bz, &n, 1) && err != nil {
-		return
-	}
-	// Read number of elements.
-	var num, _n = uint64(0), int(0)
-	num, _n = binary.Uvarint(bz)
-	if _n < 0 {
-		_n = 0
-		err = errors.New("error decoding list length (uvarint)")
+// Scans and prints bz, as the given type if we have a schema.  Returns the
+// error printed, if bz couldn't be decoded.
+func scanFrame(bz []byte, sc *scan.Schema, typ string, pl scan.PrefixLookup, format string) error {
+	var node *scan.Node
+	var err error
+	var root = "Struct (assumed)"
+	if sc != nil {
+		node, err = sc.Scan(bz, typ)
+		if node != nil {
+			root = node.Type
+		}
+	} else if names := pl.Lookup(bz); len(names) > 0 {
+		node, err = scan.ScanConcrete(bz, names)
+		root = strings.Join(names, " or ")
+	} else {
+		node, err = scan.Scan(bz) // Assume that it's a struct.
 	}
-	s += Cyan(fmt.Sprintf("%X", bz[:_n]))
-	if slide(&bz, &n, _n) && err != nil {
-		return
+	if node == nil {
+		fmt.Println(err)
+		return err
 	}
-	fmt.Printf("%s%s of %v with %v items\n", indent, s, typ, num)
-	// Read elements.
-	var _s string
-	for i := 0; i < int(num); i++ {
-		// Maybe read nil byte.
-		if typ&0x08 != 0 {
-			if len(bz) == 0 {
-				err = errors.New("EOF while reading list nil byte")
-				return
-			}
-			var nb = bz[0]
-			slide(&bz, &n, 1)
-			switch nb {
-			case 0x00:
-				s += "00"
-				fmt.Printf("%s00 (not nil)\n", indent)
-			case 0x01:
-				s += "01" // Is nil (NOTE: reverse logic)
-				fmt.Printf("%s01 (is nil)\n", indent)
-				continue
-			default:
-				err = fmt.Errorf("Unexpected nil pointer byte %X", nb)
-				return
-			}
+
+	// Print the nodes.
+	switch format {
+	case "color":
+		fmt.Println(Yellow("## Root " + root))
+		if werr := scan.WriteTree(os.Stdout, node, colorNode); werr != nil {
+			fmt.Fprintln(os.Stderr, werr)
+			return werr
+		}
+		fmt.Println(Yellow("## Root " + root + " END"))
+		fmt.Println(node.Hex(colorNode), len(bz), err) // Print color-encoded bytes.
+	case "tree":
+		if werr := scan.WriteTree(os.Stdout, node, nil); werr != nil {
+			fmt.Fprintln(os.Stderr, werr)
+			return werr
 		}
-		// Read element.
-		_s, _n, err = scanAny(typ.Typ3(), bz, indent+"  ")
-		if slide(&bz, &n, _n) && concat(&s, _s) && err != nil {
-			return
+		if err != nil {
+			fmt.Println(err)
 		}
+	case "json":
+		jsonBz, jerr := json.MarshalIndent(node, "", "  ")
+		if jerr != nil {
+			fmt.Fprintln(os.Stderr, jerr)
+			return jerr
+		}
+		fmt.Println(string(jsonBz))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	default:
+		err = fmt.Errorf("unknown format %v", format)
+		fmt.Println(err)
 	}
-	return
+	return err
 }
-*/
 
-/*
-func scanInterface(bz []byte, indent string) (s string, n int, err error) {
-	db, hasDb, pb, typ, _, isNil, _n, err := amino.DecodeDisambPrefixBytes(bz)
-	if slide(&bz, &n, _n) && err != nil {
-		return
-	}
-	pb3 := pb
-	if isNil {
-		s = Magenta("0000")
-	} else if hasDb {
-		s = Magenta(fmt.Sprintf("%X%X", db.Bytes(), pb3.Bytes()))
-	} else {
-		s = Magenta(fmt.Sprintf("%X", pb3.Bytes()))
-	}
-	if isNil {
-		fmt.Printf("%s%s (nil interface)\n", indent, s)
-	} else if hasDb {
-		fmt.Printf("%s%s (disamb: %X, prefix: %X, typ: %v)\n",
-			indent, s, db.Bytes(), pb.Bytes(), typ)
-	} else {
-		fmt.Printf("%s%s (prefix: %X, typ: %v)\n",
-			indent, s, pb.Bytes(), typ)
-	}
-	_s, _n, err := scanAny(typ, bz, indent)
-	if slide(&bz, &n, _n) && concat(&s, _s) && err != nil {
-		return
+// Colors the bytes of each kind of node.
+func colorNode(kind scan.Kind, s string) string {
+	switch kind {
+	case scan.KindLength:
+		return Cyan(s)
+	case scan.KindPrefix:
+		return Yellow(s)
+	case scan.KindVarint, scan.Kind8Byte, scan.Kind4Byte:
+		return Blue(s)
+	case scan.KindRaw:
+		return Green(s)
+	case scan.KindRemaining:
+		return Red(s) // Bytes remaining are red.
+	default:
+		return s
 	}
-	return
 }
-*/
 
 //----------------------------------------
 // Misc.
 
-func slide(bzPtr *[]byte, n *int, _n int) bool {
-	if len(*bzPtr) < _n {
-		panic("eof")
-	}
-	*bzPtr = (*bzPtr)[_n:]
-	*n += _n
-	return true
-}
-
-func concat(sPtr *string, _s string) bool {
-	*sPtr += _s
-	return true
-}
-
-func hexDecode(s string) []byte {
-	bz, err := hex.DecodeString(s)
+func readPrefixLookup(path string) (scan.PrefixLookup, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	return bz
+	defer f.Close() // nolint: errcheck
+	return scan.ReadPrefixLookup(f)
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/tests"
+	"github.com/tendermint/go-amino/vectors"
+)
+
+func main() {
+	// Parse flags...
+	var dir string
+	var seed int64
+	var samples int
+	flgs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	flgs.StringVar(&dir, "dir", "vectors", "The directory to write to.")
+	flgs.Int64Var(&seed, "seed", 0, "The gofuzz seed.")
+	flgs.IntVar(&samples, "samples", 4, "The number of samples per type.")
+	err := flgs.Parse(os.Args[1:])
+	if err == flag.ErrHelp {
+		fmt.Println(`Usage: aminovectors [--dir=vectors] [--seed=0] [--samples=4] or --help
+
+		aminovectors writes golden test vectors of the types of the amino test
+		suite (package tests), registered as "tests/<Name>", for ports of amino
+		to other languages.  Each sample is a JSON file of its registered name,
+		Go type, and amino JSON, binary and length-prefixed binary encodings.
+
+		> aminovectors --dir=vectors
+		  (writes vectors/tests_PrimitivesStruct-0.json, ...)
+
+		The samples are made by gofuzz, and are the same for the same seed.`)
+		return
+	} else if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	cdc := newTestsCodec()
+	err = vectors.Generate(cdc, dir, &vectors.Options{Seed: seed, Samples: samples})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// Returns a codec with the types of package tests registered.
+func newTestsCodec() *amino.Codec {
+	cdc := amino.NewCodec()
+	for _, ptr := range tests.StructTypes {
+		cdc.RegisterConcrete(ptr, "tests/"+reflect.TypeOf(ptr).Elem().Name(), nil)
+	}
+	for _, ptr := range tests.DefTypes {
+		cdc.RegisterConcrete(ptr, "tests/"+reflect.TypeOf(ptr).Elem().Name(), nil)
+	}
+	cdc.RegisterInterface((*tests.Interface1)(nil), nil)
+	cdc.RegisterConcrete((*tests.InterfaceFieldsStruct)(nil), "tests/InterfaceFieldsStruct", nil)
+	cdc.RegisterConcrete(tests.Concrete1{}, "tests/Concrete1", nil)
+	cdc.RegisterConcrete(tests.Concrete2{}, "tests/Concrete2", nil)
+	cdc.RegisterConcrete(tests.ConcreteWrappedBytes{}, "tests/ConcreteWrappedBytes", nil)
+	return cdc.Seal()
+}
@@ -0,0 +1,65 @@
+// Package cmdutil has the input handling shared by the amino commands.
+package cmdutil
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/tendermint/go-amino/scan"
+)
+
+// ReadInput reads the bytes from file (or stdin if "-"), or else from arg (or
+// stdin if empty), in the given input encoding.
+func ReadInput(arg string, file string, input string) ([]byte, error) {
+	var bz []byte
+	var err error
+	switch {
+	case file != "":
+		bz, err = ReadFile(file)
+	case arg == "":
+		bz, err = ioutil.ReadAll(os.Stdin)
+	default:
+		bz = []byte(arg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return DecodeInput(bz, input)
+}
+
+// ReadFile reads the named file, or stdin if "-".
+func ReadFile(file string) ([]byte, error) {
+	if file == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(file)
+}
+
+// DecodeInput decodes bz in the input encoding: hex, base64 (both ignoring
+// whitespace) or raw.
+func DecodeInput(bz []byte, input string) ([]byte, error) {
+	switch input {
+	case "hex":
+		return hex.DecodeString(strings.Join(strings.Fields(string(bz)), ""))
+	case "base64":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(bz)), ""))
+	case "raw":
+		return bz, nil
+	default:
+		return nil, fmt.Errorf("unknown input encoding %v", input)
+	}
+}
+
+// ReadSchema reads the schema of a registry dumped by Codec.ExportRegistry.
+func ReadSchema(path string) (*scan.Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+	return scan.ReadSchema(f)
+}
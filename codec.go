@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"unicode"
@@ -113,15 +114,19 @@ type FieldInfo struct {
 }
 
 type FieldOptions struct {
-	JSONName      string // (JSON) field name
-	JSONOmitEmpty bool   // (JSON) omitempty
-	BinFixed64    bool   // (Binary) Encode as fixed64
-	BinFixed32    bool   // (Binary) Encode as fixed32
-	BinFieldNum   uint32 // (Binary) max 1<<29-1
+	JSONName      string   // (JSON) field name
+	JSONOmitEmpty bool     // (JSON) omitempty
+	JSONAliases   []string // (JSON) legacy field names also accepted when decoding
+	JSONSkip      bool     // (JSON) omit the field from JSON only
+	BinFixed64    bool     // (Binary) Encode as fixed64
+	BinFixed32    bool     // (Binary) Encode as fixed32
+	BinFieldNum   uint32   // (Binary) max 1<<29-1, reserved but unused if BinSkip
+	BinSkip       bool     // (Binary) omit the field from binary only
 
 	Unsafe        bool // e.g. if this field is a float.
 	WriteEmpty    bool // write empty structs and lists (default false except for pointers)
 	EmptyElements bool // Slice and Array elements are never nil, decode 0x00 as empty struct.
+	Optional      bool // Pointer fields are written iff non-nil, and decode to nil if absent.
 }
 
 //----------------------------------------
@@ -135,6 +140,7 @@ type Codec struct {
 	concreteInfos    []*TypeInfo
 	disfixToTypeInfo map[DisfixBytes]*TypeInfo
 	nameToTypeInfo   map[string]*TypeInfo
+	formats          map[string]func() Format
 }
 
 func NewCodec() *Codec {
@@ -143,6 +149,7 @@ func NewCodec() *Codec {
 		typeInfos:        make(map[reflect.Type]*TypeInfo),
 		disfixToTypeInfo: make(map[DisfixBytes]*TypeInfo),
 		nameToTypeInfo:   make(map[string]*TypeInfo),
+		formats:          map[string]func() Format{"json": newJSONFormat, "text": newTextFormat},
 	}
 	return cdc
 }
@@ -308,6 +315,37 @@ func (cdc *Codec) PrintTypes(out io.Writer) error {
 	return nil
 }
 
+// ConcreteTypes returns the TypeInfos of the registered concrete types, in
+// order of registration.
+func (cdc *Codec) ConcreteTypes() []*TypeInfo {
+	cdc.mtx.RLock()
+	defer cdc.mtx.RUnlock()
+	var cinfos = make([]*TypeInfo, len(cdc.concreteInfos))
+	copy(cinfos, cdc.concreteInfos)
+	return cinfos
+}
+
+// Implementers returns the TypeInfos of the registered concrete types that
+// implement the registered interface irt, by pointer at least, sorted by
+// name.
+func (cdc *Codec) Implementers(irt reflect.Type) ([]*TypeInfo, error) {
+	iinfo, err := cdc.getTypeInfoWlock(irt)
+	if err != nil {
+		return nil, err
+	}
+	if iinfo.Type.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("%v is not an interface", irt)
+	}
+	var cinfos []*TypeInfo
+	cdc.mtx.RLock()
+	for _, infos := range iinfo.Implementers {
+		cinfos = append(cinfos, infos...)
+	}
+	cdc.mtx.RUnlock()
+	sort.Slice(cinfos, func(i, j int) bool { return cinfos[i].Name < cinfos[j].Name })
+	return cinfos, nil
+}
+
 // A heuristic to guess the size of a registered type and return it as a string.
 // If the size is not fixed it returns "variable".
 func getLengthStr(info *TypeInfo) string {
@@ -451,6 +489,7 @@ func (cdc *Codec) parseStructInfo(rt reflect.Type) (sinfo StructInfo) {
 	}
 
 	var infos = make([]FieldInfo, 0, rt.NumField())
+	var numBinFields uint32
 	for i := 0; i < rt.NumField(); i++ {
 		var field = rt.Field(i)
 		var ftype = field.Type
@@ -460,6 +499,9 @@ func (cdc *Codec) parseStructInfo(rt reflect.Type) (sinfo StructInfo) {
 		}
 		skip, fopts := cdc.parseFieldOptions(field)
 		if skip {
+			if fopts.BinSkip {
+				numBinFields++ // Reserved, as below.
+			}
 			continue // e.g. json:"-"
 		}
 		if ftype.Kind() == reflect.Array || ftype.Kind() == reflect.Slice {
@@ -480,7 +522,10 @@ func (cdc *Codec) parseStructInfo(rt reflect.Type) (sinfo StructInfo) {
 		}
 		// NOTE: This is going to change a bit.
 		// NOTE: BinFieldNum starts with 1.
-		fopts.BinFieldNum = uint32(len(infos) + 1)
+		// NOTE: Fields omitted from binary keep their field number reserved,
+		// so that omitting a field doesn't renumber the fields after it.
+		numBinFields++
+		fopts.BinFieldNum = numBinFields
 		fieldInfo := FieldInfo{
 			Name:         field.Name, // Mostly for debugging.
 			Index:        i,
@@ -490,6 +535,7 @@ func (cdc *Codec) parseStructInfo(rt reflect.Type) (sinfo StructInfo) {
 			FieldOptions: fopts,
 		}
 		checkUnsafe(fieldInfo)
+		checkOptional(fieldInfo)
 		infos = append(infos, fieldInfo)
 	}
 	sinfo = StructInfo{infos}
@@ -502,7 +548,7 @@ func (cdc *Codec) parseFieldOptions(field reflect.StructField) (skip bool, fopts
 	jsonTag := field.Tag.Get("json")
 
 	// If `json:"-"`, don't encode.
-	// NOTE: This skips binary as well.
+	// NOTE: This skips binary as well, see `amino:"-json"` to skip JSON only.
 	if jsonTag == "-" {
 		skip = true
 		return
@@ -542,6 +588,24 @@ func (cdc *Codec) parseFieldOptions(field reflect.StructField) (skip bool, fopts
 		if aminoTag == "empty_elements" {
 			fopts.EmptyElements = true
 		}
+		if aminoTag == "optional" {
+			fopts.Optional = true
+		}
+		if aminoTag == "-binary" {
+			fopts.BinSkip = true
+		}
+		if aminoTag == "-json" {
+			fopts.JSONSkip = true
+		}
+		if strings.HasPrefix(aminoTag, "json_alias=") {
+			fopts.JSONAliases = append(fopts.JSONAliases,
+				strings.TrimPrefix(aminoTag, "json_alias="))
+		}
+	}
+
+	// If omitted from both binary and JSON, don't encode.
+	if fopts.BinSkip && fopts.JSONSkip {
+		skip = true
 	}
 
 	return skip, fopts
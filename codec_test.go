@@ -3,6 +3,9 @@ package amino_test
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -11,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/tests"
 )
 
 type SimpleStruct struct {
@@ -250,3 +254,175 @@ func TestCodecSeal(t *testing.T) {
 	assert.Panics(t, func() { cdc.RegisterInterface((*Bar)(nil), nil) })
 	assert.Panics(t, func() { cdc.RegisterConcrete(int(0), "int", nil) })
 }
+
+func TestFieldSkipAndAliases(t *testing.T) {
+	type Account struct {
+		Name     string `json:"name" amino:"json_alias=username,json_alias=user"`
+		Cache    string `amino:"-binary"`
+		Secret   string `json:"secret" amino:"-json"`
+		Balance  int64  `json:"balance"`
+		Internal string `amino:"-binary,-json"`
+	}
+
+	cdc := amino.NewCodec()
+	acc := Account{Name: "a", Cache: "c", Secret: "s", Balance: 2, Internal: "i"}
+
+	// Binary omits Cache and Internal, whose field numbers stay reserved.
+	bz, err := cdc.MarshalBinaryBare(acc)
+	require.NoError(t, err)
+	assert.Equal(t, "0A0161"+"1A0173"+"2002", fmt.Sprintf("%X", bz))
+	var acc2 Account
+	err = cdc.UnmarshalBinaryBare(bz, &acc2)
+	require.NoError(t, err)
+	assert.Equal(t, Account{Name: "a", Secret: "s", Balance: 2}, acc2)
+
+	// JSON omits Secret and Internal.
+	bz, err = cdc.MarshalJSON(acc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"a","Cache":"c","balance":"2"}`, string(bz))
+	var acc3 Account
+	err = cdc.UnmarshalJSON(bz, &acc3)
+	require.NoError(t, err)
+	assert.Equal(t, Account{Name: "a", Cache: "c", Balance: 2}, acc3)
+
+	// Legacy names are accepted when decoding, the new name takes precedence.
+	var acc4 Account
+	err = cdc.UnmarshalJSON([]byte(`{"user":"old","balance":"3"}`), &acc4)
+	require.NoError(t, err)
+	assert.Equal(t, Account{Name: "old", Balance: 3}, acc4)
+	err = cdc.UnmarshalJSON([]byte(`{"username":"old","name":"new"}`), &acc4)
+	require.NoError(t, err)
+	assert.Equal(t, "new", acc4.Name)
+}
+
+func TestFieldSkipKeepsFieldNumbers(t *testing.T) {
+	type AccountV1 struct {
+		Name    string
+		Cache   string
+		Balance int64
+	}
+	type AccountV2 struct {
+		Name    string
+		Cache   string `amino:"-binary"`
+		Balance int64
+	}
+	type AccountV3 struct {
+		Name    string `amino:"-binary,-json"`
+		Cache   string `amino:"-binary"`
+		Balance int64
+	}
+
+	// Omitting fields from binary doesn't renumber Balance.
+	cdc := amino.NewCodec()
+	bz1, err := cdc.MarshalBinaryBare(AccountV1{Balance: 2})
+	require.NoError(t, err)
+	bz2, err := cdc.MarshalBinaryBare(AccountV2{Cache: "c", Balance: 2})
+	require.NoError(t, err)
+	assert.Equal(t, bz1, bz2)
+	bz3, err := cdc.MarshalBinaryBare(AccountV3{Name: "a", Cache: "c", Balance: 2})
+	require.NoError(t, err)
+	assert.Equal(t, bz1, bz3)
+	var acc AccountV1
+	require.NoError(t, cdc.UnmarshalBinaryBare(bz3, &acc))
+	assert.Equal(t, AccountV1{Balance: 2}, acc)
+}
+
+func TestExportRegistry(t *testing.T) {
+	type Pet interface{}
+	type Dog struct {
+		Name  string    `json:"name"`
+		Born  time.Time `json:"born"`
+		Tags  []string  `amino:"json_alias=labels"`
+		Other *int64    `binary:"fixed64" amino:"optional"`
+	}
+
+	cdc := amino.NewCodec()
+	cdc.RegisterInterface((*Pet)(nil), nil)
+	cdc.RegisterConcrete(Dog{}, "registry/Dog", nil)
+
+	buf := new(bytes.Buffer)
+	err := cdc.ExportRegistry(buf)
+	require.NoError(t, err)
+	var rd amino.RegistryDescription
+	err = json.Unmarshal(buf.Bytes(), &rd)
+	require.NoError(t, err)
+
+	pet, ok := rd.Type("github.com/tendermint/go-amino_test.Pet")
+	require.True(t, ok)
+	assert.Equal(t, "interface", pet.Kind)
+	assert.Equal(t, []string{"github.com/tendermint/go-amino_test.Dog"}, pet.Implementers)
+
+	dog, ok := rd.Type("github.com/tendermint/go-amino_test.Dog")
+	require.True(t, ok)
+	assert.True(t, dog.Registered)
+	assert.Equal(t, "registry/Dog", dog.Name)
+	_, pb := amino.NameToDisfix("registry/Dog")
+	assert.Equal(t, fmt.Sprintf("%X", pb.Bytes()), dog.Prefix)
+	require.Len(t, dog.Fields, 4)
+	assert.Equal(t, amino.FieldDescription{Name: "Tags", Type: "[]string", JSONName: "Tags",
+		JSONAliases: []string{"labels"}, BinFieldNum: 3, UnpackedList: true}, dog.Fields[2])
+	assert.Equal(t, amino.FieldDescription{Name: "Other", Type: "*int64", JSONName: "Other",
+		BinFieldNum: 4, BinFixed64: true, Optional: true}, dog.Fields[3])
+
+	// Referenced types are described too.
+	for _, rt := range []string{"time.Time", "[]string", "string", "*int64", "int64"} {
+		_, ok = rd.Type(rt)
+		assert.True(t, ok, rt)
+	}
+	slice, _ := rd.Type("[]string")
+	assert.Equal(t, "string", slice.Elem)
+
+	// Types of the same name declared in different functions can't be told
+	// apart.
+	cdc.RegisterConcrete(exportRegistryDog(), "registry/OtherDog", nil)
+	_, err = cdc.DescribeRegistry()
+	assert.EqualError(t, err, "cannot describe distinct types of the same TypeString github.com/tendermint/go-amino_test.Dog")
+}
+
+func exportRegistryDog() interface{} {
+	type Dog struct{ Name string }
+	return Dog{}
+}
+
+func TestConcreteTypesAndImplementers(t *testing.T) {
+	cdc := amino.NewCodec()
+	registerTransports(cdc)
+
+	var names []string
+	for _, cinfo := range cdc.ConcreteTypes() {
+		names = append(names, cinfo.Name)
+	}
+	assert.Equal(t, []string{"our/transport", "car", "insuranceplan", "boat", "plane"}, names)
+
+	impls, err := cdc.Implementers(reflect.TypeOf((*Vehicle)(nil)).Elem())
+	require.NoError(t, err)
+	names = nil
+	for _, cinfo := range impls {
+		names = append(names, cinfo.Name)
+	}
+	assert.Equal(t, []string{"boat", "car", "our/transport", "plane"}, names) // Transport embeds Vehicle.
+
+	_, err = cdc.Implementers(reflect.TypeOf(Car("")))
+	assert.EqualError(t, err, "amino_test.Car is not an interface")
+}
+
+func TestTypeString(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{int64(0), "int64"},
+		{(*error)(nil), "*error"},
+		{time.Time{}, "time.Time"},
+		{map[string][]*tests.EmptyStruct{}, "map[string][]*github.com/tendermint/go-amino/tests.EmptyStruct"},
+		{[2]tests.Concrete1{}, "[2]github.com/tendermint/go-amino/tests.Concrete1"},
+		{struct{}{}, "struct {}"},
+		{struct {
+			tests.Concrete1
+			A int8 `json:"a"`
+		}{}, `struct { github.com/tendermint/go-amino/tests.Concrete1; A int8 "json:\"a\"" }`},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, amino.TypeString(reflect.TypeOf(tc.v)))
+	}
+}
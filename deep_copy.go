@@ -15,43 +15,127 @@ import (
 // `.UnmarshalAmino(<any>) error`, the pair will be used to copy.
 // If .MarshalAmino() or .UnmarshalAmino() returns an error, this
 // function will panic.
+//
+// Pointers and maps that are shared within o are shared within the copy, so
+// cyclic objects can be copied too.  Unexported struct fields are left zero,
+// see DeepCopyOptions to copy them.
 func DeepCopy(o interface{}) (r interface{}) {
+	r, err := DeepCopyError(o)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// DeepCopyError is like DeepCopy, but returns an error rather than panic,
+// e.g. if .MarshalAmino() or .UnmarshalAmino() returns one.
+func DeepCopyError(o interface{}) (r interface{}, err error) {
+	return DeepCopyOptions{}.DeepCopy(o)
+}
+
+// DeepCopyInto deeply copies src into dst, as by DeepCopy, where dst is a
+// pointer to a value of the type of src, or of the type src points to.
+func DeepCopyInto(dst, src interface{}) error {
+	return DeepCopyOptions{}.DeepCopyInto(dst, src)
+}
+
+// DeepCopyOptions are the options of a deep copy.  The zero value is the
+// default, of DeepCopy.
+type DeepCopyOptions struct {
+	// If true, unexported struct fields are copied, but shallowly as by
+	// assignment.  Otherwise they're left zero.
+	CopyUnexported bool
+}
+
+// DeepCopy is like amino.DeepCopyError, with the options.
+func (opts DeepCopyOptions) DeepCopy(o interface{}) (r interface{}, err error) {
 	if o == nil {
-		return nil
+		return nil, nil
 	}
 	src := reflect.ValueOf(o)
 	dst := reflect.New(src.Type()).Elem()
-	deepCopy(src, dst)
-	return dst.Interface()
+	if err = newDeepCopier(opts).deepCopy(src, dst); err != nil {
+		return nil, err
+	}
+	return dst.Interface(), nil
+}
+
+// DeepCopyInto is like amino.DeepCopyInto, with the options.
+func (opts DeepCopyOptions) DeepCopyInto(dst, src interface{}) error {
+	drv, srv := reflect.ValueOf(dst), reflect.ValueOf(src)
+	if drv.Kind() != reflect.Ptr || drv.IsNil() || !srv.IsValid() {
+		return fmt.Errorf("DeepCopyInto expects a non-nil pointer dst and a non-nil src, got %T and %T", dst, src)
+	}
+	if srv.Type() == drv.Type() {
+		if srv.IsNil() {
+			return fmt.Errorf("DeepCopyInto expects a non-nil src, got nil %T", src)
+		}
+		srv = srv.Elem()
+	}
+	if srv.Type() != drv.Type().Elem() {
+		return fmt.Errorf("DeepCopyInto expects dst to be a pointer to %v, got %T", srv.Type(), dst)
+	}
+	var dc = newDeepCopier(opts)
+	var elem = reflect.New(srv.Type()).Elem()
+	if err := dc.deepCopy(srv, elem); err != nil {
+		return err
+	}
+	drv.Elem().Set(elem)
+	return nil
+}
+
+// The state of a deep copy.
+type deepCopier struct {
+	DeepCopyOptions
+	copies map[deepCopyKey]reflect.Value // Of the pointers and maps copied so far.
+}
+
+type deepCopyKey struct {
+	ptr uintptr
+	rt  reflect.Type
 }
 
-func deepCopy(src, dst reflect.Value) {
+func newDeepCopier(opts DeepCopyOptions) *deepCopier {
+	return &deepCopier{
+		DeepCopyOptions: opts,
+		copies:          make(map[deepCopyKey]reflect.Value),
+	}
+}
+
+func (dc *deepCopier) deepCopy(src, dst reflect.Value) error {
 	if isNil(src) {
-		return
+		return nil
 	}
 	if callDeepCopy(src, dst) {
-		return
+		return nil
 	}
-	if callAminoCopy(src, dst) {
-		return
+	if ok, err := callAminoCopy(src, dst); ok || err != nil {
+		return err
 	}
-	_deepCopy(src, dst)
+	return dc._deepCopy(src, dst)
 }
 
-func _deepCopy(src, dst reflect.Value) {
+func (dc *deepCopier) _deepCopy(src, dst reflect.Value) error {
 
 	switch src.Kind() {
 	case reflect.Ptr:
+		key := deepCopyKey{src.Pointer(), src.Type()}
+		if cpy, ok := dc.copies[key]; ok {
+			dst.Set(cpy)
+			return nil
+		}
 		cpy := reflect.New(src.Type().Elem())
-		_deepCopy(src.Elem(), cpy.Elem())
+		dc.copies[key] = cpy
 		dst.Set(cpy)
-		return
+		return dc.deepCopy(src.Elem(), cpy.Elem())
 
 	case reflect.Interface:
 		cpy := reflect.New(src.Elem().Type())
-		deepCopy(src.Elem(), cpy.Elem())
+		if err := dc.deepCopy(src.Elem(), cpy.Elem()); err != nil {
+			return err
+		}
 		dst.Set(cpy.Elem())
-		return
+		return nil
 
 	case reflect.Array:
 		switch src.Type().Elem().Kind() {
@@ -59,17 +143,20 @@ func _deepCopy(src, dst reflect.Value) {
 			reflect.Int8, reflect.Int, reflect.Uint64,
 			reflect.Uint32, reflect.Uint16, reflect.Uint8,
 			reflect.Uint, reflect.Bool, reflect.Float64,
-			reflect.Float32, reflect.String:
+			reflect.Float32, reflect.Complex128,
+			reflect.Complex64, reflect.String:
 
 			reflect.Copy(dst, src)
-			return
+			return nil
 		default:
 			for i := 0; i < src.Type().Len(); i++ {
 				esrc := src.Index(i)
 				edst := dst.Index(i)
-				deepCopy(esrc, edst)
+				if err := dc.deepCopy(esrc, edst); err != nil {
+					return err
+				}
 			}
-			return
+			return nil
 		}
 
 	case reflect.Slice:
@@ -78,51 +165,67 @@ func _deepCopy(src, dst reflect.Value) {
 			reflect.Int8, reflect.Int, reflect.Uint64,
 			reflect.Uint32, reflect.Uint16, reflect.Uint8,
 			reflect.Uint, reflect.Bool, reflect.Float64,
-			reflect.Float32, reflect.String:
+			reflect.Float32, reflect.Complex128,
+			reflect.Complex64, reflect.String:
 
 			cpy := reflect.MakeSlice(
 				src.Type(), src.Len(), src.Len())
 			reflect.Copy(cpy, src)
-			dst.Set(src)
-			return
+			dst.Set(cpy)
+			return nil
 		default:
 			cpy := reflect.MakeSlice(
 				src.Type(), src.Len(), src.Len())
 			for i := 0; i < src.Len(); i++ {
 				esrc := src.Index(i)
 				ecpy := cpy.Index(i)
-				deepCopy(esrc, ecpy)
+				if err := dc.deepCopy(esrc, ecpy); err != nil {
+					return err
+				}
 			}
-			dst.Set(src)
-			return
+			dst.Set(cpy)
+			return nil
 		}
 
 	case reflect.Struct:
 		switch src.Type() {
 		case timeType:
 			dst.Set(src)
-			return
+			return nil
 		default:
+			if dc.CopyUnexported {
+				dst.Set(src) // Then overwrite the exported fields.
+			}
 			for i := 0; i < src.NumField(); i++ {
 				if !isExported(src.Type().Field(i)) {
 					continue // field is unexported
 				}
 				srcf := src.Field(i)
 				dstf := dst.Field(i)
-				deepCopy(srcf, dstf)
+				if err := dc.deepCopy(srcf, dstf); err != nil {
+					return err
+				}
 			}
-			return
+			return nil
 		}
 
 	case reflect.Map:
-		cpy := reflect.MakeMapWithSize(src.Type(), src.Len())
-		keys := src.MapKeys()
-		for _, key := range keys {
-			val := src.MapIndex(key)
-			cpy.SetMapIndex(key, val)
+		key := deepCopyKey{src.Pointer(), src.Type()}
+		if cpy, ok := dc.copies[key]; ok {
+			dst.Set(cpy)
+			return nil
 		}
+		cpy := reflect.MakeMapWithSize(src.Type(), src.Len())
+		dc.copies[key] = cpy
 		dst.Set(cpy)
-		return
+		for _, mkey := range src.MapKeys() {
+			val := reflect.New(src.Type().Elem()).Elem()
+			if err := dc.deepCopy(src.MapIndex(mkey), val); err != nil {
+				return err
+			}
+			cpy.SetMapIndex(mkey, val)
+		}
+		return nil
 
 	// Primitive types
 	case reflect.Int64, reflect.Int32, reflect.Int16,
@@ -135,13 +238,16 @@ func _deepCopy(src, dst reflect.Value) {
 		dst.SetBool(src.Bool())
 	case reflect.Float64, reflect.Float32:
 		dst.SetFloat(src.Float())
+	case reflect.Complex128, reflect.Complex64:
+		dst.SetComplex(src.Complex())
 	case reflect.String:
 		dst.SetString(src.String())
 
 	default:
-		panic(fmt.Sprintf("unsupported type %v", src.Kind()))
+		return fmt.Errorf("unsupported type %v", src.Kind())
 	}
 
+	return nil
 }
 
 //----------------------------------------
@@ -177,9 +283,9 @@ func callDeepCopy(src, dst reflect.Value) bool {
 }
 
 // Call .MarshalAmino() and .UnmarshalAmino to copy if possible.
-// Panics if .MarshalAmino() or .UnmarshalAmino() return an error.
+// Returns an error if .MarshalAmino() or .UnmarshalAmino() return one.
 // CONTRACT: src and dst are of equal types.
-func callAminoCopy(src, dst reflect.Value) bool {
+func callAminoCopy(src, dst reflect.Value) (bool, error) {
 	if src.Type() != dst.Type() {
 		panic("should not happen")
 	}
@@ -187,33 +293,33 @@ func callAminoCopy(src, dst reflect.Value) bool {
 	case src.Kind() == reflect.Ptr:
 		cpy := reflect.New(src.Type().Elem())
 		dst.Set(cpy)
+		dst = cpy
 	case src.CanAddr():
 		if !dst.CanAddr() {
 			panic("should not happen")
 		}
+		// Unmarshal into dst in place.
 		src = src.Addr()
 		dst = dst.Addr()
 	default:
-		return false
+		return false, nil
 	}
 	if !canAminoCopy(src) {
-		return false
+		return false, nil
 	}
-	cpy := reflect.New(src.Type().Elem())
-	dst.Set(cpy)
 	ma := src.MethodByName("MarshalAmino")
 	ua := dst.MethodByName("UnmarshalAmino")
 	outs := ma.Call(nil)
 	repr, err := outs[0], outs[1]
 	if !err.IsNil() {
-		panic(err.Interface())
+		return true, err.Interface().(error)
 	}
 	outs = ua.Call([]reflect.Value{repr})
 	err = outs[0]
 	if !err.IsNil() {
-		panic(err.Interface())
+		return true, err.Interface().(error)
 	}
-	return true
+	return true, nil
 }
 
 func canAminoCopy(rv reflect.Value) bool {
@@ -129,3 +129,109 @@ func TestDeepCopyInterface2(t *testing.T) {
 	dci2 := amino.DeepCopy(dci1).(DCInterface1)
 	assert.Equal(t, "foo", dci2.Foo)
 }
+
+func TestDeepCopyError(t *testing.T) {
+	_, err := amino.DeepCopyError(newDCFoo8("foobar"))
+	assert.EqualError(t, err, "uh oh")
+	_, err = amino.DeepCopyError(newDCFoo9("foobar"))
+	assert.EqualError(t, err, "uh oh")
+	_, err = amino.DeepCopyError(DCInterface1{Foo: make(chan int)})
+	assert.EqualError(t, err, "unsupported type chan")
+
+	dcf2, err := amino.DeepCopyError(newDCFoo2("foobar"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foobar", dcf2.(*DCFoo2).a)
+}
+
+type DCMap struct {
+	Ints  map[string][]int
+	Nodes map[string]*DCNode
+}
+
+func TestDeepCopyMap(t *testing.T) {
+	dcm1 := DCMap{
+		Ints:  map[string][]int{"a": {1, 2}, "b": nil},
+		Nodes: map[string]*DCNode{"c": {Name: "c"}},
+	}
+	dcm2 := amino.DeepCopy(dcm1).(DCMap)
+	assert.Equal(t, dcm1, dcm2)
+
+	dcm2.Ints["a"][0] = 3
+	dcm2.Nodes["c"].Name = "d"
+	assert.Equal(t, 1, dcm1.Ints["a"][0])
+	assert.Equal(t, "c", dcm1.Nodes["c"].Name)
+}
+
+type DCNode struct {
+	Name  string
+	Next  *DCNode
+	Nodes []*DCNode
+	Attrs map[string]interface{}
+}
+
+func TestDeepCopyCycles(t *testing.T) {
+	a := &DCNode{Name: "a", Attrs: map[string]interface{}{}}
+	b := &DCNode{Name: "b", Next: a}
+	a.Next = b
+	a.Nodes = []*DCNode{a, b, b}
+	a.Attrs["self"] = a.Attrs
+
+	a2 := amino.DeepCopy(a).(*DCNode)
+	assert.False(t, a == a2)
+	assert.Equal(t, "b", a2.Next.Name)
+	assert.True(t, a2.Next.Next == a2)
+	assert.True(t, a2.Nodes[0] == a2)
+	assert.True(t, a2.Nodes[1] == a2.Next)
+	assert.True(t, a2.Nodes[2] == a2.Next)
+	assert.Equal(t, 1, len(a2.Attrs))
+	a2.Attrs["new"] = 1
+	assert.Equal(t, 2, len(a2.Attrs["self"].(map[string]interface{})))
+	assert.Equal(t, 1, len(a.Attrs))
+}
+
+type DCUnexported struct {
+	Name  string
+	Bytes []byte
+	cache []byte
+}
+
+func TestDeepCopyUnexported(t *testing.T) {
+	dcu1 := DCUnexported{Name: "a", Bytes: []byte{1}, cache: []byte{2}}
+
+	dcu2 := amino.DeepCopy(dcu1).(DCUnexported)
+	assert.Equal(t, DCUnexported{Name: "a", Bytes: []byte{1}}, dcu2)
+
+	cpy, err := amino.DeepCopyOptions{CopyUnexported: true}.DeepCopy(dcu1)
+	assert.NoError(t, err)
+	dcu2 = cpy.(DCUnexported)
+	assert.Equal(t, dcu1, dcu2)
+	dcu2.Bytes[0] = 3
+	assert.Equal(t, byte(1), dcu1.Bytes[0]) // Deeply copied,
+	dcu2.cache[0] = 3
+	assert.Equal(t, byte(3), dcu1.cache[0]) // but not unexported fields.
+}
+
+func TestDeepCopyInto(t *testing.T) {
+	src := DCNode{Name: "a", Nodes: []*DCNode{{Name: "b"}}}
+
+	var dst DCNode
+	assert.NoError(t, amino.DeepCopyInto(&dst, src))
+	assert.Equal(t, src, dst)
+	assert.False(t, src.Nodes[0] == dst.Nodes[0])
+
+	dst = DCNode{Name: "old", Next: &DCNode{}}
+	assert.NoError(t, amino.DeepCopyInto(&dst, &src))
+	assert.Equal(t, src, dst)
+
+	// Addressable values are copied by .MarshalAmino() and .UnmarshalAmino().
+	var dcf DCFoo2
+	assert.NoError(t, amino.DeepCopyInto(&dcf, newDCFoo2("foobar")))
+	assert.Equal(t, "foobar", dcf.a)
+
+	assert.EqualError(t, amino.DeepCopyInto(dst, src),
+		"DeepCopyInto expects a non-nil pointer dst and a non-nil src, got amino_test.DCNode and amino_test.DCNode")
+	assert.EqualError(t, amino.DeepCopyInto(&dst, DCMap{}),
+		"DeepCopyInto expects dst to be a pointer to amino_test.DCMap, got *amino_test.DCNode")
+	assert.EqualError(t, amino.DeepCopyInto(&dst, (*DCNode)(nil)),
+		"DeepCopyInto expects a non-nil src, got nil *amino_test.DCNode")
+}
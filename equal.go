@@ -0,0 +1,237 @@
+package amino
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+//----------------------------------------
+// Equal & Diff
+
+// Equal returns whether a and b are equal as amino values, unlike
+// reflect.DeepEqual:
+//   - nil and empty slices and maps are equal,
+//   - times are equal if they're the same instant, in any location and with or
+//     without monotonic clock readings,
+//   - values with `.MarshalAmino()` are equal if their reprs are,
+//   - nil pointers are equal to pointers to default values (1970 for times),
+//     as they're decoded alike, but for `amino:"optional"` fields,
+//   - unexported and `json:"-"` struct fields are ignored.
+//
+// Otherwise, interface values are only equal if their concrete types are.
+func Equal(a, b interface{}) bool {
+	d := &differ{first: true}
+	d.diffRoot(a, b)
+	return len(d.diffs) == 0
+}
+
+// Difference is a value that differs between two, as found by Diff.
+type Difference struct {
+	Path Path        // Of the value, see ParsePath.  Interface values step into "value".
+	Old  interface{} // The value in a, or nil if absent.
+	New  interface{} // The value in b, or nil if absent.
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%v: %v -> %v", d.Path.describe(), formatDiffValue(d.Old), formatDiffValue(d.New))
+}
+
+// Diff returns the differences between a and b, as compared by Equal, in
+// field order, outermost first.  A value that differs is not stepped into if
+// it's of a different type or concrete type, or of a type with
+// `.MarshalAmino()`; otherwise the differences within it are returned.
+func Diff(a, b interface{}) []Difference {
+	d := &differ{}
+	d.diffRoot(a, b)
+	return d.diffs
+}
+
+type differ struct {
+	first bool // Only find the first difference.
+	diffs []Difference
+}
+
+func (d *differ) done() bool {
+	return d.first && len(d.diffs) > 0
+}
+
+func (d *differ) add(path Path, a, b reflect.Value) {
+	var diff = Difference{Path: append(Path(nil), path...)}
+	if a.IsValid() {
+		diff.Old = a.Interface()
+	}
+	if b.IsValid() {
+		diff.New = b.Interface()
+	}
+	d.diffs = append(d.diffs, diff)
+}
+
+func (d *differ) diffRoot(a, b interface{}) {
+	var arv, brv = reflect.ValueOf(a), reflect.ValueOf(b)
+	if !arv.IsValid() || !brv.IsValid() {
+		if arv.IsValid() != brv.IsValid() {
+			d.add(nil, arv, brv)
+		}
+		return
+	}
+	if arv.Type() != brv.Type() {
+		d.add(nil, arv, brv)
+		return
+	}
+	d.diff(nil, arv, brv, FieldOptions{})
+}
+
+// Compares values of the same type, of a field of options fopts (or of the
+// list or map they are elements of).
+func (d *differ) diff(path Path, a, b reflect.Value, fopts FieldOptions) {
+	if d.done() {
+		return
+	}
+	var rt = a.Type()
+
+	switch rt.Kind() {
+	case reflect.Ptr:
+		switch {
+		case a.IsNil() && b.IsNil():
+		case !a.IsNil() && !b.IsNil():
+			d.diff(path, a.Elem(), b.Elem(), fopts)
+		case fopts.Optional:
+			d.add(path, a, b)
+		default:
+			d.diff(path, derefPointersDefault(a), derefPointersDefault(b), fopts)
+		}
+		return
+
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, a, b)
+			}
+			return
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			d.add(path, a, b)
+			return
+		}
+		d.diff(append(path, PathStep{Name: "value"}), a.Elem(), b.Elem(), FieldOptions{})
+		return
+	}
+
+	info, err := gcdc.getTypeInfoWlock(rt)
+	if err != nil {
+		panic(err) // Only for interfaces, handled above.
+	}
+	if info.IsAminoMarshaler {
+		arrv, aerr := toReprObject(a)
+		brrv, berr := toReprObject(b)
+		if aerr != nil || berr != nil {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				d.add(path, a, b)
+			}
+			return
+		}
+		sub := &differ{first: true}
+		sub.diff(nil, arrv, brrv, fopts)
+		if len(sub.diffs) > 0 {
+			d.add(path, a, b)
+		}
+		return
+	}
+
+	switch rt.Kind() {
+	case reflect.Array, reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			if !bytes.Equal(toBytes(a), toBytes(b)) {
+				d.add(path, a, b)
+			}
+			return
+		}
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			var step = append(path, PathStep{Index: i})
+			switch {
+			case i >= a.Len():
+				d.add(step, reflect.Value{}, b.Index(i))
+			case i >= b.Len():
+				d.add(step, a.Index(i), reflect.Value{})
+			default:
+				d.diff(step, a.Index(i), b.Index(i), fopts)
+			}
+			if d.done() {
+				return
+			}
+		}
+
+	case reflect.Map:
+		var keys = append(a.MapKeys(), b.MapKeys()...)
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for i, key := range keys {
+			if i > 0 && reflect.DeepEqual(key.Interface(), keys[i-1].Interface()) {
+				continue
+			}
+			var step = append(path, PathStep{Name: fmt.Sprint(key.Interface())})
+			var aval, bval = a.MapIndex(key), b.MapIndex(key)
+			if !aval.IsValid() || !bval.IsValid() {
+				d.add(step, aval, bval)
+			} else {
+				d.diff(step, aval, bval, fopts)
+			}
+			if d.done() {
+				return
+			}
+		}
+
+	case reflect.Struct:
+		if rt == timeType {
+			if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+				d.add(path, a, b)
+			}
+			return
+		}
+		for _, field := range info.Fields {
+			d.diff(append(path, PathStep{Name: field.JSONName}), a.Field(field.Index), b.Field(field.Index),
+				field.FieldOptions)
+			if d.done() {
+				return
+			}
+		}
+
+	default:
+		if a.Interface() != b.Interface() {
+			d.add(path, a, b)
+		}
+	}
+}
+
+// Dereferences pointers, to the default value if nil, as they're decoded.
+func derefPointersDefault(rv reflect.Value) reflect.Value {
+	var drv, _, isNilPtr = derefPointersZero(rv)
+	if isNilPtr && drv.Type() == timeType {
+		return reflect.ValueOf(zeroTime)
+	}
+	return drv
+}
+
+// Returns the bytes of a byte slice or array.
+func toBytes(rv reflect.Value) []byte {
+	var bz = make([]byte, rv.Len())
+	for i := range bz {
+		bz[i] = byte(rv.Index(i).Uint())
+	}
+	return bz
+}
+
+func formatDiffValue(o interface{}) string {
+	switch o := o.(type) {
+	case nil:
+		return "<absent>"
+	case string:
+		return fmt.Sprintf("%q", o)
+	default:
+		return fmt.Sprintf("%v", o)
+	}
+}
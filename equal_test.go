@@ -0,0 +1,111 @@
+package amino_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// eqRepr is encoded as s only, whatever its cache.
+type eqRepr struct {
+	s     string
+	cache int
+}
+
+func (r eqRepr) MarshalAmino() (string, error) { return r.s, nil }
+
+type eqStruct struct {
+	Name    string    `json:"name"`
+	Ints    []int     `json:"ints"`
+	Bytes   []byte    `json:"bytes"`
+	Time    time.Time `json:"time"`
+	Repr    eqRepr    `json:"repr"`
+	Pt      *int8     `json:"pt"`
+	Vehicle Vehicle   `json:"vehicle"`
+	Nested  []eqStruct
+	Ignored int `json:"-"`
+	private int
+}
+
+type eqOptional struct {
+	Pt *int8 `json:"pt" amino:"optional"`
+}
+
+func TestEqual(t *testing.T) {
+	var tm = time.Unix(1e9, 0)
+	var zero, one = int8(0), int8(1)
+	var epoch = time.Unix(0, 0)
+
+	cases := []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{nil, nil, true},
+		{nil, 0, false},
+		{int8(1), int16(1), false},
+		{[]int(nil), []int{}, true},
+		{[]byte(nil), []byte{}, true},
+		{[]int{1}, []int{1, 2}, false},
+		{map[string]int(nil), map[string]int{}, true},
+		{map[string]int{"a": 1}, map[string]int{"a": 2}, false},
+		{tm, tm.In(time.FixedZone("X", 3600)), true},
+		{time.Now(), time.Now().Round(0).Add(time.Second), false},
+		{eqRepr{"a", 1}, eqRepr{"a", 2}, true},
+		{eqRepr{"a", 1}, eqRepr{"b", 1}, false},
+		{eqStruct{Ignored: 1, private: 2}, eqStruct{Ints: []int{}, Nested: []eqStruct{}}, true},
+		{eqStruct{Pt: &zero}, eqStruct{}, true}, // Encoded alike.
+		{eqStruct{Pt: &one}, eqStruct{}, false},
+		{eqOptional{Pt: &zero}, eqOptional{}, false},
+		{eqOptional{Pt: &zero}, eqOptional{Pt: new(int8)}, true},
+		{struct{ T *time.Time }{}, struct{ T *time.Time }{&epoch}, true},
+		{eqStruct{Vehicle: Car("a")}, eqStruct{Vehicle: Car("a")}, true},
+		{eqStruct{Vehicle: Car("a")}, eqStruct{Vehicle: Boat("a")}, false},
+		{&eqStruct{Nested: []eqStruct{{Time: tm}}}, &eqStruct{Nested: []eqStruct{{Time: tm.UTC()}}}, true},
+	}
+	for i, tc := range cases {
+		assert.Equal(t, tc.equal, amino.Equal(tc.a, tc.b), "#%v: %#v, %#v", i, tc.a, tc.b)
+		assert.Equal(t, tc.equal, len(amino.Diff(tc.a, tc.b)) == 0, "#%v: %#v, %#v", i, tc.a, tc.b)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	var a = eqStruct{
+		Name:    "a",
+		Ints:    []int{1, 2},
+		Repr:    eqRepr{"r", 0},
+		Vehicle: Car("Tesla"),
+		Nested:  []eqStruct{{Vehicle: &Transport{Capacity: 1}}},
+	}
+	var b = eqStruct{
+		Name:    "b",
+		Ints:    []int{1, 3, 4},
+		Repr:    eqRepr{"r", 1},
+		Vehicle: Boat("Tesla"),
+		Nested:  []eqStruct{{Vehicle: &Transport{Capacity: 2}}},
+	}
+	var diffs []string
+	for _, diff := range amino.Diff(a, b) {
+		diffs = append(diffs, diff.String())
+	}
+	assert.Equal(t, []string{
+		`name: "a" -> "b"`,
+		`ints[1]: 2 -> 3`,
+		`ints[2]: <absent> -> 4`,
+		`vehicle: Tesla -> Tesla`,
+		`Nested[0].vehicle.value.Capacity: 1 -> 2`,
+	}, diffs)
+
+	diffs = nil
+	for _, diff := range amino.Diff(1, 2) {
+		diffs = append(diffs, diff.String())
+	}
+	assert.Equal(t, []string{`the root: 1 -> 2`}, diffs)
+
+	assert.Equal(t, []amino.Difference{{
+		Path: amino.Path{{Name: "ints"}, {Index: 0}},
+		Old:  1,
+	}}, amino.Diff(eqStruct{Ints: []int{1}}, eqStruct{}))
+}
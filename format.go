@@ -0,0 +1,453 @@
+package amino
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+//----------------------------------------
+// Format
+
+// A Format is an encoding that is written by the walker of EncodeFormat, so
+// that it needn't reimplement the reflective walk.  The walker dereferences
+// pointers, encodes AminoMarshalers as their repr, and resolves interfaces to
+// their registered concrete types, so a Format only sees the calls below.
+// The amino JSON and text encodings are Formats.  The binary encoding is not:
+// its byte-length prefixes of nested messages, packed lists and rolled back
+// empty fields aren't expressed by the calls below, so it keeps its own
+// walker (see encodeReflectBinary), which hashing shares.
+//
+// Values are passed with the FieldOptions of the struct field they are (or
+// are elements of), and struct fields are written unless SkipField says
+// otherwise, so a Format decides how to encode and omit fields, e.g. by
+// JSONName and SkipFieldJSON.
+//
+// Composite values are written as a Begin* call, the calls for each of their
+// elements, and the matching End* call.  Each element of a list is preceded
+// by BeginElem, each field of a struct by BeginField, and each value of a map
+// by BeginKey.  Map keys are strings, and are visited in sorted order.
+//
+// A Format may keep state, such as the current depth, so a new one should be
+// used for each value.
+type Format interface {
+	EncodeNull(w io.Writer) error // Nil pointers and interface values.
+	EncodeBool(w io.Writer, b bool, fopts FieldOptions) error
+	EncodeInt(w io.Writer, i int64, info *TypeInfo, fopts FieldOptions) error
+	EncodeUint(w io.Writer, u uint64, info *TypeInfo, fopts FieldOptions) error
+	EncodeFloat(w io.Writer, f float64, info *TypeInfo, fopts FieldOptions) error      // Requires fopts.Unsafe.
+	EncodeComplex(w io.Writer, c complex128, info *TypeInfo, fopts FieldOptions) error // Requires fopts.Unsafe.
+	EncodeString(w io.Writer, s string, fopts FieldOptions) error
+	EncodeBytes(w io.Writer, bz []byte, fopts FieldOptions) error // Non-nil byte slices and arrays.
+	EncodeTime(w io.Writer, t time.Time, fopts FieldOptions) error
+
+	// cinfo is the registered concrete type of the interface value.  A
+	// registered concrete value passed to EncodeFormat is also wrapped.
+	BeginInterface(w io.Writer, cinfo *TypeInfo, fopts FieldOptions) error
+	EndInterface(w io.Writer, cinfo *TypeInfo, fopts FieldOptions) error
+
+	// Nil slices, including byte slices, are written by EncodeNilSlice.
+	EncodeNilSlice(w io.Writer, info *TypeInfo, fopts FieldOptions) error
+	BeginList(w io.Writer, info, einfo *TypeInfo, length int, fopts FieldOptions) error
+	BeginElem(w io.Writer, index int) error
+	EndList(w io.Writer, info *TypeInfo, fopts FieldOptions) error
+
+	BeginStruct(w io.Writer, info *TypeInfo, fopts FieldOptions) error
+	// SkipField returns whether to omit field, of value rv (which may be a
+	// nil pointer), and then BeginField is not called.
+	SkipField(field *FieldInfo, rv reflect.Value) bool
+	BeginField(w io.Writer, field *FieldInfo) error
+	EndStruct(w io.Writer, info *TypeInfo, fopts FieldOptions) error
+
+	BeginMap(w io.Writer, info *TypeInfo, length int, fopts FieldOptions) error
+	BeginKey(w io.Writer, key string) error
+	EndMap(w io.Writer, info *TypeInfo, fopts FieldOptions) error
+}
+
+// An OverrideFormat is a Format that encodes some values itself.  The walker
+// calls EncodeOverride for each non-nil value but times, after dereferencing
+// pointers, and if it returns true, the value has been written.  E.g. the
+// JSON format writes json.Marshalers so.
+type OverrideFormat interface {
+	Format
+	EncodeOverride(w io.Writer, rv reflect.Value, info *TypeInfo, fopts FieldOptions) (ok bool, err error)
+}
+
+// SkipFieldJSON returns whether amino JSON omits field, of value rv, for
+// Formats that skip fields as JSON does: `amino:"-json"` fields, nil optional
+// fields, and empty `json:",omitempty"` fields.
+func SkipFieldJSON(field *FieldInfo, rv reflect.Value) bool {
+	if field.JSONSkip {
+		return true
+	}
+	var frv, _, isNil = derefPointers(rv)
+	// NOTE: Unlike Amino:binary, we don't skip null fields unless "omitempty".
+	// Optional fields are skipped iff nil, regardless of "omitempty".
+	if field.Optional {
+		return isNil
+	}
+	return field.JSONOmitEmpty && isEmpty(frv, field.ZeroValue)
+}
+
+// RegisterFormat registers the Format constructor newFormat under name, for
+// MarshalFormat.  The "json" and "text" formats (see MarshalJSON and
+// MarshalText) are always registered.
+func (cdc *Codec) RegisterFormat(name string, newFormat func() Format) {
+	cdc.assertNotSealed()
+
+	cdc.mtx.Lock()
+	defer cdc.mtx.Unlock()
+
+	if _, ok := cdc.formats[name]; ok {
+		panic(fmt.Sprintf("format %v already registered", name))
+	}
+	cdc.formats[name] = newFormat
+}
+
+// MarshalFormat encodes o in the format registered under name.
+func (cdc *Codec) MarshalFormat(name string, o interface{}) ([]byte, error) {
+	cdc.mtx.RLock()
+	newFormat, ok := cdc.formats[name]
+	cdc.mtx.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("unrecognized format %v", name)
+	}
+
+	w := new(bytes.Buffer)
+	if err := cdc.EncodeFormat(w, newFormat(), o); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+// EncodeFormat encodes o to w with the Format f.
+func (cdc *Codec) EncodeFormat(w io.Writer, f Format, o interface{}) error {
+	rv := reflect.ValueOf(o)
+	if rv.Kind() == reflect.Invalid {
+		return f.EncodeNull(w)
+	}
+	info, err := cdc.getTypeInfoWlock(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	// Wrap registered concrete types like interface values.
+	if info.Registered {
+		if err = f.BeginInterface(w, info, FieldOptions{}); err != nil {
+			return err
+		}
+	}
+	if err = cdc.encodeReflectFormat(w, f, info, rv, FieldOptions{}); err != nil {
+		return err
+	}
+	if info.Registered {
+		return f.EndInterface(w, info, FieldOptions{})
+	}
+	return nil
+}
+
+//----------------------------------------
+// cdc.encodeReflectFormat
+
+// This is the walker for all Formats.  Like encodeReflectJSON, rv may be a
+// pointer.
+// CONTRACT: rv is valid.
+func (cdc *Codec) encodeReflectFormat(w io.Writer, f Format, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if !rv.IsValid() {
+		panic("should not happen")
+	}
+	if printLog {
+		spew.Printf("(E) encodeReflectFormat(info: %v, rv: %#v (%v), fopts: %v)\n",
+			info, rv.Interface(), rv.Type(), fopts)
+		defer func() {
+			fmt.Printf("(E) -> err: %v\n", err)
+		}()
+	}
+
+	// Dereference value if pointer.
+	var isNilPtr bool
+	rv, _, isNilPtr = derefPointers(rv)
+
+	// Write null if necessary.
+	if isNilPtr {
+		return f.EncodeNull(w)
+	}
+
+	// Special case:
+	if rv.Type() == timeType {
+		return f.EncodeTime(w, rv.Interface().(time.Time), fopts)
+	}
+
+	// Handle override if the format encodes rv itself.
+	if of, ok := f.(OverrideFormat); ok {
+		if ok, err = of.EncodeOverride(w, rv, info, fopts); ok || err != nil {
+			return
+		}
+	}
+
+	// Handle override if rv implements MarshalAmino.
+	if info.IsAminoMarshaler {
+		// First, encode rv into repr instance.
+		var (
+			rrv   reflect.Value
+			rinfo *TypeInfo
+		)
+		rrv, err = toReprObject(rv)
+		if err != nil {
+			return
+		}
+		rinfo, err = cdc.getTypeInfoWlock(info.AminoMarshalReprType)
+		if err != nil {
+			return
+		}
+		// Then, encode the repr instance.
+		return cdc.encodeReflectFormat(w, f, rinfo, rrv, fopts)
+	}
+
+	switch info.Type.Kind() {
+
+	//----------------------------------------
+	// Complex
+
+	case reflect.Interface:
+		return cdc.encodeReflectFormatInterface(w, f, info, rv, fopts)
+
+	case reflect.Array, reflect.Slice:
+		return cdc.encodeReflectFormatList(w, f, info, rv, fopts)
+
+	case reflect.Struct:
+		return cdc.encodeReflectFormatStruct(w, f, info, rv, fopts)
+
+	case reflect.Map:
+		return cdc.encodeReflectFormatMap(w, f, info, rv, fopts)
+
+	//----------------------------------------
+	// Signed, Unsigned
+
+	case reflect.Int64, reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8:
+		return f.EncodeInt(w, rv.Int(), info, fopts)
+
+	case reflect.Uint64, reflect.Uint, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return f.EncodeUint(w, rv.Uint(), info, fopts)
+
+	//----------------------------------------
+	// Misc
+
+	case reflect.Float64, reflect.Float32:
+		if !fopts.Unsafe {
+			return errors.New("amino float* support requires `amino:\"unsafe\"`")
+		}
+		return f.EncodeFloat(w, rv.Float(), info, fopts)
+
+	case reflect.Bool:
+		return f.EncodeBool(w, rv.Bool(), fopts)
+
+	case reflect.String:
+		return f.EncodeString(w, rv.String(), fopts)
+
+	case reflect.Complex128, reflect.Complex64:
+		if !fopts.Unsafe {
+			return errors.New("amino complex* support requires `amino:\"unsafe\"`")
+		}
+		return f.EncodeComplex(w, rv.Complex(), info, fopts)
+
+	//----------------------------------------
+	// Default
+
+	default:
+		panic(fmt.Sprintf("unsupported type %v", info.Type.Kind()))
+	}
+}
+
+func (cdc *Codec) encodeReflectFormatInterface(w io.Writer, f Format, iinfo *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(e) encodeReflectFormatInterface")
+		defer func() {
+			fmt.Printf("(e) -> err: %v\n", err)
+		}()
+	}
+
+	// Special case when rv is nil, just write null.
+	if rv.IsNil() {
+		return f.EncodeNull(w)
+	}
+
+	// Get concrete non-pointer reflect value & type.
+	var crv, isPtr, isNilPtr = derefPointers(rv.Elem())
+	if isPtr && crv.Kind() == reflect.Interface {
+		// See "MARKER: No interface-pointers" in codec.go
+		panic("should not happen")
+	}
+	if isNilPtr {
+		panic(fmt.Sprintf("Illegal nil-pointer of type %v for registered interface %v. "+
+			"For compatibility with other languages, nil-pointer interface values are forbidden.", crv.Type(), iinfo.Type))
+	}
+	var crt = crv.Type()
+
+	// Get *TypeInfo for concrete type.
+	var cinfo *TypeInfo
+	cinfo, err = cdc.getTypeInfoWlock(crt)
+	if err != nil {
+		return
+	}
+	if !cinfo.Registered {
+		err = errors.Errorf("cannot encode unregistered concrete type %v", crt)
+		return
+	}
+
+	// Wrap the concrete value.
+	if err = f.BeginInterface(w, cinfo, fopts); err != nil {
+		return
+	}
+	if err = cdc.encodeReflectFormat(w, f, cinfo, crv, fopts); err != nil {
+		return
+	}
+	return f.EndInterface(w, cinfo, fopts)
+}
+
+func (cdc *Codec) encodeReflectFormatList(w io.Writer, f Format, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(e) encodeReflectFormatList")
+		defer func() {
+			fmt.Printf("(e) -> err: %v\n", err)
+		}()
+	}
+
+	// Special case: nil slice.  Empty slices and arrays are not nil.
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return f.EncodeNilSlice(w, info, fopts)
+	}
+
+	ert := info.Type.Elem()
+	length := rv.Len()
+
+	// Special case: byte array or slice.
+	if ert.Kind() == reflect.Uint8 {
+		var bz []byte
+		if rv.CanAddr() || rv.Kind() == reflect.Slice {
+			bz = rv.Slice(0, length).Bytes()
+		} else {
+			bz = make([]byte, length)
+			reflect.Copy(reflect.ValueOf(bz), rv) // XXX: looks expensive!
+		}
+		return f.EncodeBytes(w, bz, fopts)
+	}
+
+	var einfo *TypeInfo
+	einfo, err = cdc.getTypeInfoWlock(ert)
+	if err != nil {
+		return
+	}
+	if err = f.BeginList(w, info, einfo, length, fopts); err != nil {
+		return
+	}
+	for i := 0; i < length; i++ {
+		if err = f.BeginElem(w, i); err != nil {
+			return
+		}
+		// Get dereferenced element value.
+		var erv, _, isNil = derefPointers(rv.Index(i))
+		if isNil {
+			err = f.EncodeNull(w)
+		} else {
+			err = cdc.encodeReflectFormat(w, f, einfo, erv, fopts)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return f.EndList(w, info, fopts)
+}
+
+func (cdc *Codec) encodeReflectFormatStruct(w io.Writer, f Format, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(e) encodeReflectFormatStruct")
+		defer func() {
+			fmt.Printf("(e) -> err: %v\n", err)
+		}()
+	}
+
+	if err = f.BeginStruct(w, info, fopts); err != nil {
+		return
+	}
+	for i := range info.Fields {
+		field := &info.Fields[i]
+		// Get dereferenced field value and info.
+		var frv, _, isNil = derefPointers(rv.Field(field.Index))
+		var finfo *TypeInfo
+		finfo, err = cdc.getTypeInfoWlock(field.Type)
+		if err != nil {
+			return
+		}
+		if f.SkipField(field, rv.Field(field.Index)) {
+			continue
+		}
+		if err = f.BeginField(w, field); err != nil {
+			return
+		}
+		if isNil {
+			err = f.EncodeNull(w)
+		} else {
+			err = cdc.encodeReflectFormat(w, f, finfo, frv, field.FieldOptions)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return f.EndStruct(w, info, fopts)
+}
+
+func (cdc *Codec) encodeReflectFormatMap(w io.Writer, f Format, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(e) encodeReflectFormatMap")
+		defer func() {
+			fmt.Printf("(e) -> err: %v\n", err)
+		}()
+	}
+
+	// Ensure that the map key type is a string.
+	if rv.Type().Key().Kind() != reflect.String {
+		return errors.New("encodeReflectFormatMap: map key type must be a string")
+	}
+
+	// NOTE: Keys are sorted so that the output is deterministic.
+	var keys = rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	if err = f.BeginMap(w, info, len(keys), fopts); err != nil {
+		return
+	}
+	for _, krv := range keys {
+		// Get dereferenced object value and info.
+		var vrv, _, isNil = derefPointers(rv.MapIndex(krv))
+
+		if err = f.BeginKey(w, krv.String()); err != nil {
+			return
+		}
+		if isNil {
+			err = f.EncodeNull(w)
+		} else {
+			var vinfo *TypeInfo
+			vinfo, err = cdc.getTypeInfoWlock(vrv.Type())
+			if err != nil {
+				return
+			}
+			err = cdc.encodeReflectFormat(w, f, vinfo, vrv, fopts) // pass through fopts
+		}
+		if err != nil {
+			return
+		}
+	}
+	return f.EndMap(w, info, fopts)
+}
@@ -0,0 +1,163 @@
+package amino_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// compactFormat writes values on one line, with types, e.g.
+// (our/transport {Vehicle:(car "Tesla") Capacity:int:4}).
+type compactFormat struct {
+	sep []bool // Whether to write a separator before the next element.
+}
+
+func (cf *compactFormat) printf(w io.Writer, format string, args ...interface{}) error {
+	_, err := fmt.Fprintf(w, format, args...)
+	return err
+}
+
+func (cf *compactFormat) begin(w io.Writer, s string) error {
+	cf.sep = append(cf.sep, false)
+	return cf.printf(w, s)
+}
+
+func (cf *compactFormat) next(w io.Writer, s string) error {
+	if cf.sep[len(cf.sep)-1] {
+		s = " " + s
+	}
+	cf.sep[len(cf.sep)-1] = true
+	return cf.printf(w, s)
+}
+
+func (cf *compactFormat) end(w io.Writer, s string) error {
+	cf.sep = cf.sep[:len(cf.sep)-1]
+	return cf.printf(w, s)
+}
+
+func (cf *compactFormat) EncodeNull(w io.Writer) error { return cf.printf(w, "nil") }
+func (cf *compactFormat) EncodeBool(w io.Writer, b bool, fopts amino.FieldOptions) error {
+	return cf.printf(w, "%v", b)
+}
+func (cf *compactFormat) EncodeString(w io.Writer, s string, fopts amino.FieldOptions) error {
+	return cf.printf(w, "%q", s)
+}
+func (cf *compactFormat) EncodeBytes(w io.Writer, bz []byte, fopts amino.FieldOptions) error {
+	return cf.printf(w, "%X", bz)
+}
+func (cf *compactFormat) EncodeTime(w io.Writer, t time.Time, fopts amino.FieldOptions) error {
+	return cf.printf(w, "%v", t.Unix())
+}
+func (cf *compactFormat) EncodeInt(w io.Writer, i int64, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	if fopts.BinFixed64 {
+		return cf.printf(w, "fixed64:%v", i)
+	}
+	return cf.printf(w, "%v:%v", info.Type, i)
+}
+func (cf *compactFormat) EncodeUint(w io.Writer, u uint64, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.printf(w, "%v:%v", info.Type, u)
+}
+func (cf *compactFormat) EncodeFloat(w io.Writer, f float64, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.printf(w, "%v:%v", info.Type, f)
+}
+func (cf *compactFormat) EncodeComplex(w io.Writer, c complex128, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.printf(w, "%v:%v", info.Type, c)
+}
+func (cf *compactFormat) BeginInterface(w io.Writer, cinfo *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.printf(w, "(%v ", cinfo.Name)
+}
+func (cf *compactFormat) EndInterface(w io.Writer, cinfo *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.printf(w, ")")
+}
+func (cf *compactFormat) EncodeNilSlice(w io.Writer, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.printf(w, "nil")
+}
+func (cf *compactFormat) BeginList(w io.Writer, info, einfo *amino.TypeInfo, length int, fopts amino.FieldOptions) error {
+	return cf.begin(w, "[")
+}
+func (cf *compactFormat) BeginElem(w io.Writer, index int) error { return cf.next(w, "") }
+func (cf *compactFormat) EndList(w io.Writer, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.end(w, "]")
+}
+func (cf *compactFormat) BeginStruct(w io.Writer, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.begin(w, "{")
+}
+func (cf *compactFormat) SkipField(field *amino.FieldInfo, rv reflect.Value) bool {
+	return field.BinSkip // Skips fields as binary does.
+}
+func (cf *compactFormat) BeginField(w io.Writer, field *amino.FieldInfo) error {
+	return cf.next(w, fmt.Sprintf("%v.%v:", field.BinFieldNum, field.Name))
+}
+func (cf *compactFormat) EndStruct(w io.Writer, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.end(w, "}")
+}
+func (cf *compactFormat) BeginMap(w io.Writer, info *amino.TypeInfo, length int, fopts amino.FieldOptions) error {
+	return cf.begin(w, "map[")
+}
+func (cf *compactFormat) BeginKey(w io.Writer, key string) error { return cf.next(w, key+":") }
+func (cf *compactFormat) EndMap(w io.Writer, info *amino.TypeInfo, fopts amino.FieldOptions) error {
+	return cf.end(w, "]")
+}
+
+func TestMarshalFormat(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+	cdc.RegisterFormat("compact", func() amino.Format { return &compactFormat{} })
+
+	cases := []struct {
+		o    interface{}
+		want string
+	}{
+		{nil, "nil"},
+		{int8(-3), "int8:-3"},
+		{[]byte{1, 2}, "0102"},
+		{[2]uint16{1, 2}, "[uint16:1 uint16:2]"},
+		{map[string]*int{"b": nil, "a": new(int)}, "map[a:int:0 b:nil]"},
+		{time.Unix(1000, 0), "1000"},
+		{
+			Transport{Vehicle: Car("Tesla"), Capacity: 4},
+			`(our/transport {1.Vehicle:(car "Tesla") 2.Capacity:int:4})`,
+		},
+		{
+			BalanceSheet{Assets: []Asset{Car("Tesla"), insurancePlan(2), nil}},
+			`{1.Assets:[(car "Tesla") (insuranceplan amino_test.insurancePlan:2) nil]}`,
+		},
+		{
+			// The format is given the field options, and skips fields.
+			struct {
+				A int64  `binary:"fixed64"`
+				B string `amino:"-binary"`
+				C []int8
+			}{A: 1, B: "b"},
+			`{1.A:fixed64:1 3.C:nil}`,
+		},
+	}
+	for _, tc := range cases {
+		bz, err := cdc.MarshalFormat("compact", tc.o)
+		require.NoError(t, err, "%#v", tc.o)
+		assert.Equal(t, tc.want, string(bz), "%#v", tc.o)
+	}
+
+	// The json and text formats are built in.
+	bz, err := cdc.MarshalFormat("json", Transport{Vehicle: Car("Tesla"), Capacity: 4})
+	require.NoError(t, err)
+	assert.Equal(t, cdc.MustMarshalJSON(Transport{Vehicle: Car("Tesla"), Capacity: 4}), bz)
+	bz, err = cdc.MarshalFormat("text", Transport{Vehicle: Car("Tesla"), Capacity: 4})
+	require.NoError(t, err)
+	assert.Equal(t, "@our/transport {\n  Vehicle: @car \"Tesla\"\n  Capacity: 4\n}", string(bz))
+
+	_, err = cdc.MarshalFormat("cbor", 1)
+	assert.EqualError(t, err, "unrecognized format cbor")
+	_, err = cdc.MarshalFormat("compact", 1.5)
+	assert.EqualError(t, err, "amino float* support requires `amino:\"unsafe\"`")
+	assert.Panics(t, func() {
+		cdc.RegisterFormat("text", func() amino.Format { return &compactFormat{} })
+	})
+}
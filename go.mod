@@ -8,4 +8,5 @@ require (
 	github.com/google/gofuzz v0.0.0-20170612174753-24818f796faf
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
 )
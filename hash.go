@@ -0,0 +1,176 @@
+package amino
+
+import (
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"reflect"
+)
+
+//----------------------------------------
+// Hash
+
+// Hash writes the binary encoding of o, as by MarshalBinaryBare, to h.  The
+// encoding is streamed: unlike MarshalBinaryBare, neither it nor any nested
+// message is buffered, at the cost of walking o twice, first to measure the
+// byte-length of each nested message.
+//
+// The binary encoding is deterministic, so equal values (by the amino rules,
+// e.g. nil and empty slices are equal) always give equal hashes, as long as
+// any MarshalAmino methods are deterministic too.
+func (cdc *Codec) Hash(o interface{}, h hash.Hash) error {
+	// Dereference value if pointer.
+	var rv, _, isNilPtr = derefPointers(reflect.ValueOf(o))
+	if isNilPtr {
+		return errors.New("Hash cannot hash a nil pointer directly. Try wrapping in a struct?")
+	}
+	info, err := cdc.getTypeInfoWlock(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	// First measure, then write.
+	s := &binaryStream{lengths: []int{0}}
+	if err = cdc.encodeBinaryBare(s, info, rv); err != nil {
+		return err
+	}
+	s.w, s.pos = h, 0
+	return cdc.encodeBinaryBare(s, info, rv)
+}
+
+// Sum256 returns the SHA256 hash of the binary encoding of o, i.e. of
+// MarshalBinaryBare(o), streamed as by Hash.
+func (cdc *Codec) Sum256(o interface{}) (sum [sha256.Size]byte, err error) {
+	h := sha256.New()
+	if err = cdc.Hash(o, h); err != nil {
+		return
+	}
+	copy(sum[:], h.Sum(nil))
+	return
+}
+
+// MustSum256 panics if an error occurs. Besides that behaves exactly like Sum256.
+func (cdc *Codec) MustSum256(o interface{}) [sha256.Size]byte {
+	sum, err := cdc.Sum256(o)
+	if err != nil {
+		panic(err)
+	}
+	return sum
+}
+
+//----------------------------------------
+// binaryStream
+
+// binaryStream is written to by the binary encoder in place of the buffers
+// of nested messages (see beginMessage).  The encoder runs twice: while w is
+// nil, the stream measures the byte-length of each message and whether each
+// field is empty and so skipped (see writeFieldIfNotEmpty), recording them
+// in entries; then it writes to w, reading the entries back in order.
+type binaryStream struct {
+	w       io.Writer // nil while measuring.
+	entries []binaryStreamEntry
+	pos     int // of the next entry, while writing.
+
+	// While measuring:
+	lengths []int // of the root, then each open message.
+	opened  []int // the entries of the open messages.
+	last    byte  // the last byte written.
+}
+
+type binaryStreamEntry struct {
+	length int  // of a message.
+	skip   bool // whether a field is skipped,
+	next   int  // in which case, the entry after its value's.
+}
+
+func (s *binaryStream) Write(bz []byte) (int, error) {
+	if s.w != nil {
+		return s.w.Write(bz)
+	}
+	s.lengths[len(s.lengths)-1] += len(bz)
+	if len(bz) > 0 {
+		s.last = bz[len(bz)-1]
+	}
+	return len(bz), nil
+}
+
+// Rather than buffer the message, writes it to s itself.
+func (s *binaryStream) beginMessage(bare bool) (messageWriter, error) {
+	if s.w != nil {
+		entry := s.entries[s.pos]
+		s.pos++
+		if bare {
+			return s, nil
+		}
+		return s, EncodeUvarint(s.w, uint64(entry.length))
+	}
+	s.opened = append(s.opened, len(s.entries))
+	s.entries = append(s.entries, binaryStreamEntry{})
+	s.lengths = append(s.lengths, 0)
+	return s, nil
+}
+
+// The message is already written to s, which is w.
+func (s *binaryStream) endMessage(w io.Writer, bare bool) error {
+	if s.w != nil {
+		return nil
+	}
+	length := s.lengths[len(s.lengths)-1]
+	s.lengths = s.lengths[:len(s.lengths)-1]
+	s.entries[s.opened[len(s.opened)-1]].length = length
+	s.opened = s.opened[:len(s.opened)-1]
+	if !bare {
+		// Count the byte-length prefix, which comes first.
+		s.lengths[len(s.lengths)-1] += UvarintSize(uint64(length))
+		if length == 0 {
+			s.last = 0x00
+		}
+	}
+	s.lengths[len(s.lengths)-1] += length
+	return nil
+}
+
+// Like messageBuffer's, but rather than roll back an empty field after
+// writing it, skips it while writing.
+func (s *binaryStream) writeFieldIfNotEmpty(cdc *Codec, fieldNum uint32, finfo *TypeInfo,
+	fieldOpts FieldOptions, derefedVal reflect.Value, isWriteEmpty bool, bare bool) error {
+	var index, lBeforeKey int
+	var lastBeforeKey byte
+	if s.w != nil {
+		entry := s.entries[s.pos]
+		s.pos++
+		if entry.skip {
+			s.pos = entry.next
+			return nil
+		}
+	} else {
+		index = len(s.entries)
+		s.entries = append(s.entries, binaryStreamEntry{})
+		lBeforeKey, lastBeforeKey = s.lengths[len(s.lengths)-1], s.last
+	}
+
+	// Write field key (number and type).
+	err := encodeFieldNumberAndTyp3(s, fieldNum, typeToTyp3(finfo.Type, fieldOpts))
+	if err != nil {
+		return err
+	}
+	lBeforeValue := s.lengths[len(s.lengths)-1]
+
+	// Write field value from rv.
+	err = cdc.encodeReflectBinary(s, finfo, derefedVal, fieldOpts, bare)
+	if err != nil {
+		return err
+	}
+
+	if s.w == nil {
+		lAfterValue := s.lengths[len(s.lengths)-1]
+		if !isWriteEmpty && lBeforeValue == lAfterValue-1 && s.last == 0x00 {
+			// Uncount the field, and skip it while writing.
+			s.lengths[len(s.lengths)-1], s.last = lBeforeKey, lastBeforeKey
+			s.entries[index].skip = true
+			s.entries[index].next = len(s.entries)
+		}
+	}
+	return nil
+}
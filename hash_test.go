@@ -0,0 +1,122 @@
+package amino_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/tests"
+)
+
+// A hash.Hash that records what is written to it.
+type recordingHash struct {
+	bytes.Buffer
+}
+
+func (h *recordingHash) Sum(b []byte) []byte { return append(b, h.Bytes()...) }
+func (h *recordingHash) Size() int           { return h.Len() }
+func (h *recordingHash) BlockSize() int      { return 1 }
+
+func TestHash(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+	for _, ptr := range tests.StructTypes {
+		cdc.RegisterConcrete(ptr, "tests/"+reflect.TypeOf(ptr).Elem().Name(), nil)
+	}
+	cdc.RegisterConcrete(tests.IntSl{}, "tests/IntSl", nil)
+
+	type nested struct {
+		Empty   tests.EmptyStruct
+		EmptyPt *tests.EmptyStruct
+		Lists   [][]int8
+		Structs []tests.EmptyStruct
+		Complex complex64 `amino:"unsafe"`
+		Time    time.Time
+		Vehicle Vehicle
+		Nested  *nested
+	}
+	cases := []interface{}{
+		int8(0),
+		int64(-1),
+		"",
+		[]byte{},
+		[]int{0, 1},
+		tests.IntSl{0, 1}, // Registered.
+		tests.EmptyStruct{},
+		Car("Tesla"),
+		&Transport{Vehicle: Boat("Poseidon"), Capacity: 1234},
+		nested{},
+		nested{
+			EmptyPt: &tests.EmptyStruct{},
+			Lists:   [][]int8{nil, {}, {0}, {1, 2}},
+			Structs: []tests.EmptyStruct{{}, {}},
+			Time:    time.Unix(0, 1),
+			Vehicle: Plane{},
+			Nested:  &nested{Nested: &nested{Complex: 1i}},
+		},
+	}
+
+	// And fuzzed values of every struct type.
+	f := fuzz.New().RandSource(rand.New(rand.NewSource(10))).NilChance(0.3).NumElements(0, 3).Funcs(
+		func(t *time.Time, c fuzz.Continue) {
+			*t = time.Unix(c.Int63n(1<<33), c.Int63n(1e9)).UTC()
+		},
+	)
+	for _, ptr := range tests.StructTypes {
+		for i := 0; i < 20; i++ {
+			rv := reflect.New(reflect.TypeOf(ptr).Elem())
+			f.Fuzz(rv.Interface())
+			cases = append(cases, rv.Interface())
+		}
+	}
+
+	for _, o := range cases {
+		bz, err := cdc.MarshalBinaryBare(o)
+		require.NoError(t, err)
+
+		h := new(recordingHash)
+		require.NoError(t, cdc.Hash(o, h), "%#v", o)
+		assert.True(t, bytes.Equal(bz, h.Bytes()), "%#v:\nencoded:  %X\nstreamed: %X", o, bz, h.Bytes())
+
+		sum, err := cdc.Sum256(o)
+		require.NoError(t, err)
+		assert.Equal(t, sha256.Sum256(bz), sum, "%#v", o)
+	}
+
+	// Equal values give equal hashes.
+	assert.Equal(t, cdc.MustSum256([]int(nil)), cdc.MustSum256([]int{}))
+	assert.Equal(t, cdc.MustSum256(nested{}), cdc.MustSum256(&nested{Lists: [][]int8{}}))
+	assert.NotEqual(t, cdc.MustSum256(int8(0)), cdc.MustSum256(int8(1)))
+
+	err := cdc.Hash((*nested)(nil), sha256.New())
+	assert.EqualError(t, err, "Hash cannot hash a nil pointer directly. Try wrapping in a struct?")
+}
+
+func BenchmarkSum256(b *testing.B) {
+	var cdc = amino.NewCodec()
+	var o = tests.SlicesStruct{
+		Int64Sl:  make([]int64, 1000),
+		StringSl: make([]string, 1000),
+		BytesSl:  make([][]byte, 1000),
+	}
+	b.Run("Sum256", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cdc.MustSum256(o)
+		}
+	})
+	b.Run("MarshalBinaryBare", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sha256.Sum256(cdc.MustMarshalBinaryBare(o))
+		}
+	})
+}
@@ -14,8 +14,11 @@ import (
 //----------------------------------------
 // cdc.decodeReflectJSON
 
+// If merge is true, the decoded value is merged into rv with proto merge
+// semantics (see UnmarshalJSONMerge).
 // CONTRACT: rv.CanAddr() is true.
-func (cdc *Codec) decodeReflectJSON(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
+func (cdc *Codec) decodeReflectJSON(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions,
+	merge bool) (err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -23,8 +26,8 @@ func (cdc *Codec) decodeReflectJSON(bz []byte, info *TypeInfo, rv reflect.Value,
 		panic("should not happen")
 	}
 	if printLog {
-		spew.Printf("(D) decodeReflectJSON(bz: %s, info: %v, rv: %#v (%v), fopts: %v)\n",
-			bz, info, rv.Interface(), rv.Type(), fopts)
+		spew.Printf("(D) decodeReflectJSON(bz: %s, info: %v, rv: %#v (%v), fopts: %v, merge: %v)\n",
+			bz, info, rv.Interface(), rv.Type(), fopts, merge)
 		defer func() {
 			fmt.Printf("(D) -> err: %v\n", err)
 		}()
@@ -70,13 +73,17 @@ func (cdc *Codec) decodeReflectJSON(bz []byte, info *TypeInfo, rv reflect.Value,
 	// Handle override if a pointer to rv implements UnmarshalAmino.
 	if info.IsAminoUnmarshaler {
 		// First, decode repr instance from bytes.
-		rrv := reflect.New(info.AminoUnmarshalReprType).Elem()
+		var rrv reflect.Value
+		rrv, err = newReprObjectMerge(info, rv, merge)
+		if err != nil {
+			return
+		}
 		var rinfo *TypeInfo
 		rinfo, err = cdc.getTypeInfoWlock(info.AminoUnmarshalReprType)
 		if err != nil {
 			return
 		}
-		err = cdc.decodeReflectJSON(bz, rinfo, rrv, fopts)
+		err = cdc.decodeReflectJSON(bz, rinfo, rrv, fopts, merge)
 		if err != nil {
 			return
 		}
@@ -96,19 +103,19 @@ func (cdc *Codec) decodeReflectJSON(bz []byte, info *TypeInfo, rv reflect.Value,
 	// Complex
 
 	case reflect.Interface:
-		err = cdc.decodeReflectJSONInterface(bz, info, rv, fopts)
+		err = cdc.decodeReflectJSONInterface(bz, info, rv, fopts, merge)
 
 	case reflect.Array:
 		err = cdc.decodeReflectJSONArray(bz, info, rv, fopts)
 
 	case reflect.Slice:
-		err = cdc.decodeReflectJSONSlice(bz, info, rv, fopts)
+		err = cdc.decodeReflectJSONSlice(bz, info, rv, fopts, merge)
 
 	case reflect.Struct:
-		err = cdc.decodeReflectJSONStruct(bz, info, rv, fopts)
+		err = cdc.decodeReflectJSONStruct(bz, info, rv, fopts, merge)
 
 	case reflect.Map:
-		err = cdc.decodeReflectJSONMap(bz, info, rv, fopts)
+		err = cdc.decodeReflectJSONMap(bz, info, rv, fopts, merge)
 
 	//----------------------------------------
 	// Signed, Unsigned
@@ -142,6 +149,30 @@ func (cdc *Codec) decodeReflectJSON(bz []byte, info *TypeInfo, rv reflect.Value,
 	case reflect.Bool, reflect.String:
 		err = invokeStdlibJSONUnmarshal(bz, rv, fopts)
 
+	case reflect.Complex128, reflect.Complex64:
+		if !fopts.Unsafe {
+			return errors.New("amino:JSON complex* support requires `amino:\"unsafe\"`")
+		}
+		// Expect a two element array of the real and imaginary parts.
+		var parts []float64
+		if info.Type.Kind() == reflect.Complex64 {
+			var parts32 []float32
+			if err = json.Unmarshal(bz, &parts32); err != nil {
+				return
+			}
+			for _, p := range parts32 {
+				parts = append(parts, float64(p))
+			}
+		} else {
+			if err = json.Unmarshal(bz, &parts); err != nil {
+				return
+			}
+		}
+		if len(parts) != 2 {
+			return errors.Errorf("amino:JSON complex expects [real, imag], got %v", string(bz))
+		}
+		rv.SetComplex(complex(parts[0], parts[1]))
+
 	//----------------------------------------
 	// Default
 
@@ -171,7 +202,7 @@ func invokeStdlibJSONUnmarshal(bz []byte, rv reflect.Value, fopts FieldOptions)
 
 // CONTRACT: rv.CanAddr() is true.
 func (cdc *Codec) decodeReflectJSONInterface(bz []byte, iinfo *TypeInfo, rv reflect.Value,
-	fopts FieldOptions) (err error) {
+	fopts FieldOptions, merge bool) (err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -189,7 +220,12 @@ func (cdc *Codec) decodeReflectJSONInterface(bz []byte, iinfo *TypeInfo, rv refl
 		* What if the type is mismatched?
 		* What if the JSON field entry is missing?
 		* Circular references?
+
+		The exception is merging into a value of the same concrete type,
+		see constructConcreteTypeMerge.
 	*/
+	var orv = reflect.New(rv.Type()).Elem()
+	orv.Set(rv)
 	if !rv.IsNil() {
 		// We don't strictly need to set it nil, but lets keep it here for a
 		// while in case we forget, for defensive purposes.
@@ -216,10 +252,10 @@ func (cdc *Codec) decodeReflectJSONInterface(bz []byte, iinfo *TypeInfo, rv refl
 	}
 
 	// Construct the concrete type.
-	var crv, irvSet = constructConcreteType(cinfo)
+	var crv, irvSet = constructConcreteTypeMerge(cinfo, orv, merge)
 
 	// Decode into the concrete type.
-	err = cdc.decodeReflectJSON(bz, cinfo, crv, fopts)
+	err = cdc.decodeReflectJSON(bz, cinfo, crv, fopts, merge)
 	if err != nil {
 		rv.Set(irvSet) // Helps with debugging
 		return
@@ -282,7 +318,7 @@ func (cdc *Codec) decodeReflectJSONArray(bz []byte, info *TypeInfo, rv reflect.V
 		for i := 0; i < length; i++ {
 			erv := rv.Index(i)
 			ebz := rawSlice[i]
-			err = cdc.decodeReflectJSON(ebz, einfo, erv, fopts)
+			err = cdc.decodeReflectJSON(ebz, einfo, erv, fopts, false)
 			if err != nil {
 				return
 			}
@@ -292,7 +328,8 @@ func (cdc *Codec) decodeReflectJSONArray(bz []byte, info *TypeInfo, rv reflect.V
 }
 
 // CONTRACT: rv.CanAddr() is true.
-func (cdc *Codec) decodeReflectJSONSlice(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
+func (cdc *Codec) decodeReflectJSONSlice(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions,
+	merge bool) (err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -339,7 +376,9 @@ func (cdc *Codec) decodeReflectJSONSlice(bz []byte, info *TypeInfo, rv reflect.V
 		// NOTE: We prefer nil slices.
 		var length = len(rawSlice)
 		if length == 0 {
-			rv.Set(info.ZeroValue)
+			if !merge {
+				rv.Set(info.ZeroValue)
+			}
 			return
 		}
 
@@ -349,11 +388,15 @@ func (cdc *Codec) decodeReflectJSONSlice(bz []byte, info *TypeInfo, rv reflect.V
 		for i := 0; i < length; i++ {
 			erv := srv.Index(i)
 			ebz := rawSlice[i]
-			err = cdc.decodeReflectJSON(ebz, einfo, erv, fopts)
+			err = cdc.decodeReflectJSON(ebz, einfo, erv, fopts, false)
 			if err != nil {
 				return
 			}
 		}
+		if merge {
+			// Append decoded items to the existing ones.
+			srv = reflect.AppendSlice(rv.Convert(esrt), srv)
+		}
 
 		// TODO do we need this extra step?
 		rv.Set(srv)
@@ -362,7 +405,8 @@ func (cdc *Codec) decodeReflectJSONSlice(bz []byte, info *TypeInfo, rv reflect.V
 }
 
 // CONTRACT: rv.CanAddr() is true.
-func (cdc *Codec) decodeReflectJSONStruct(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
+func (cdc *Codec) decodeReflectJSONStruct(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions,
+	merge bool) (err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -383,6 +427,9 @@ func (cdc *Codec) decodeReflectJSONStruct(bz []byte, info *TypeInfo, rv reflect.
 	}
 
 	for _, field := range info.Fields {
+		if field.JSONSkip {
+			continue // e.g. amino:"-json"
+		}
 
 		// Get field rv and info.
 		var frv = rv.Field(field.Index)
@@ -393,7 +440,14 @@ func (cdc *Codec) decodeReflectJSONStruct(bz []byte, info *TypeInfo, rv reflect.
 		}
 
 		// Get value from rawMap.
+		// Fall back to any legacy names, e.g. amino:"json_alias=oldName".
 		var valueBytes = rawMap[field.JSONName]
+		for _, alias := range field.JSONAliases {
+			if len(valueBytes) != 0 {
+				break
+			}
+			valueBytes = rawMap[alias]
+		}
 		if len(valueBytes) == 0 {
 			// TODO: Since the Go stdlib's JSON codec allows case-insensitive
 			// keys perhaps we need to also do case-insensitive lookups here.
@@ -404,7 +458,9 @@ func (cdc *Codec) decodeReflectJSONStruct(bz []byte, info *TypeInfo, rv reflect.
 			// JAE: I vote we depart from encoding/json, than carry a vuln.
 
 			// Set to the zero value only if not omitempty
-			if !field.JSONOmitEmpty {
+			// (optional fields are always reset to nil),
+			// and leave it untouched when merging.
+			if !merge && (!field.JSONOmitEmpty || field.Optional) {
 				// Set nil/zero on frv.
 				frv.Set(reflect.Zero(frv.Type()))
 			}
@@ -413,7 +469,7 @@ func (cdc *Codec) decodeReflectJSONStruct(bz []byte, info *TypeInfo, rv reflect.
 		}
 
 		// Decode into field rv.
-		err = cdc.decodeReflectJSON(valueBytes, finfo, frv, fopts)
+		err = cdc.decodeReflectJSON(valueBytes, finfo, frv, field.FieldOptions, merge)
 		if err != nil {
 			return
 		}
@@ -423,7 +479,8 @@ func (cdc *Codec) decodeReflectJSONStruct(bz []byte, info *TypeInfo, rv reflect.
 }
 
 // CONTRACT: rv.CanAddr() is true.
-func (cdc *Codec) decodeReflectJSONMap(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
+func (cdc *Codec) decodeReflectJSONMap(bz []byte, info *TypeInfo, rv reflect.Value, fopts FieldOptions,
+	merge bool) (err error) {
 	if !rv.CanAddr() {
 		panic("rv not addressable")
 	}
@@ -455,13 +512,20 @@ func (cdc *Codec) decodeReflectJSONMap(bz []byte, info *TypeInfo, rv reflect.Val
 	}
 
 	var mrv = reflect.MakeMapWithSize(rv.Type(), len(rawMap))
+	if merge && !rv.IsNil() {
+		// Keep the existing entries, overwriting those present in bz.
+		mrv = reflect.MakeMapWithSize(rv.Type(), rv.Len()+len(rawMap))
+		for _, key := range rv.MapKeys() {
+			mrv.SetMapIndex(key, rv.MapIndex(key))
+		}
+	}
 	for key, valueBytes := range rawMap {
 
 		// Get map value rv.
 		vrv := reflect.New(mrv.Type().Elem()).Elem()
 
 		// Decode valueBytes into vrv.
-		err = cdc.decodeReflectJSON(valueBytes, vinfo, vrv, fopts)
+		err = cdc.decodeReflectJSON(valueBytes, vinfo, vrv, fopts, false)
 		if err != nil {
 			return
 		}
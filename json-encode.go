@@ -5,408 +5,191 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
 	"time"
-
-	"github.com/pkg/errors"
-
-	"github.com/davecgh/go-spew/spew"
 )
 
 //----------------------------------------
 // cdc.encodeReflectJSON
 
-// This is the main entrypoint for encoding all types in json form.  This
-// function calls encodeReflectJSON*, and generally those functions should
-// only call this one, for the disfix wrapper is only written here.
+// This is the main entrypoint for encoding all types in json form, by the
+// walker of Formats with a jsonFormat.  The disfix wrapper of rv itself, if
+// registered, is written by the caller.
 // NOTE: Unlike encodeReflectBinary, rv may be a pointer.
 // CONTRACT: rv is valid.
-func (cdc *Codec) encodeReflectJSON(w io.Writer, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
-	if !rv.IsValid() {
-		panic("should not happen")
-	}
-	if printLog {
-		spew.Printf("(E) encodeReflectJSON(info: %v, rv: %#v (%v), fopts: %v)\n",
-			info, rv.Interface(), rv.Type(), fopts)
-		defer func() {
-			fmt.Printf("(E) -> err: %v\n", err)
-		}()
-	}
+func (cdc *Codec) encodeReflectJSON(w io.Writer, info *TypeInfo, rv reflect.Value, fopts FieldOptions) error {
+	return cdc.encodeReflectFormat(w, newJSONFormat(), info, rv, fopts)
+}
 
-	// Dereference value if pointer.
-	var isNilPtr bool
-	rv, _, isNilPtr = derefPointers(rv)
+//----------------------------------------
+// jsonFormat
 
-	// Write null if necessary.
-	if isNilPtr {
-		err = writeStr(w, `null`)
-		return
-	}
+// jsonFormat is the Format of MarshalJSON.
+type jsonFormat struct {
+	commas []bool // Whether to write a comma before the next field or key, per open struct or map.
+}
 
-	// Special case:
-	if rv.Type() == timeType {
-		// Amino time strips the timezone.
-		// NOTE: This must be done before json.Marshaler override below.
-		ct := rv.Interface().(time.Time).Round(0).UTC()
-		rv = reflect.ValueOf(ct)
-	}
-	// Handle override if rv implements json.Marshaler.
+var _ OverrideFormat = (*jsonFormat)(nil)
+
+func newJSONFormat() Format {
+	return &jsonFormat{}
+}
+
+func (jf *jsonFormat) EncodeNull(w io.Writer) error {
+	return writeStr(w, `null`)
+}
+
+// Handle override if rv implements json.Marshaler.
+func (jf *jsonFormat) EncodeOverride(w io.Writer, rv reflect.Value, _ *TypeInfo, _ FieldOptions) (bool, error) {
 	if rv.CanAddr() { // Try pointer first.
 		if rv.Addr().Type().Implements(jsonMarshalerType) {
-			err = invokeMarshalJSON(w, rv.Addr())
-			return
+			return true, invokeMarshalJSON(w, rv.Addr())
 		}
 	} else if rv.Type().Implements(jsonMarshalerType) {
-		err = invokeMarshalJSON(w, rv)
-		return
+		return true, invokeMarshalJSON(w, rv)
 	}
+	return false, nil
+}
 
-	// Handle override if rv implements json.Marshaler.
-	if info.IsAminoMarshaler {
-		// First, encode rv into repr instance.
-		var (
-			rrv   reflect.Value
-			rinfo *TypeInfo
-		)
-		rrv, err = toReprObject(rv)
-		if err != nil {
-			return
-		}
-		rinfo, err = cdc.getTypeInfoWlock(info.AminoMarshalReprType)
-		if err != nil {
-			return
-		}
-		// Then, encode the repr instance.
-		err = cdc.encodeReflectJSON(w, rinfo, rrv, fopts)
-		return
-	}
+func (jf *jsonFormat) EncodeBool(w io.Writer, b bool, _ FieldOptions) error {
+	return invokeStdlibJSONMarshal(w, b)
+}
 
+func (jf *jsonFormat) EncodeInt(w io.Writer, i int64, info *TypeInfo, _ FieldOptions) error {
 	switch info.Type.Kind() {
-
-	//----------------------------------------
-	// Complex
-
-	case reflect.Interface:
-		return cdc.encodeReflectJSONInterface(w, info, rv, fopts)
-
-	case reflect.Array, reflect.Slice:
-		return cdc.encodeReflectJSONList(w, info, rv, fopts)
-
-	case reflect.Struct:
-		return cdc.encodeReflectJSONStruct(w, info, rv, fopts)
-
-	case reflect.Map:
-		return cdc.encodeReflectJSONMap(w, info, rv, fopts)
-
-	//----------------------------------------
-	// Signed, Unsigned
-
 	case reflect.Int64, reflect.Int:
-		_, err = fmt.Fprintf(w, `"%d"`, rv.Int()) // JS can't handle int64
-		return
+		_, err := fmt.Fprintf(w, `"%d"`, i) // JS can't handle int64
+		return err
+	default:
+		return writeStr(w, strconv.FormatInt(i, 10))
+	}
+}
 
+func (jf *jsonFormat) EncodeUint(w io.Writer, u uint64, info *TypeInfo, _ FieldOptions) error {
+	switch info.Type.Kind() {
 	case reflect.Uint64, reflect.Uint:
-		_, err = fmt.Fprintf(w, `"%d"`, rv.Uint()) // JS can't handle uint64
-		return
-
-	case reflect.Int32, reflect.Int16, reflect.Int8,
-		reflect.Uint32, reflect.Uint16, reflect.Uint8:
-		return invokeStdlibJSONMarshal(w, rv.Interface())
-
-	//----------------------------------------
-	// Misc
-
-	case reflect.Float64, reflect.Float32:
-		if !fopts.Unsafe {
-			return errors.New("amino.JSON float* support requires `amino:\"unsafe\"`")
-		}
-		fallthrough
-	case reflect.Bool, reflect.String:
-		return invokeStdlibJSONMarshal(w, rv.Interface())
-
-	//----------------------------------------
-	// Default
-
+		_, err := fmt.Fprintf(w, `"%d"`, u) // JS can't handle uint64
+		return err
 	default:
-		panic(fmt.Sprintf("unsupported type %v", info.Type.Kind()))
+		return writeStr(w, strconv.FormatUint(u, 10))
 	}
 }
 
-func (cdc *Codec) encodeReflectJSONInterface(w io.Writer, iinfo *TypeInfo, rv reflect.Value,
-	fopts FieldOptions) (err error) {
-	if printLog {
-		fmt.Println("(e) encodeReflectJSONInterface")
-		defer func() {
-			fmt.Printf("(e) -> err: %v\n", err)
-		}()
+func (jf *jsonFormat) EncodeFloat(w io.Writer, f float64, info *TypeInfo, _ FieldOptions) error {
+	if info.Type.Kind() == reflect.Float32 {
+		return invokeStdlibJSONMarshal(w, float32(f))
 	}
+	return invokeStdlibJSONMarshal(w, f)
+}
 
-	// Special case when rv is nil, just write "null".
-	if rv.IsNil() {
-		err = writeStr(w, `null`)
-		return
+func (jf *jsonFormat) EncodeComplex(w io.Writer, c complex128, info *TypeInfo, _ FieldOptions) error {
+	// Encoded as a two element array of the real and imaginary parts.
+	if info.Type.Kind() == reflect.Complex64 {
+		return invokeStdlibJSONMarshal(w, [2]float32{float32(real(c)), float32(imag(c))})
 	}
+	return invokeStdlibJSONMarshal(w, [2]float64{real(c), imag(c)})
+}
 
-	// Get concrete non-pointer reflect value & type.
-	var crv, isPtr, isNilPtr = derefPointers(rv.Elem())
-	if isPtr && crv.Kind() == reflect.Interface {
-		// See "MARKER: No interface-pointers" in codec.go
-		panic("should not happen")
-	}
-	if isNilPtr {
-		panic(fmt.Sprintf("Illegal nil-pointer of type %v for registered interface %v. "+
-			"For compatibility with other languages, nil-pointer interface values are forbidden.", crv.Type(), iinfo.Type))
-	}
-	var crt = crv.Type()
+func (jf *jsonFormat) EncodeString(w io.Writer, s string, _ FieldOptions) error {
+	return invokeStdlibJSONMarshal(w, s)
+}
 
-	// Get *TypeInfo for concrete type.
-	var cinfo *TypeInfo
-	cinfo, err = cdc.getTypeInfoWlock(crt)
-	if err != nil {
-		return
-	}
-	if !cinfo.Registered {
-		err = errors.Errorf("cannot encode unregistered concrete type %v", crt)
-		return
+func (jf *jsonFormat) EncodeBytes(w io.Writer, bz []byte, _ FieldOptions) error {
+	// Write bytes in base64.
+	// NOTE: Base64 encoding preserves the exact original number of bytes.
+	if bz == nil {
+		bz = []byte{} // Not null, which is for nil slices.
 	}
+	return invokeStdlibJSONMarshal(w, bz)
+}
 
-	// Write interface wrapper.
-	// Part 1:
-	err = writeStr(w, _fmt(`{"type":"%s","value":`, cinfo.Name))
+func (jf *jsonFormat) EncodeTime(w io.Writer, t time.Time, _ FieldOptions) error {
+	// Amino time strips the timezone.
+	t = t.Round(0).UTC()
+	bz, err := t.MarshalJSON()
 	if err != nil {
-		return
+		return err
 	}
-	// Part 2:
-	defer func() {
-		if err != nil {
-			return
-		}
-		err = writeStr(w, `}`)
-	}()
+	_, err = w.Write(bz)
+	return err
+}
 
+func (jf *jsonFormat) BeginInterface(w io.Writer, cinfo *TypeInfo, _ FieldOptions) error {
 	// NOTE: In the future, we may write disambiguation bytes
 	// here, if it is only to be written for interface values.
 	// Currently, go-amino JSON *always* writes disfix bytes for
 	// all registered concrete types.
+	return writeStr(w, _fmt(`{"type":"%s","value":`, cinfo.Name))
+}
 
-	err = cdc.encodeReflectJSON(w, cinfo, crv, fopts)
-	return err
+func (jf *jsonFormat) EndInterface(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	return writeStr(w, `}`)
 }
 
-func (cdc *Codec) encodeReflectJSONList(w io.Writer, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
-	if printLog {
-		fmt.Println("(e) encodeReflectJSONList")
-		defer func() {
-			fmt.Printf("(e) -> err: %v\n", err)
-		}()
-	}
+// Empty slices and arrays are not encoded as "null".
+func (jf *jsonFormat) EncodeNilSlice(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	return writeStr(w, `null`)
+}
 
-	// Special case when list is a nil slice, just write "null".
-	// Empty slices and arrays are not encoded as "null".
-	if rv.Kind() == reflect.Slice && rv.IsNil() {
-		err = writeStr(w, `null`)
-		return
-	}
+func (jf *jsonFormat) BeginList(w io.Writer, _, _ *TypeInfo, _ int, _ FieldOptions) error {
+	return writeStr(w, `[`)
+}
 
-	ert := info.Type.Elem()
-	length := rv.Len()
-
-	switch ert.Kind() {
-
-	case reflect.Uint8: // Special case: byte array
-		// Write bytes in base64.
-		// NOTE: Base64 encoding preserves the exact original number of bytes.
-		// Get readable slice of bytes.
-		var bz []byte
-		if rv.CanAddr() {
-			bz = rv.Slice(0, length).Bytes()
-		} else {
-			bz = make([]byte, length)
-			reflect.Copy(reflect.ValueOf(bz), rv) // XXX: looks expensive!
-		}
-		var jsonBytes []byte
-		jsonBytes, err = json.Marshal(bz) // base64 encode
-		if err != nil {
-			return
-		}
-		_, err = w.Write(jsonBytes)
-		return
+func (jf *jsonFormat) BeginElem(w io.Writer, index int) error {
+	if index > 0 {
+		return writeStr(w, `,`)
+	}
+	return nil
+}
 
-	default:
-		// Open square bracket.
-		err = writeStr(w, `[`)
-		if err != nil {
-			return
-		}
+func (jf *jsonFormat) EndList(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	return writeStr(w, `]`)
+}
 
-		// Write elements with comma.
-		var einfo *TypeInfo
-		einfo, err = cdc.getTypeInfoWlock(ert)
-		if err != nil {
-			return
-		}
-		for i := 0; i < length; i++ {
-			// Get dereferenced element value and info.
-			var erv, _, isNil = derefPointers(rv.Index(i))
-			if isNil {
-				err = writeStr(w, `null`)
-			} else {
-				err = cdc.encodeReflectJSON(w, einfo, erv, fopts)
-			}
-			if err != nil {
-				return
-			}
-			// Add a comma if it isn't the last item.
-			if i != length-1 {
-				err = writeStr(w, `,`)
-				if err != nil {
-					return
-				}
-			}
-		}
+func (jf *jsonFormat) BeginStruct(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	jf.commas = append(jf.commas, false)
+	return writeStr(w, `{`)
+}
 
-		// Close square bracket.
-		defer func() {
-			err = writeStr(w, `]`)
-		}()
-		return
-	}
+func (jf *jsonFormat) SkipField(field *FieldInfo, rv reflect.Value) bool {
+	return SkipFieldJSON(field, rv)
 }
 
-func (cdc *Codec) encodeReflectJSONStruct(w io.Writer, info *TypeInfo, rv reflect.Value, _ FieldOptions) (err error) {
-	if printLog {
-		fmt.Println("(e) encodeReflectJSONStruct")
-		defer func() {
-			fmt.Printf("(e) -> err: %v\n", err)
-		}()
-	}
+func (jf *jsonFormat) BeginField(w io.Writer, field *FieldInfo) error {
+	return jf.beginKey(w, field.JSONName)
+}
 
-	// Part 1.
-	err = writeStr(w, `{`)
-	if err != nil {
-		return
-	}
-	// Part 2.
-	defer func() {
-		if err == nil {
-			err = writeStr(w, `}`)
-		}
-	}()
-
-	var writeComma = false
-	for _, field := range info.Fields {
-		// Get dereferenced field value and info.
-		var frv, _, isNil = derefPointers(rv.Field(field.Index))
-		var finfo *TypeInfo
-		finfo, err = cdc.getTypeInfoWlock(field.Type)
-		if err != nil {
-			return
-		}
-		// If frv is empty and omitempty, skip it.
-		// NOTE: Unlike Amino:binary, we don't skip null fields unless "omitempty".
-		if field.JSONOmitEmpty && isEmpty(frv, field.ZeroValue) {
-			continue
-		}
-		// Now we know we're going to write something.
-		// Add a comma if we need to.
-		if writeComma {
-			err = writeStr(w, `,`)
-			if err != nil {
-				return
-			}
-			writeComma = false //nolint:ineffassign
-		}
-		// Write field JSON name.
-		err = invokeStdlibJSONMarshal(w, field.JSONName)
-		if err != nil {
-			return
-		}
-		// Write colon.
-		err = writeStr(w, `:`)
-		if err != nil {
-			return
-		}
-		// Write field value.
-		if isNil {
-			err = writeStr(w, `null`)
-		} else {
-			err = cdc.encodeReflectJSON(w, finfo, frv, field.FieldOptions)
-		}
-		if err != nil {
-			return
-		}
-		writeComma = true
-	}
-	return err
+func (jf *jsonFormat) EndStruct(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	jf.commas = jf.commas[:len(jf.commas)-1]
+	return writeStr(w, `}`)
 }
 
-// TODO: TEST
-func (cdc *Codec) encodeReflectJSONMap(w io.Writer, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
-	if printLog {
-		fmt.Println("(e) encodeReflectJSONMap")
-		defer func() {
-			fmt.Printf("(e) -> err: %v\n", err)
-		}()
-	}
+func (jf *jsonFormat) BeginMap(w io.Writer, _ *TypeInfo, _ int, _ FieldOptions) error {
+	jf.commas = append(jf.commas, false)
+	return writeStr(w, `{`)
+}
 
-	// Part 1.
-	err = writeStr(w, `{`)
-	if err != nil {
-		return
-	}
-	// Part 2.
-	defer func() {
-		if err == nil {
-			err = writeStr(w, `}`)
-		}
-	}()
+func (jf *jsonFormat) BeginKey(w io.Writer, key string) error {
+	return jf.beginKey(w, key)
+}
 
-	// Ensure that the map key type is a string.
-	if rv.Type().Key().Kind() != reflect.String {
-		err = errors.New("encodeReflectJSONMap: map key type must be a string")
-		return
-	}
+func (jf *jsonFormat) EndMap(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	jf.commas = jf.commas[:len(jf.commas)-1]
+	return writeStr(w, `}`)
+}
 
-	var writeComma = false
-	for _, krv := range rv.MapKeys() {
-		// Get dereferenced object value and info.
-		var vrv, _, isNil = derefPointers(rv.MapIndex(krv))
-
-		// Add a comma if we need to.
-		if writeComma {
-			err = writeStr(w, `,`)
-			if err != nil {
-				return
-			}
-			writeComma = false //nolint:ineffassign
-		}
-		// Write field name.
-		err = invokeStdlibJSONMarshal(w, krv.Interface())
-		if err != nil {
-			return
-		}
-		// Write colon.
-		err = writeStr(w, `:`)
-		if err != nil {
-			return
+// Writes the key, with a comma if it isn't the first, and a colon.
+func (jf *jsonFormat) beginKey(w io.Writer, key string) error {
+	if jf.commas[len(jf.commas)-1] {
+		if err := writeStr(w, `,`); err != nil {
+			return err
 		}
-		// Write field value.
-		if isNil {
-			err = writeStr(w, `null`)
-		} else {
-			var vinfo *TypeInfo
-			vinfo, err = cdc.getTypeInfoWlock(vrv.Type())
-			if err != nil {
-				return
-			}
-			err = cdc.encodeReflectJSON(w, vinfo, vrv, fopts) // pass through fopts
-		}
-		if err != nil {
-			return
-		}
-		writeComma = true
 	}
-	return err
-
+	jf.commas[len(jf.commas)-1] = true
+	if err := invokeStdlibJSONMarshal(w, key); err != nil {
+		return err
+	}
+	return writeStr(w, `:`)
 }
 
 //----------------------------------------
@@ -0,0 +1,360 @@
+package amino
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+//----------------------------------------
+// JSON Schema
+
+// JSONSchemaDraft is the "$schema" of the schemas returned by JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns a JSON Schema (draft 2020-12) of the amino JSON encoding
+// of the type that ptr points to, i.e. of what MarshalJSON writes.  As with
+// RegisterInterface, an interface type is given as e.g. (*MyInterface)(nil).
+//
+// Structs and interfaces are defined under "$defs", by their registered name
+// or else their Go type.  Interfaces are a "oneOf" over their registered
+// implementers, each in its {"type","value"} wrapper.  The legacy names of
+// `amino:"json_alias=..."` fields, which are decoded but never written, are
+// "deprecated" properties, and a required field may be given by any of its
+// names.
+func (cdc *Codec) JSONSchema(ptr interface{}) ([]byte, error) {
+	rt := reflect.TypeOf(ptr)
+	if rt == nil || rt.Kind() != reflect.Ptr {
+		return nil, errors.New("expected a pointer")
+	}
+	rt = rt.Elem()
+
+	sb := &jsonSchemaBuilder{cdc: cdc, defs: make(map[string]interface{})}
+	info, err := cdc.getTypeInfoWlock(rt)
+	if err != nil {
+		return nil, err
+	}
+	var root map[string]interface{}
+	if info.Registered {
+		// Registered concrete types are wrapped, as in MarshalJSON.
+		root, err = sb.wrapperSchema(info)
+	} else {
+		root, err = sb.schema(rt, FieldOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	root["$schema"] = JSONSchemaDraft
+	if len(sb.defs) > 0 {
+		root["$defs"] = sb.defs
+	}
+	return json.MarshalIndent(root, "", "  ")
+}
+
+type jsonSchemaBuilder struct {
+	cdc  *Codec
+	defs map[string]interface{}
+}
+
+// Returns the schema of rt, which may be a pointer.  Like encodeReflectJSON,
+// fopts is passed through to list elements and map values.
+func (sb *jsonSchemaBuilder) schema(rt reflect.Type, fopts FieldOptions) (s map[string]interface{}, err error) {
+	// Nil pointers are written as null.
+	if rt.Kind() == reflect.Ptr {
+		for rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		s, err = sb.schema(rt, fopts)
+		return nullable(s), err
+	}
+
+	// Special case:
+	if rt == timeType {
+		// Amino time strips the timezone.
+		return map[string]interface{}{
+			"type":    "string",
+			"format":  "date-time",
+			"pattern": `^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]{1,9})?Z$`,
+		}, nil
+	}
+	// Types that implement json.Marshaler may write anything.
+	if rt.Implements(jsonMarshalerType) || reflect.PtrTo(rt).Implements(jsonMarshalerType) {
+		return map[string]interface{}{}, nil
+	}
+
+	var info *TypeInfo
+	info, err = sb.cdc.getTypeInfoWlock(rt)
+	if err != nil {
+		return
+	}
+	// Types that implement MarshalAmino are written as their repr.
+	if info.IsAminoMarshaler {
+		return sb.schema(info.AminoMarshalReprType, fopts)
+	}
+
+	switch rt.Kind() {
+
+	//----------------------------------------
+	// Complex
+
+	case reflect.Interface:
+		// Nil interfaces are written as null.
+		s, err = sb.ref(info, sb.interfaceSchema)
+		return nullable(s), err
+
+	case reflect.Array, reflect.Slice:
+		return sb.listSchema(rt, fopts)
+
+	case reflect.Struct:
+		if rt.Name() == "" {
+			// Anonymous structs can't be recursive, so define them inline.
+			return sb.structSchema(info)
+		}
+		return sb.ref(info, sb.structSchema)
+
+	case reflect.Map:
+		if rt.Key().Kind() != reflect.String {
+			return nil, errors.New("encodeReflectJSONMap: map key type must be a string")
+		}
+		var vs map[string]interface{}
+		vs, err = sb.schema(rt.Elem(), fopts) // pass through fopts
+		if err != nil {
+			return
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": vs}, nil
+
+	//----------------------------------------
+	// Signed, Unsigned
+
+	case reflect.Int64, reflect.Int:
+		// JS can't handle int64, so it is quoted.
+		return map[string]interface{}{"type": "string", "pattern": "^-?[0-9]+$"}, nil
+
+	case reflect.Uint64, reflect.Uint:
+		// JS can't handle uint64, so it is quoted.
+		return map[string]interface{}{"type": "string", "pattern": "^[0-9]+$"}, nil
+
+	case reflect.Int32, reflect.Int16, reflect.Int8:
+		bits := uint(rt.Bits())
+		return map[string]interface{}{
+			"type":    "integer",
+			"minimum": -int64(1) << (bits - 1),
+			"maximum": int64(1)<<(bits-1) - 1,
+		}, nil
+
+	case reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return map[string]interface{}{
+			"type":    "integer",
+			"minimum": 0,
+			"maximum": uint64(math.MaxUint64) >> uint(64-rt.Bits()),
+		}, nil
+
+	//----------------------------------------
+	// Misc
+
+	case reflect.Float64, reflect.Float32:
+		if !fopts.Unsafe {
+			return nil, errors.New("amino.JSON float* support requires `amino:\"unsafe\"`")
+		}
+		return map[string]interface{}{"type": "number"}, nil
+
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+
+	case reflect.Complex128, reflect.Complex64:
+		if !fopts.Unsafe {
+			return nil, errors.New("amino.JSON complex* support requires `amino:\"unsafe\"`")
+		}
+		// Encoded as a two element array of the real and imaginary parts.
+		return map[string]interface{}{
+			"type":        "array",
+			"prefixItems": []interface{}{map[string]interface{}{"type": "number"}, map[string]interface{}{"type": "number"}},
+			"items":       false,
+		}, nil
+
+	//----------------------------------------
+	// Default
+
+	default:
+		return nil, errors.Errorf("unsupported type %v", rt)
+	}
+}
+
+// Returns a reference to the definition of info, which is built by define
+// unless it already exists.
+func (sb *jsonSchemaBuilder) ref(info *TypeInfo,
+	define func(*TypeInfo) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	var name = info.Type.String()
+	if info.Registered {
+		name = info.Name
+	}
+	var ref = map[string]interface{}{"$ref": "#/$defs/" + EscapeJSONPointer(name)}
+	if _, ok := sb.defs[name]; ok {
+		return ref, nil
+	}
+	// Reserve the name first, for recursive types.
+	sb.defs[name] = true
+	s, err := define(info)
+	if err != nil {
+		return nil, err
+	}
+	sb.defs[name] = s
+	return ref, nil
+}
+
+func (sb *jsonSchemaBuilder) interfaceSchema(iinfo *TypeInfo) (map[string]interface{}, error) {
+	// Collect the implementers, in order of name.
+	var cinfos []*TypeInfo
+	sb.cdc.mtx.RLock()
+	for _, infos := range iinfo.Implementers {
+		cinfos = append(cinfos, infos...)
+	}
+	sb.cdc.mtx.RUnlock()
+	sort.Slice(cinfos, func(i, j int) bool { return cinfos[i].Name < cinfos[j].Name })
+
+	var oneOf = make([]interface{}, 0, len(cinfos))
+	for _, cinfo := range cinfos {
+		s, err := sb.wrapperSchema(cinfo)
+		if err != nil {
+			return nil, err
+		}
+		oneOf = append(oneOf, s)
+	}
+	if len(oneOf) == 0 {
+		// Nothing but null can be written.
+		return map[string]interface{}{"not": map[string]interface{}{}}, nil
+	}
+	return map[string]interface{}{"oneOf": oneOf}, nil
+}
+
+// Returns the schema of the {"type","value"} wrapper of the registered
+// concrete type cinfo.
+func (sb *jsonSchemaBuilder) wrapperSchema(cinfo *TypeInfo) (map[string]interface{}, error) {
+	value, err := sb.schema(cinfo.Type, FieldOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type":  map[string]interface{}{"const": cinfo.Name},
+			"value": value,
+		},
+		"required":             []string{"type", "value"},
+		"additionalProperties": false,
+	}, nil
+}
+
+func (sb *jsonSchemaBuilder) listSchema(rt reflect.Type, fopts FieldOptions) (s map[string]interface{}, err error) {
+	if rt.Elem().Kind() == reflect.Uint8 {
+		// Special case: byte array, written in base64.
+		s = map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		if rt.Kind() == reflect.Array {
+			length := 4 * ((rt.Len() + 2) / 3)
+			s["minLength"], s["maxLength"] = length, length
+		}
+	} else {
+		var es map[string]interface{}
+		es, err = sb.schema(rt.Elem(), fopts)
+		if err != nil {
+			return
+		}
+		s = map[string]interface{}{"type": "array", "items": es}
+		if rt.Kind() == reflect.Array {
+			s["minItems"], s["maxItems"] = rt.Len(), rt.Len()
+		}
+	}
+	// Nil slices are written as null.
+	if rt.Kind() == reflect.Slice {
+		s = nullable(s)
+	}
+	return
+}
+
+func (sb *jsonSchemaBuilder) structSchema(info *TypeInfo) (map[string]interface{}, error) {
+	var properties = make(map[string]interface{}, len(info.Fields))
+	var required = []string{}
+	var allOf []interface{}
+	for _, field := range info.Fields {
+		if field.JSONSkip {
+			continue // e.g. amino:"-json"
+		}
+		fs, err := sb.schema(field.Type, field.FieldOptions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %v.%v", info.Type, field.Name)
+		}
+		if field.Optional {
+			// Optional fields are skipped iff nil, so they are never null.
+			fs = nonNullable(fs)
+		}
+		properties[field.JSONName] = fs
+
+		// Legacy names are still decoded, e.g. amino:"json_alias=oldName",
+		// but never written.
+		for _, alias := range field.JSONAliases {
+			as := make(map[string]interface{}, len(fs)+1)
+			for k, v := range fs {
+				as[k] = v
+			}
+			as["deprecated"] = true
+			properties[alias] = as
+		}
+		if field.Optional || field.JSONOmitEmpty {
+			continue
+		}
+		if len(field.JSONAliases) == 0 {
+			required = append(required, field.JSONName)
+			continue
+		}
+		// Required by its name or any alias.
+		var anyOf = []interface{}{map[string]interface{}{"required": []string{field.JSONName}}}
+		for _, alias := range field.JSONAliases {
+			anyOf = append(anyOf, map[string]interface{}{"required": []string{alias}})
+		}
+		allOf = append(allOf, map[string]interface{}{"anyOf": anyOf})
+	}
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	if allOf != nil {
+		s["allOf"] = allOf
+	}
+	return s, nil
+}
+
+//----------------------------------------
+// Misc.
+
+// Allows null as well as s.
+func nullable(s map[string]interface{}) map[string]interface{} {
+	if s == nil || isNullable(s) {
+		return s
+	}
+	return map[string]interface{}{"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}}}
+}
+
+func isNullable(s map[string]interface{}) bool {
+	if len(s) == 0 {
+		return true // Anything.
+	}
+	anyOf, ok := s["anyOf"].([]interface{})
+	return ok && len(anyOf) == 2 && reflect.DeepEqual(anyOf[1], map[string]interface{}{"type": "null"})
+}
+
+// The inverse of nullable.
+func nonNullable(s map[string]interface{}) map[string]interface{} {
+	if len(s) != 0 && isNullable(s) {
+		return s["anyOf"].([]interface{})[0].(map[string]interface{})
+	}
+	return s
+}
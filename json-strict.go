@@ -0,0 +1,380 @@
+package amino
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//----------------------------------------
+// Strict JSON decoding
+
+// StrictJSONOptions are the options for UnmarshalJSONStrict.
+type StrictJSONOptions struct {
+	// If Canonical is set, the JSON must also be exactly as written by
+	// MarshalJSON with respect to whitespace (none) and string escaping.
+	Canonical bool
+}
+
+// StrictJSONError is returned by UnmarshalJSONStrict when the JSON is
+// well-formed but violates the strict rules.
+type StrictJSONError struct {
+	Pointer string // RFC 6901 JSON pointer to the offending key or value.
+	Reason  string
+}
+
+func (err *StrictJSONError) Error() string {
+	return fmt.Sprintf("amino:JSON strict: %v at %q", err.Reason, err.Pointer)
+}
+
+// UnmarshalJSONStrict is like UnmarshalJSON, but it first validates bz
+// against the type of ptr and errors on unknown keys, duplicate keys (or a
+// key given by both its name and an alias) and trailing data.  This makes the
+// decoding unambiguous, which matters when the JSON is signed.
+func (cdc *Codec) UnmarshalJSONStrict(bz []byte, ptr interface{}, opts StrictJSONOptions) error {
+	if len(bz) == 0 {
+		return errors.New("cannot decode empty bytes")
+	}
+
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr {
+		return errors.New("expected a pointer")
+	}
+	info, err := cdc.getTypeInfoWlock(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	// Read exactly one top-level value.
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	var value json.RawMessage
+	if err = dec.Decode(&value); err != nil {
+		return err
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return &StrictJSONError{Pointer: "", Reason: "trailing data after top-level value"}
+	}
+	if opts.Canonical && !bytes.Equal(value, bz) {
+		return &StrictJSONError{Pointer: "", Reason: "non-canonical whitespace"}
+	}
+
+	// If registered concrete, expect the type wrapper.
+	if info.Registered {
+		err = cdc.checkStrictJSONInterface(value, info, opts, "")
+	} else {
+		err = cdc.checkStrictJSON(value, info, opts, "")
+	}
+	if err != nil {
+		return err
+	}
+	return cdc.UnmarshalJSON(bz, ptr)
+}
+
+// Validates bz as the JSON of info.  If info is nil, only the generic rules
+// (duplicate keys, canonical form) are checked.
+func (cdc *Codec) checkStrictJSON(bz []byte, info *TypeInfo, opts StrictJSONOptions, pointer string) (err error) {
+	if nullBytes(bz) {
+		return nil
+	}
+
+	if info != nil {
+		switch {
+		case reflect.PtrTo(info.Type).Implements(jsonUnmarshalerType),
+			info.Type == timeType:
+			// The value is opaque to amino.
+			info = nil
+		case info.IsAminoUnmarshaler:
+			var rinfo *TypeInfo
+			rinfo, err = cdc.getTypeInfoWlock(info.AminoUnmarshalReprType)
+			if err != nil {
+				return
+			}
+			return cdc.checkStrictJSON(bz, rinfo, opts, pointer)
+		}
+	}
+
+	if info == nil {
+		switch bz[0] {
+		case '{':
+			return cdc.checkStrictJSONMap(bz, nil, opts, pointer)
+		case '[':
+			return cdc.checkStrictJSONList(bz, nil, opts, pointer)
+		default:
+			return checkStrictJSONScalar(bz, opts, pointer)
+		}
+	}
+
+	switch info.Type.Kind() {
+
+	case reflect.Interface:
+		return cdc.checkStrictJSONInterface(bz, nil, opts, pointer)
+
+	case reflect.Array, reflect.Slice:
+		if info.Type.Elem().Kind() == reflect.Uint8 {
+			return checkStrictJSONScalar(bz, opts, pointer)
+		}
+		var einfo *TypeInfo
+		einfo, err = cdc.getTypeInfoWlock(info.Type.Elem())
+		if err != nil {
+			return
+		}
+		return cdc.checkStrictJSONList(bz, einfo, opts, pointer)
+
+	case reflect.Struct:
+		return cdc.checkStrictJSONStruct(bz, info, opts, pointer)
+
+	case reflect.Map:
+		var vinfo *TypeInfo
+		vinfo, err = cdc.getTypeInfoWlock(info.Type.Elem())
+		if err != nil {
+			return
+		}
+		return cdc.checkStrictJSONMap(bz, vinfo, opts, pointer)
+
+	default:
+		return checkStrictJSONScalar(bz, opts, pointer)
+	}
+}
+
+// Validates the {"type":<name>,"value":<value>} wrapper of an interface
+// value.  If cinfo is nil, it is looked up by name.
+func (cdc *Codec) checkStrictJSONInterface(bz []byte, cinfo *TypeInfo, opts StrictJSONOptions,
+	pointer string) (err error) {
+	keys, values, err := readJSONObject(bz)
+	if err != nil {
+		return
+	}
+	var seen = make(map[string]bool, len(keys))
+	for i, key := range keys {
+		kpointer := pointer + "/" + EscapeJSONPointer(key)
+		if key != "type" && key != "value" {
+			return &StrictJSONError{Pointer: kpointer, Reason: "unknown key"}
+		}
+		if seen[key] {
+			return &StrictJSONError{Pointer: kpointer, Reason: "duplicate key"}
+		}
+		seen[key] = true
+		if key == "type" {
+			if err = checkStrictJSONScalar(values[i], opts, kpointer); err != nil {
+				return
+			}
+			continue
+		}
+		if cinfo == nil {
+			// NOTE: An unknown or missing name is reported by the decoder.
+			var name string
+			var idx = keyIndex(keys, "type")
+			if idx < 0 {
+				return nil
+			}
+			if err = json.Unmarshal(values[idx], &name); err != nil {
+				return nil
+			}
+			if cinfo, err = cdc.getTypeInfoFromNameRlock(name); err != nil {
+				return nil
+			}
+		}
+		if err = cdc.checkStrictJSON(values[i], cinfo, opts, kpointer); err != nil {
+			return
+		}
+	}
+	return checkCanonicalJSONObject(bz, keys, values, opts, pointer)
+}
+
+func (cdc *Codec) checkStrictJSONStruct(bz []byte, info *TypeInfo, opts StrictJSONOptions,
+	pointer string) (err error) {
+	keys, values, err := readJSONObject(bz)
+	if err != nil {
+		return
+	}
+
+	// Map all JSON names (and aliases) to their fields.
+	var fields = make(map[string]*FieldInfo, len(info.Fields))
+	for i := range info.Fields {
+		field := &info.Fields[i]
+		if field.JSONSkip {
+			continue
+		}
+		fields[field.JSONName] = field
+		for _, alias := range field.JSONAliases {
+			if _, ok := fields[alias]; !ok {
+				fields[alias] = field
+			}
+		}
+	}
+
+	var seen = make(map[*FieldInfo]bool, len(keys))
+	for i, key := range keys {
+		kpointer := pointer + "/" + EscapeJSONPointer(key)
+		field, ok := fields[key]
+		if !ok {
+			return &StrictJSONError{Pointer: kpointer, Reason: "unknown key"}
+		}
+		if seen[field] {
+			return &StrictJSONError{Pointer: kpointer, Reason: "duplicate key"}
+		}
+		seen[field] = true
+		var finfo *TypeInfo
+		finfo, err = cdc.getTypeInfoWlock(field.Type)
+		if err != nil {
+			return
+		}
+		if err = cdc.checkStrictJSON(values[i], finfo, opts, kpointer); err != nil {
+			return
+		}
+	}
+	return checkCanonicalJSONObject(bz, keys, values, opts, pointer)
+}
+
+// If vinfo is nil, the values are checked generically.
+func (cdc *Codec) checkStrictJSONMap(bz []byte, vinfo *TypeInfo, opts StrictJSONOptions,
+	pointer string) (err error) {
+	keys, values, err := readJSONObject(bz)
+	if err != nil {
+		return
+	}
+	var seen = make(map[string]bool, len(keys))
+	for i, key := range keys {
+		kpointer := pointer + "/" + EscapeJSONPointer(key)
+		if seen[key] {
+			return &StrictJSONError{Pointer: kpointer, Reason: "duplicate key"}
+		}
+		seen[key] = true
+		if err = cdc.checkStrictJSON(values[i], vinfo, opts, kpointer); err != nil {
+			return
+		}
+	}
+	return checkCanonicalJSONObject(bz, keys, values, opts, pointer)
+}
+
+// If einfo is nil, the elements are checked generically.
+func (cdc *Codec) checkStrictJSONList(bz []byte, einfo *TypeInfo, opts StrictJSONOptions,
+	pointer string) (err error) {
+	var values []json.RawMessage
+	if err = json.Unmarshal(bz, &values); err != nil {
+		return
+	}
+	for i, value := range values {
+		if err = cdc.checkStrictJSON(value, einfo, opts, pointer+"/"+strconv.Itoa(i)); err != nil {
+			return
+		}
+	}
+	if !opts.Canonical {
+		return nil
+	}
+	var canonical = []byte{'['}
+	for i, value := range values {
+		if i > 0 {
+			canonical = append(canonical, ',')
+		}
+		canonical = append(canonical, value...)
+	}
+	canonical = append(canonical, ']')
+	if !bytes.Equal(bz, canonical) {
+		return &StrictJSONError{Pointer: pointer, Reason: "non-canonical whitespace"}
+	}
+	return nil
+}
+
+func checkStrictJSONScalar(bz []byte, opts StrictJSONOptions, pointer string) error {
+	if !opts.Canonical || bz[0] != '"' {
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(bz, &str); err != nil {
+		return err
+	}
+	canonical, err := json.Marshal(str)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(bz, canonical) {
+		return &StrictJSONError{Pointer: pointer, Reason: "non-canonical string escaping"}
+	}
+	return nil
+}
+
+// The values must already have been checked, so a mismatch is due to the
+// whitespace or key escaping of this object.
+func checkCanonicalJSONObject(bz []byte, keys []string, values []json.RawMessage,
+	opts StrictJSONOptions, pointer string) error {
+	if !opts.Canonical {
+		return nil
+	}
+	var canonical = []byte{'{'}
+	for i, key := range keys {
+		if i > 0 {
+			canonical = append(canonical, ',')
+		}
+		kbz, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		canonical = append(canonical, kbz...)
+		canonical = append(canonical, ':')
+		canonical = append(canonical, values[i]...)
+		if !bytes.HasPrefix(bz, canonical) {
+			return &StrictJSONError{
+				Pointer: pointer + "/" + EscapeJSONPointer(key),
+				Reason:  "non-canonical whitespace or key escaping",
+			}
+		}
+	}
+	canonical = append(canonical, '}')
+	if !bytes.Equal(bz, canonical) {
+		return &StrictJSONError{Pointer: pointer, Reason: "non-canonical whitespace"}
+	}
+	return nil
+}
+
+//----------------------------------------
+// Misc.
+
+// Reads the keys and values of a JSON object in order, keeping duplicates.
+func readJSONObject(bz []byte) (keys []string, values []json.RawMessage, err error) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	tok, err := dec.Token()
+	if err != nil {
+		return
+	}
+	if tok != json.Delim('{') {
+		err = errors.Errorf("expected a JSON object, got %s", bz)
+		return
+	}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return
+		}
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return
+		}
+		keys = append(keys, tok.(string))
+		values = append(values, value)
+	}
+	_, err = dec.Token() // Consume '}'.
+	return
+}
+
+func keyIndex(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// EscapeJSONPointer escapes a reference token of a JSON pointer, e.g. a key
+// of StrictJSONError.Pointer, as per RFC 6901.
+func EscapeJSONPointer(token string) string {
+	token = strings.Replace(token, "~", "~0", -1)
+	return strings.Replace(token, "/", "~1", -1)
+}
@@ -492,6 +492,21 @@ func TestAminoJSONTimeEncodeDecodeRoundTrip(t *testing.T) {
 	require.Equal(t, tAminoOut, tStdlibOut, "expecting amino.unmarshaled to be equal to json.unmarshaled")
 }
 
+func TestUnmarshalJSONFieldOptions(t *testing.T) {
+	type Unsafe struct {
+		F float64 `amino:"unsafe"`
+	}
+	cdc := amino.NewCodec()
+
+	// Fields are decoded with their own options, as they're encoded.
+	bz, err := cdc.MarshalJSON(Unsafe{1.5})
+	require.NoError(t, err)
+	assert.Equal(t, `{"F":1.5}`, string(bz))
+	var u Unsafe
+	require.NoError(t, cdc.UnmarshalJSON(bz, &u))
+	assert.Equal(t, Unsafe{1.5}, u)
+}
+
 //----------------------------------------
 
 func TestMarshalJSONMap(t *testing.T) {
@@ -587,3 +602,239 @@ func TestMarshalJSONIndent(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expected, string(blob))
 }
+
+func TestComplexJSON(t *testing.T) {
+	type ComplexStruct struct {
+		C64  complex64    `amino:"unsafe"`
+		C128 complex128   `amino:"unsafe"`
+		Cs   []complex128 `amino:"unsafe"`
+	}
+
+	cdc := amino.NewCodec()
+
+	cs := ComplexStruct{
+		C64:  complex(1.5, -2),
+		C128: complex(-0.25, 3),
+		Cs:   []complex128{complex(1, 0), 0},
+	}
+	bz, err := cdc.MarshalJSON(cs)
+	require.NoError(t, err)
+	assert.Equal(t, `{"C64":[1.5,-2],"C128":[-0.25,3],"Cs":[[1,0],[0,0]]}`, string(bz))
+
+	var cs2 ComplexStruct
+	err = cdc.UnmarshalJSON(bz, &cs2)
+	require.NoError(t, err)
+	assert.Equal(t, cs, cs2)
+
+	err = cdc.UnmarshalJSON([]byte(`{"C64":[1.5]}`), &cs2)
+	assert.Error(t, err, "complex numbers require both parts")
+	_, err = cdc.MarshalJSON(complex128(1))
+	assert.Error(t, err, "complex numbers require amino:\"unsafe\"")
+}
+
+func TestOptionalFieldsJSON(t *testing.T) {
+	type Optionals struct {
+		Int    *int64  `json:"int,omitempty" amino:"optional"`
+		String *string `json:"string" amino:"optional"`
+	}
+
+	cdc := amino.NewCodec()
+
+	bz, err := cdc.MarshalJSON(Optionals{})
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(bz), "nil optional fields are omitted")
+
+	var zeroInt int64
+	var zeroString string
+	bz, err = cdc.MarshalJSON(Optionals{Int: &zeroInt, String: &zeroString})
+	require.NoError(t, err)
+	assert.Equal(t, `{"int":"0","string":""}`, string(bz), "set optional fields are written even if zero")
+
+	var o Optionals
+	err = cdc.UnmarshalJSON(bz, &o)
+	require.NoError(t, err)
+	require.NotNil(t, o.Int)
+	require.NotNil(t, o.String)
+
+	err = cdc.UnmarshalJSON([]byte(`{}`), &o)
+	require.NoError(t, err)
+	assert.Nil(t, o.Int, "absent optional fields are reset to nil")
+	assert.Nil(t, o.String)
+}
+
+func TestUnmarshalJSONMerge(t *testing.T) {
+	type Inner struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}
+	type State struct {
+		Num   int64            `json:"num"`
+		Inner *Inner           `json:"inner"`
+		List  []string         `json:"list"`
+		Map   map[string]int32 `json:"map"`
+	}
+
+	cdc := amino.NewCodec()
+
+	state := State{
+		Num:   1,
+		Inner: &Inner{A: 1, B: "b"},
+		List:  []string{"x"},
+		Map:   map[string]int32{"k1": 1, "k2": 2},
+	}
+	err := cdc.UnmarshalJSONMerge([]byte(`{"num":"0","inner":{"b":"c"},"list":["y"],"map":{"k2":3}}`), &state)
+	require.NoError(t, err)
+	assert.Equal(t, State{
+		Num:   0,
+		Inner: &Inner{A: 1, B: "c"},
+		List:  []string{"x", "y"},
+		Map:   map[string]int32{"k1": 1, "k2": 3},
+	}, state, "present fields overwrite, lists append, absent fields are untouched")
+
+	err = cdc.UnmarshalJSON([]byte(`{"num":"2"}`), &state)
+	require.NoError(t, err)
+	assert.Equal(t, State{Num: 2}, state, "without merge absent fields are reset")
+}
+
+func TestUnmarshalJSONStrict(t *testing.T) {
+	type Inner struct {
+		A int32  `json:"a"`
+		B string `json:"b"`
+	}
+	type Doc struct {
+		Name   string  `json:"name" amino:"json_alias=title"`
+		Inners []Inner `json:"inners"`
+	}
+
+	cdc := amino.NewCodec()
+	lax, canonical := amino.StrictJSONOptions{}, amino.StrictJSONOptions{Canonical: true}
+
+	cases := []struct {
+		bz      string
+		opts    amino.StrictJSONOptions
+		wantErr bool
+		pointer string
+	}{
+		{`{"name":"x","inners":[{"a":1}]}`, lax, false, ""},
+		{` {"name" : "x"} `, lax, false, ""},
+		{`{"name":"x","extra":1}`, lax, true, "/extra"},
+		{`{"name":"x","inners":[{"a":1},{"c":2}]}`, lax, true, "/inners/1/c"},
+		{`{"name":"x","name":"y"}`, lax, true, "/name"},
+		{`{"title":"x","name":"y"}`, lax, true, "/name"},
+		{`{"name":"x"}{}`, lax, true, ""},
+		{`{"inners":[{"a":1,"a":2}]}`, lax, true, "/inners/0/a"},
+		{`{"name":"x","inners":[{"a":1}]}`, canonical, false, ""},
+		{`{"name":"a\"b"}`, canonical, false, ""},
+		{`{"name":"x"} `, canonical, true, ""},
+		{`{"name": "x"}`, canonical, true, "/name"},
+		{`{"name":"x","inners":[{"a":1} ]}`, canonical, true, "/inners"},
+		{`{"name":"<"}`, canonical, true, "/name"},
+		{`{"name":"a\/b"}`, canonical, true, "/name"},
+	}
+	for i, tc := range cases {
+		var doc Doc
+		err := cdc.UnmarshalJSONStrict([]byte(tc.bz), &doc, tc.opts)
+		if !tc.wantErr {
+			assert.NoError(t, err, "#%v", i)
+			continue
+		}
+		serr, ok := err.(*amino.StrictJSONError)
+		require.True(t, ok, "#%v: expected a strict error, got %v", i, err)
+		assert.Equal(t, tc.pointer, serr.Pointer, "#%v", i)
+	}
+	assert.Equal(t, "a~1b~0c", amino.EscapeJSONPointer("a/b~c"))
+}
+
+func TestJSONSchema(t *testing.T) {
+	type Doc struct {
+		Height   int64          `json:"height"`
+		Count    uint8          `json:"count"`
+		Hash     [3]byte        `json:"hash"`
+		Data     []byte         `json:"data,omitempty"`
+		Time     time.Time      `json:"time"`
+		Vehicles []Vehicle      `json:"vehicles"`
+		Limits   map[string]int `json:"limits"`
+		Next     *int32         `json:"next" amino:"optional"`
+		Hidden   string         `amino:"-json"`
+		Name     string         `json:"name" amino:"json_alias=title"`
+	}
+
+	cdc := amino.NewCodec()
+	registerTransports(cdc)
+	bz, err := cdc.JSONSchema(&Doc{})
+	require.NoError(t, err)
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(bz, &schema))
+
+	// Returns the value at the JSON pointer path in schema.
+	get := func(path string) interface{} {
+		var v interface{} = schema
+		for _, token := range strings.Split(path, "/")[1:] {
+			token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+			switch vv := v.(type) {
+			case map[string]interface{}:
+				v = vv[token]
+			case []interface{}:
+				var i int
+				fmt.Sscan(token, &i)
+				v = vv[i]
+			default:
+				return nil
+			}
+		}
+		return v
+	}
+	doc := "/$defs/amino_test.Doc"
+
+	assert.Equal(t, amino.JSONSchemaDraft, get("/$schema"))
+	assert.Equal(t, "#"+doc, get("/$ref"))
+	assert.Equal(t, []interface{}{"height", "count", "hash", "time", "vehicles", "limits"}, get(doc+"/required"))
+	assert.Equal(t, false, get(doc+"/additionalProperties"))
+	assert.Nil(t, get(doc+"/properties/Hidden"))
+	assert.Equal(t, "^-?[0-9]+$", get(doc+"/properties/height/pattern"))
+	assert.Equal(t, 255.0, get(doc+"/properties/count/maximum"))
+	assert.Equal(t, "base64", get(doc+"/properties/hash/contentEncoding"))
+	assert.Equal(t, 4.0, get(doc+"/properties/hash/maxLength"))
+	assert.Equal(t, "base64", get(doc+"/properties/data/anyOf/0/contentEncoding"))
+	assert.Equal(t, "null", get(doc+"/properties/data/anyOf/1/type"))
+	assert.Equal(t, "date-time", get(doc+"/properties/time/format"))
+	assert.Equal(t, "#/$defs/amino_test.Vehicle", get(doc+"/properties/vehicles/anyOf/0/items/anyOf/0/$ref"))
+	assert.Equal(t, "^-?[0-9]+$", get(doc+"/properties/limits/additionalProperties/pattern"))
+	assert.Equal(t, 2147483647.0, get(doc+"/properties/next/maximum"), "optional fields are never null")
+
+	// Aliases are deprecated names, and required fields may be given by any.
+	assert.Equal(t, "string", get(doc+"/properties/name/type"))
+	assert.Nil(t, get(doc+"/properties/name/deprecated"))
+	assert.Equal(t, "string", get(doc+"/properties/title/type"))
+	assert.Equal(t, true, get(doc+"/properties/title/deprecated"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"required": []interface{}{"name"}},
+		map[string]interface{}{"required": []interface{}{"title"}},
+	}, get(doc+"/allOf/0/anyOf"))
+
+	// Interfaces are a oneOf over their implementers, in their wrappers.
+	vehicle := "/$defs/amino_test.Vehicle/oneOf"
+	var names []interface{}
+	for i := range get(vehicle).([]interface{}) {
+		names = append(names, get(fmt.Sprintf("%v/%v/properties/type/const", vehicle, i)))
+	}
+	assert.Equal(t, []interface{}{"boat", "car", "our/transport", "plane"}, names)
+	assert.Equal(t, []interface{}{"type", "value"}, get(vehicle+"/0/required"))
+	assert.Equal(t, "string", get(vehicle+"/1/properties/value/type"))
+	assert.Equal(t, "#/$defs/our~1transport", get(vehicle+"/2/properties/value/$ref"))
+	assert.Equal(t, "#/$defs/amino_test.Vehicle", get("/$defs/our~1transport/properties/Vehicle/anyOf/0/$ref"),
+		"recursive types are referenced")
+
+	// Registered concrete types are wrapped.
+	bz, err = cdc.JSONSchema(&Plane{})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bz, &schema))
+	assert.Equal(t, "plane", get("/properties/type/const"))
+	assert.Equal(t, "#/$defs/plane", get("/properties/value/$ref"))
+
+	// Like MarshalJSON, floats require unsafe.
+	_, err = cdc.JSONSchema(new(float64))
+	assert.Error(t, err)
+	_, err = cdc.JSONSchema(Doc{})
+	assert.Error(t, err)
+}
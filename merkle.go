@@ -0,0 +1,397 @@
+package amino
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+//----------------------------------------
+// Merkle trees
+
+// MerkleRoot returns the root hash of the Merkle tree of o, which must be a
+// struct or list, so that the value of any field nested in o can be proven
+// by ProveField without the rest of o.
+//
+// The leaves of a struct are its fields in order, excluding `amino:"-binary"`
+// fields, each the field's BinFieldNum as a uvarint, its JSON name as by
+// EncodeString, and the digest of its value.  The leaves of a list are its
+// elements, each its index as a uvarint and the digest of its value.  The
+// digest of a struct or list value (other than time or one with MarshalAmino)
+// is its own root, and of any other value is the SHA256 hash of its binary
+// encoding, as returned by ProveField, with the prefix 0x02.  Nil pointers are
+// as their default values, except for `amino:"optional"` fields, whose
+// encoding is then empty.
+//
+// The tree over the leaves is that of RFC 6962, as used by Tendermint:
+// leaves are hashed with the prefix 0x00, inner nodes with 0x01, and the
+// left subtree of n leaves has the largest power of 2 less than n of them.
+// The root of no leaves is the SHA256 hash of nothing.  The prefix 0x02 of
+// values keeps a value from having the digest of a subtree, else the inner
+// node of a struct could be "proven" as the value of that struct's field.
+func (cdc *Codec) MerkleRoot(o interface{}) ([]byte, error) {
+	node, err := cdc.merkleRootNode(o)
+	if err != nil {
+		return nil, err
+	}
+	return cdc.merkleDigest(node)
+}
+
+// FieldProof proves the value of the field at a path in a Merkle tree, see
+// ProveField.
+type FieldProof struct {
+	Steps []FieldProofStep `json:"steps"` // One per step of the path.
+}
+
+// FieldProofStep proves the digest of a struct or list from that of one of
+// its fields or elements.
+type FieldProofStep struct {
+	FieldNum uint32   `json:"field_num"` // The BinFieldNum of a field, 0 for an element.
+	Index    int      `json:"index"`     // Of the leaf.
+	Total    int      `json:"total"`     // The number of leaves.
+	Aunts    [][]byte `json:"aunts"`     // The sibling hashes from the leaf up.
+}
+
+// ProveField returns the binary encoding of the value at path in o (see
+// ParsePath) and its proof against MerkleRoot(o), for VerifyFieldProof.  The
+// value must not itself be a struct or list, but its fields or elements can
+// be proven.
+func (cdc *Codec) ProveField(o interface{}, path string) (value []byte, proof *FieldProof, err error) {
+	steps, err := ParsePath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	node, err := cdc.merkleRootNode(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof = &FieldProof{Steps: make([]FieldProofStep, 0, len(steps))}
+	for i, step := range steps {
+		if !isMerkleNode(node.info) {
+			return nil, nil, fmt.Errorf("%v is not a struct or list", steps[:i].describe())
+		}
+		children, err := cdc.merkleChildren(node)
+		if err != nil {
+			return nil, nil, err
+		}
+		var index = -1
+		if step.Name == "" {
+			if node.info.Type.Kind() == reflect.Struct {
+				return nil, nil, fmt.Errorf("%v is a struct, not a list", steps[:i].describe())
+			}
+			if step.Index < len(children) {
+				index = step.Index
+			}
+		} else {
+			if node.info.Type.Kind() != reflect.Struct {
+				return nil, nil, fmt.Errorf("%v is a list, not a struct", steps[:i].describe())
+			}
+			for j, child := range children {
+				if child.name == step.Name {
+					index = j
+				}
+			}
+		}
+		if index < 0 {
+			return nil, nil, fmt.Errorf("%v does not exist", steps[:i+1].describe())
+		}
+
+		leaves, err := cdc.merkleLeaves(children)
+		if err != nil {
+			return nil, nil, err
+		}
+		proof.Steps = append(proof.Steps, FieldProofStep{
+			FieldNum: children[index].fieldNum,
+			Index:    index,
+			Total:    len(leaves),
+			Aunts:    merkleAunts(leaves, index),
+		})
+		node = children[index]
+	}
+	if isMerkleNode(node.info) {
+		return nil, nil, fmt.Errorf("%v is a struct or list, prove its fields or elements instead", steps.describe())
+	}
+	value, err = cdc.merkleValue(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, proof, nil
+}
+
+// VerifyFieldProof checks that the value at path (see ParsePath) in the value
+// of Merkle root root is value, as proven by proof (see ProveField).
+func VerifyFieldProof(root []byte, path string, value []byte, proof *FieldProof) error {
+	steps, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	if proof == nil {
+		return errors.New("nil proof")
+	}
+	if len(proof.Steps) != len(steps) {
+		return fmt.Errorf("proof has %v steps but path %v has %v", len(proof.Steps), steps.describe(), len(steps))
+	}
+	var digest = merkleValueHash(value)
+	for i := len(steps) - 1; i >= 0; i-- {
+		var step, pstep = steps[i], proof.Steps[i]
+		var key []byte
+		if step.Name == "" {
+			if pstep.Index != step.Index {
+				return fmt.Errorf("proof is of element %v of %v, not %v", pstep.Index, steps[:i].describe(), step.Index)
+			}
+			key = merkleElementKey(step.Index)
+		} else {
+			key = merkleFieldKey(pstep.FieldNum, step.Name)
+		}
+		digest = merkleRootFromAunts(merkleLeafHash(key, digest), pstep.Index, pstep.Total, pstep.Aunts)
+		if digest == nil {
+			return fmt.Errorf("invalid proof of %v", steps[:i+1].describe())
+		}
+	}
+	if !bytes.Equal(digest, root) {
+		return errors.New("proof does not match root")
+	}
+	return nil
+}
+
+//----------------------------------------
+// Misc.
+
+// A value in a Merkle tree: the root, or a field or element of a struct or
+// list in it.
+type merkleNode struct {
+	info     *TypeInfo
+	rv       reflect.Value // Possibly a (nil) pointer.
+	fopts    FieldOptions
+	name     string // The JSON name, if a field.
+	fieldNum uint32 // The BinFieldNum, if a field.
+	key      []byte // Of the leaf, if a field or element.
+}
+
+func (cdc *Codec) merkleRootNode(o interface{}) (node merkleNode, err error) {
+	var rv, _, isNilPtr = derefPointers(reflect.ValueOf(o))
+	if isNilPtr {
+		err = errors.New("MerkleRoot cannot hash a nil pointer directly. Try wrapping in a struct?")
+		return
+	}
+	info, err := cdc.getTypeInfoWlock(rv.Type())
+	if err != nil {
+		return
+	}
+	if !isMerkleNode(info) {
+		err = fmt.Errorf("MerkleRoot requires a struct or list, not %v", rv.Type())
+		return
+	}
+	return merkleNode{info: info, rv: rv}, nil
+}
+
+// Whether values of the type have Merkle trees of their own.
+func isMerkleNode(info *TypeInfo) bool {
+	if info.IsAminoMarshaler {
+		return false
+	}
+	switch info.Type.Kind() {
+	case reflect.Struct:
+		return info.Type != timeType
+	case reflect.Array, reflect.Slice:
+		return info.Type.Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// Returns the fields or elements of a struct or list node.
+func (cdc *Codec) merkleChildren(node merkleNode) (children []merkleNode, err error) {
+	var rv, _, _ = derefPointersZero(node.rv)
+	if rv.Kind() == reflect.Struct {
+		for _, field := range node.info.Fields {
+			if field.BinSkip {
+				continue // e.g. amino:"-binary"
+			}
+			var finfo *TypeInfo
+			finfo, err = cdc.getTypeInfoWlock(field.Type)
+			if err != nil {
+				return
+			}
+			children = append(children, merkleNode{
+				info:     finfo,
+				rv:       rv.Field(field.Index),
+				fopts:    field.FieldOptions,
+				name:     field.JSONName,
+				fieldNum: field.BinFieldNum,
+				key:      merkleFieldKey(field.BinFieldNum, field.JSONName),
+			})
+		}
+		return
+	}
+	einfo, err := cdc.getTypeInfoWlock(node.info.Type.Elem())
+	if err != nil {
+		return
+	}
+	for i := 0; i < rv.Len(); i++ {
+		children = append(children, merkleNode{
+			info:  einfo,
+			rv:    rv.Index(i),
+			fopts: node.fopts, // Elements are encoded with the list's options.
+			key:   merkleElementKey(i),
+		})
+	}
+	return
+}
+
+// Returns the hashes of the leaves of children.
+func (cdc *Codec) merkleLeaves(children []merkleNode) ([][]byte, error) {
+	var leaves = make([][]byte, len(children))
+	for i, child := range children {
+		digest, err := cdc.merkleDigest(child)
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = merkleLeafHash(child.key, digest)
+	}
+	return leaves, nil
+}
+
+func (cdc *Codec) merkleDigest(node merkleNode) ([]byte, error) {
+	if !isMerkleNode(node.info) {
+		value, err := cdc.merkleValue(node)
+		if err != nil {
+			return nil, err
+		}
+		return merkleValueHash(value), nil
+	}
+	children, err := cdc.merkleChildren(node)
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := cdc.merkleLeaves(children)
+	if err != nil {
+		return nil, err
+	}
+	return merkleRoot(leaves), nil
+}
+
+// Returns the binary encoding of a node that isn't a struct or list.
+func (cdc *Codec) merkleValue(node merkleNode) ([]byte, error) {
+	var rv, _, isNilPtr = derefPointers(node.rv)
+	if isNilPtr {
+		if node.fopts.Optional {
+			return nil, nil
+		}
+		rv, _, _ = derefPointersZero(defaultValue(node.rv.Type()))
+	}
+	var buf = new(bytes.Buffer)
+	err := cdc.encodeReflectBinary(buf, node.info, rv, node.fopts, true)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func merkleFieldKey(fieldNum uint32, name string) []byte {
+	var buf = new(bytes.Buffer)
+	_ = EncodeUvarint(buf, uint64(fieldNum))
+	_ = EncodeString(buf, name)
+	return buf.Bytes()
+}
+
+func merkleElementKey(index int) []byte {
+	var buf = new(bytes.Buffer)
+	_ = EncodeUvarint(buf, uint64(index))
+	return buf.Bytes()
+}
+
+func sha256Sum(bz []byte) []byte {
+	sum := sha256.Sum256(bz)
+	return sum[:]
+}
+
+func merkleValueHash(value []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x02})
+	h.Write(value)
+	return h.Sum(nil)
+}
+
+func merkleLeafHash(key, digest []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(key)
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+func merkleInnerHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Returns the largest power of 2 less than n, for n > 1.
+func merkleSplitPoint(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+func merkleRoot(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return sha256Sum(nil)
+	case 1:
+		return leaves[0]
+	default:
+		k := merkleSplitPoint(len(leaves))
+		return merkleInnerHash(merkleRoot(leaves[:k]), merkleRoot(leaves[k:]))
+	}
+}
+
+// Returns the sibling hashes of the leaf at index, from the leaf up.
+func merkleAunts(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return nil
+	}
+	k := merkleSplitPoint(len(leaves))
+	if index < k {
+		return append(merkleAunts(leaves[:k], index), merkleRoot(leaves[k:]))
+	}
+	return append(merkleAunts(leaves[k:], index-k), merkleRoot(leaves[:k]))
+}
+
+// Returns the root from the hash of the leaf at index of total and its aunts,
+// or nil if they're inconsistent.
+func merkleRootFromAunts(leaf []byte, index, total int, aunts [][]byte) []byte {
+	if index < 0 || index >= total {
+		return nil
+	}
+	if total == 1 {
+		if len(aunts) != 0 {
+			return nil
+		}
+		return leaf
+	}
+	if len(aunts) == 0 {
+		return nil
+	}
+	var last = len(aunts) - 1
+	k := merkleSplitPoint(total)
+	if index < k {
+		left := merkleRootFromAunts(leaf, index, k, aunts[:last])
+		if left == nil {
+			return nil
+		}
+		return merkleInnerHash(left, aunts[last])
+	}
+	right := merkleRootFromAunts(leaf, index-k, total-k, aunts[:last])
+	if right == nil {
+		return nil
+	}
+	return merkleInnerHash(aunts[last], right)
+}
@@ -0,0 +1,174 @@
+package amino_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+type merkleHeader struct {
+	ChainID string `json:"chain_id"`
+	Height  int64  `json:"height"`
+	Time    time.Time
+	Hashes  [][]byte
+	Parts   []merklePart `json:"parts"`
+	Last    *merklePart
+	Matrix  [][]int8
+	Vehicle Vehicle
+	Secret  string `amino:"-binary"`
+}
+
+type merklePart struct {
+	Total int    `json:"total"`
+	Hash  []byte `json:"hash"`
+}
+
+func TestMerkleRoot(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+
+	// A single leaf is the root.
+	root, err := cdc.MerkleRoot(struct{ A int8 }{5})
+	require.NoError(t, err)
+	value := sha256.Sum256([]byte{0x02, 0x0a}) // Varint 5.
+	leaf := sha256.Sum256(append([]byte{0x00, 0x01, 0x01, 'A'}, value[:]...))
+	assert.Equal(t, leaf[:], root)
+
+	// No leaves.
+	root, err = cdc.MerkleRoot([]int8{})
+	require.NoError(t, err)
+	empty := sha256.Sum256(nil)
+	assert.Equal(t, empty[:], root)
+
+	// Equal values give equal roots.
+	root1, err := cdc.MerkleRoot(merkleHeader{})
+	require.NoError(t, err)
+	root2, err := cdc.MerkleRoot(&merkleHeader{Hashes: [][]byte{}, Last: &merklePart{}, Secret: "ignored"})
+	require.NoError(t, err)
+	assert.Equal(t, root1, root2)
+	root2, err = cdc.MerkleRoot(merkleHeader{Height: 1})
+	require.NoError(t, err)
+	assert.NotEqual(t, root1, root2)
+
+	_, err = cdc.MerkleRoot(int8(5))
+	assert.EqualError(t, err, "MerkleRoot requires a struct or list, not int8")
+	_, err = cdc.MerkleRoot((*merkleHeader)(nil))
+	assert.Error(t, err)
+}
+
+func TestProveField(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+
+	var header = merkleHeader{
+		ChainID: "test-chain",
+		Height:  1234,
+		Time:    time.Unix(1e9, 0).UTC(),
+		Hashes:  [][]byte{{1}, {2}, {3}, nil, {5}},
+		Parts:   []merklePart{{1, []byte{0xAA}}, {2, nil}, {3, []byte{0xCC}}},
+		Matrix:  [][]int8{{1, 2, 3}, nil},
+		Vehicle: Car("Tesla"),
+	}
+	root, err := cdc.MerkleRoot(header)
+	require.NoError(t, err)
+
+	cases := []struct {
+		path  string
+		value []byte
+	}{
+		{"chain_id", encoded(func(w io.Writer) error { return amino.EncodeString(w, "test-chain") })},
+		{"height", encoded(func(w io.Writer) error { return amino.EncodeUvarint(w, 1234) })},
+		{"Time", cdc.MustMarshalBinaryBare(header.Time)},
+		{"Hashes[2]", encoded(func(w io.Writer) error { return amino.EncodeByteSlice(w, []byte{3}) })},
+		{"Hashes[3]", []byte{0x00}},
+		{"parts[0].hash", encoded(func(w io.Writer) error { return amino.EncodeByteSlice(w, []byte{0xAA}) })},
+		{"parts[2].total", []byte{0x03}},
+		{"Last.total", []byte{0x00}},
+		{"Matrix[0][1]", encoded(func(w io.Writer) error { return amino.EncodeInt8(w, 2) })},
+		{"Vehicle", cdc.MustMarshalBinaryBare(struct{ Vehicle }{Car("Tesla")})[2:]},
+	}
+	for _, tc := range cases {
+		value, proof, err := cdc.ProveField(header, tc.path)
+		require.NoError(t, err, tc.path)
+		assert.Equal(t, tc.value, value, tc.path)
+		assert.NoError(t, amino.VerifyFieldProof(root, tc.path, value, proof), tc.path)
+
+		// Tampered values and roots don't verify.
+		assert.EqualError(t, amino.VerifyFieldProof(root, tc.path, append(value, 0x00), proof),
+			"proof does not match root", tc.path)
+		assert.EqualError(t, amino.VerifyFieldProof(bytes.Repeat([]byte{0x00}, 32), tc.path, value, proof),
+			"proof does not match root", tc.path)
+	}
+
+	// Proofs are of their own path only.
+	value, proof, err := cdc.ProveField(header, "parts[0].total")
+	require.NoError(t, err)
+	assert.EqualError(t, amino.VerifyFieldProof(root, "parts[1].total", value, proof),
+		"proof is of element 0 of parts, not 1")
+	assert.EqualError(t, amino.VerifyFieldProof(root, "parts[0].hash", value, proof),
+		"proof does not match root")
+	assert.EqualError(t, amino.VerifyFieldProof(root, "parts[0]", value, proof),
+		"proof has 3 steps but path parts[0] has 2")
+	assert.EqualError(t, amino.VerifyFieldProof(root, "", value, proof),
+		"proof has 3 steps but path the root has 0")
+	assert.EqualError(t, amino.VerifyFieldProof(root, "parts[0].total", value, nil), "nil proof")
+	proof.Steps[1].Aunts = proof.Steps[1].Aunts[1:]
+	assert.EqualError(t, amino.VerifyFieldProof(root, "parts[0].total", value, proof),
+		"invalid proof of parts[0]")
+
+	for path, want := range map[string]string{
+		"nope":           "nope does not exist",
+		"Secret":         "Secret does not exist",
+		"parts[3]":       "parts[3] does not exist",
+		"[0]":            "the root is a struct, not a list",
+		"parts.total":    "parts is a list, not a struct",
+		"height.low":     "height is not a struct or list",
+		"parts[0]":       "parts[0] is a struct or list, prove its fields or elements instead",
+		"parts[0]..hash": `invalid path "parts[0]..hash": empty field name at 9`,
+	} {
+		_, _, err := cdc.ProveField(header, path)
+		assert.EqualError(t, err, want, path)
+	}
+}
+
+func TestVerifyFieldProofForgery(t *testing.T) {
+	type Inner struct {
+		A int8 `json:"a"`
+		B int8 `json:"b"`
+	}
+	type Outer struct {
+		Inner Inner `json:"inner"`
+		X     int8  `json:"x"`
+	}
+	var cdc = amino.NewCodec()
+	var outer = Outer{Inner{1, 2}, 3}
+	root, err := cdc.MerkleRoot(outer)
+	require.NoError(t, err)
+	value, proof, err := cdc.ProveField(outer, "inner.a")
+	require.NoError(t, err)
+	require.NoError(t, amino.VerifyFieldProof(root, "inner.a", value, proof))
+
+	// The inner node of Inner, 0x01‖leaf(a)‖leaf(b), isn't a value of inner.
+	_, proofB, err := cdc.ProveField(outer, "inner.b")
+	require.NoError(t, err)
+	leafA, leafB := proofB.Steps[1].Aunts[0], proof.Steps[1].Aunts[0]
+	forged := append(append([]byte{0x01}, leafA...), leafB...)
+	forgedProof := &amino.FieldProof{Steps: proof.Steps[:1]}
+	assert.EqualError(t, amino.VerifyFieldProof(root, "inner", forged, forgedProof),
+		"proof does not match root")
+}
+
+func encoded(encode func(w io.Writer) error) []byte {
+	var buf = new(bytes.Buffer)
+	if err := encode(buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,483 @@
+package amino
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//----------------------------------------
+// JSON Merge Patch & JSON Patch
+
+// ApplyMergePatch applies patch, a JSON merge patch (RFC 7386), to the amino
+// JSON of the value ptr points to, but for the type wrapper of the value
+// itself, if it is registered (as GetPath).
+//
+// The patch is applied by the types of the values patched, so values are
+// decoded as amino JSON of their own types, and a null member sets a struct
+// field to its zero value.  An object patching an interface value has the
+// members "type" and "value": if the type is another registered name, the
+// value is first set to the zero value of that concrete type.
+//
+// The patch is applied to a deep copy, so fields that aren't in the amino
+// JSON, e.g. `amino:"-json"` or unexported ones, are kept.  The result is
+// validated by encoding and decoding it again.  If anything fails, the value
+// is left as it was.
+func (cdc *Codec) ApplyMergePatch(ptr interface{}, patch []byte) error {
+	return cdc.applyPatch(ptr, func(rv reflect.Value) error {
+		return cdc.mergePatch(rv, FieldOptions{}, patch, nil)
+	})
+}
+
+// ApplyJSONPatch applies ops, a JSON patch (RFC 6902) as a JSON array of
+// operations, to the amino JSON of the value ptr points to, as
+// ApplyMergePatch.  Its paths are JSON pointers (RFC 6901) into the amino
+// JSON, e.g. "/msgs/0/value/amount", where interface values have the members
+// "type" and "value".  Replacing the "type" of an interface value with
+// another registered name sets it to the zero value of that concrete type.
+//
+// "add" inserts into lists (or appends, at index "-"), but otherwise sets the
+// value as "replace" does, which requires it to exist.  "remove" removes from
+// lists and maps, but sets struct fields to their zero values.  "test"
+// compares values as Equal, after decoding the given value.
+func (cdc *Codec) ApplyJSONPatch(ptr interface{}, ops []byte) error {
+	var patch []jsonPatchOp
+	if err := json.Unmarshal(ops, &patch); err != nil {
+		return errors.Wrap(err, "invalid JSON patch")
+	}
+	return cdc.applyPatch(ptr, func(rv reflect.Value) error {
+		for i, op := range patch {
+			if err := cdc.applyJSONPatchOp(rv, op); err != nil {
+				return errors.Wrapf(err, "op %v (%v %v)", i, op.Op, op.Path)
+			}
+		}
+		return nil
+	})
+}
+
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Applies a patch to a copy of the value ptr points to, and sets it to the
+// result if valid.
+func (cdc *Codec) applyPatch(ptr interface{}, apply func(rv reflect.Value) error) error {
+	var rv = reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("expected a non-nil pointer")
+	}
+	var rt = rv.Elem().Type()
+
+	// Patch a copy, so that the value is left as is on error.
+	var cpy = reflect.New(rt)
+	err := DeepCopyOptions{CopyUnexported: true}.DeepCopyInto(cpy.Interface(), ptr)
+	if err != nil {
+		return err
+	}
+	if err = apply(cpy.Elem()); err != nil {
+		return err
+	}
+
+	// Validate the result by encoding and decoding it.
+	bz, err := cdc.MarshalJSON(cpy.Elem().Interface())
+	if err != nil {
+		return errors.Wrap(err, "invalid patched value")
+	}
+	if err = cdc.UnmarshalJSON(bz, reflect.New(rt).Interface()); err != nil {
+		return errors.Wrap(err, "invalid patched value")
+	}
+	rv.Elem().Set(cpy.Elem())
+	return nil
+}
+
+//----------------------------------------
+// Merge patch
+
+func (cdc *Codec) mergePatch(rv reflect.Value, fopts FieldOptions, patch []byte, path Path) error {
+	patch = bytes.TrimSpace(patch)
+	if len(patch) == 0 || patch[0] != '{' {
+		// Replace the value.
+		return cdc.decodeJSONInto(rv, fopts, patch)
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return err
+	}
+
+	// Dereference pointers, constructing them.
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Interface {
+		for name := range members {
+			if name != "type" && name != "value" {
+				return fmt.Errorf("%v does not exist: %v is an interface value, of a type and value",
+					path.child(PathStep{Name: name}), path.describe())
+			}
+		}
+		if typ, ok := members["type"]; ok {
+			err := cdc.atInterfaceType(rv, path.child(PathStep{Name: "type"}), true,
+				func(name reflect.Value, fopts FieldOptions) error {
+					return cdc.decodeJSONInto(name, fopts, typ)
+				})
+			if err != nil {
+				return err
+			}
+		}
+		if value, ok := members["value"]; ok {
+			if rv.IsNil() {
+				return fmt.Errorf("cannot patch %v: it is nil, patch its type too", path.describe())
+			}
+			var cpy = reflect.New(rv.Elem().Type()).Elem()
+			cpy.Set(rv.Elem())
+			if err := cdc.mergePatch(cpy, FieldOptions{}, value, path.child(PathStep{Name: "value"})); err != nil {
+				return err
+			}
+			rv.Set(cpy)
+		}
+		return nil
+	}
+
+	info, err := cdc.getTypeInfoWlock(rv.Type())
+	if err != nil {
+		return err
+	}
+	if info.IsAminoMarshaler {
+		return cdc.withRepr(rv, info, true, func(repr reflect.Value) error {
+			return cdc.mergePatch(repr, fopts, patch, path)
+		})
+	}
+
+	var names = make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch {
+	case rv.Kind() == reflect.Map:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		for _, name := range names {
+			var next = path.child(PathStep{Name: name})
+			key, err := mapKey(rv, PathStep{Name: name})
+			if err != nil {
+				return fmt.Errorf("cannot patch %v: %v", next, err)
+			}
+			if isJSONNull(members[name]) {
+				rv.SetMapIndex(key, reflect.Value{})
+				continue
+			}
+			var cpy = reflect.New(rv.Type().Elem()).Elem()
+			if val := rv.MapIndex(key); val.IsValid() {
+				cpy.Set(val)
+			}
+			if err = cdc.mergePatch(cpy, fopts, members[name], next); err != nil {
+				return err
+			}
+			rv.SetMapIndex(key, cpy)
+		}
+		return nil
+
+	case rv.Kind() == reflect.Struct && rv.Type() != timeType:
+		for _, name := range names {
+			var next = path.child(PathStep{Name: name})
+			field, ok := jsonField(info, name)
+			if !ok {
+				return fmt.Errorf("%v does not exist: %v has no field %v", next, rv.Type(), name)
+			}
+			var frv = rv.Field(field.Index)
+			if isJSONNull(members[name]) {
+				frv.Set(reflect.Zero(frv.Type()))
+				continue
+			}
+			if err = cdc.mergePatch(frv, field.FieldOptions, members[name], next); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		// Replace the value with the patch, without its null members.
+		for name, member := range members {
+			if isJSONNull(member) {
+				delete(members, name)
+			}
+		}
+		bz, err := json.Marshal(members)
+		if err != nil {
+			return err
+		}
+		return cdc.decodeJSONInto(rv, fopts, bz)
+	}
+}
+
+func isJSONNull(bz []byte) bool {
+	return string(bytes.TrimSpace(bz)) == "null"
+}
+
+// Decodes bz, amino JSON of the type of rv, into rv, which is settable.
+func (cdc *Codec) decodeJSONInto(rv reflect.Value, fopts FieldOptions, bz []byte) error {
+	if len(bz) == 0 {
+		return errors.New("cannot decode empty bytes")
+	}
+	info, err := cdc.getTypeInfoWlock(rv.Type())
+	if err != nil {
+		return err
+	}
+	return cdc.decodeReflectJSON(bz, info, rv, fopts, false)
+}
+
+// Encodes rv, a value of the type of rv, as amino JSON.
+func (cdc *Codec) encodeJSONOf(rv reflect.Value, fopts FieldOptions) ([]byte, error) {
+	info, err := cdc.getTypeInfoWlock(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	var buf = new(bytes.Buffer)
+	if err = cdc.encodeReflectJSON(buf, info, rv, fopts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//----------------------------------------
+// JSON patch
+
+func (cdc *Codec) applyJSONPatchOp(rv reflect.Value, op jsonPatchOp) error {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case "add":
+		return cdc.patchAdd(rv, path, op.Value)
+
+	case "remove":
+		return cdc.patchRemove(rv, path)
+
+	case "replace":
+		return cdc.patchReplace(rv, path, op.Value)
+
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" && strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return fmt.Errorf("cannot move %v into itself", op.From)
+		}
+		value, err := cdc.patchGet(rv, from)
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" {
+			if err = cdc.patchRemove(rv, from); err != nil {
+				return err
+			}
+		}
+		return cdc.patchAdd(rv, path, value)
+
+	case "test":
+		var equal bool
+		err := cdc.atPath(rv, FieldOptions{}, path, nil, false, func(rv reflect.Value, fopts FieldOptions) error {
+			var want = reflect.New(rv.Type()).Elem()
+			if err := cdc.decodeJSONInto(want, fopts, op.Value); err != nil {
+				return err
+			}
+			equal = Equal(rv.Interface(), want.Interface())
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return fmt.Errorf("test failed: %v is not %s", path.describe(), op.Value)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+// Returns the amino JSON of the value at path.
+func (cdc *Codec) patchGet(rv reflect.Value, path Path) (value []byte, err error) {
+	err = cdc.atPath(rv, FieldOptions{}, path, nil, false, func(rv reflect.Value, fopts FieldOptions) error {
+		value, err = cdc.encodeJSONOf(rv, fopts)
+		return err
+	})
+	return
+}
+
+func (cdc *Codec) patchReplace(rv reflect.Value, path Path, value []byte) error {
+	// The value must exist.
+	if err := cdc.atPath(rv, FieldOptions{}, path, nil, false, func(reflect.Value, FieldOptions) error {
+		return nil
+	}); err != nil {
+		return err
+	}
+	return cdc.atPath(rv, FieldOptions{}, path, nil, true, func(rv reflect.Value, fopts FieldOptions) error {
+		return cdc.decodeJSONInto(rv, fopts, value)
+	})
+}
+
+func (cdc *Codec) patchAdd(rv reflect.Value, path Path, value []byte) error {
+	if len(path) == 0 {
+		return cdc.decodeJSONInto(rv, FieldOptions{}, value)
+	}
+	var last = path[len(path)-1]
+	var added bool
+	err := cdc.atContainer(rv, path, func(crv reflect.Value, fopts FieldOptions) error {
+		switch {
+		case crv.Kind() == reflect.Slice && crv.Type().Elem().Kind() != reflect.Uint8 && last.Name == "":
+			// Insert into the list, or append at "-".
+			var index = last.Index
+			if index < 0 {
+				index = crv.Len()
+			}
+			if index > crv.Len() {
+				return fmt.Errorf("%v does not exist: %v has %v elements", path, path[:len(path)-1].describe(), crv.Len())
+			}
+			var elem = reflect.New(crv.Type().Elem()).Elem()
+			if err := cdc.decodeJSONInto(elem, fopts, value); err != nil {
+				return err
+			}
+			var list = reflect.MakeSlice(crv.Type(), 0, crv.Len()+1)
+			list = reflect.AppendSlice(list, crv.Slice(0, index))
+			list = reflect.Append(list, elem)
+			list = reflect.AppendSlice(list, crv.Slice(index, crv.Len()))
+			crv.Set(list)
+			added = true
+
+		case crv.Kind() == reflect.Map:
+			key, err := mapKey(crv, last)
+			if err != nil {
+				return fmt.Errorf("cannot add %v: %v", path, err)
+			}
+			var elem = reflect.New(crv.Type().Elem()).Elem()
+			if err = cdc.decodeJSONInto(elem, fopts, value); err != nil {
+				return err
+			}
+			if crv.IsNil() {
+				crv.Set(reflect.MakeMap(crv.Type()))
+			}
+			crv.SetMapIndex(key, elem)
+			added = true
+		}
+		return nil
+	})
+	if err != nil || added {
+		return err
+	}
+	// Other values can only be replaced.
+	return cdc.patchReplace(rv, path, value)
+}
+
+func (cdc *Codec) patchRemove(rv reflect.Value, path Path) error {
+	if len(path) == 0 {
+		return errors.New("cannot remove the root")
+	}
+	var last = path[len(path)-1]
+	var removed bool
+	err := cdc.atContainer(rv, path, func(crv reflect.Value, _ FieldOptions) error {
+		switch {
+		case crv.Kind() == reflect.Slice && crv.Type().Elem().Kind() != reflect.Uint8:
+			if last.Name != "" || last.Index < 0 || last.Index >= crv.Len() {
+				return nil // Fails below, as it doesn't exist.
+			}
+			var list = reflect.MakeSlice(crv.Type(), 0, crv.Len()-1)
+			list = reflect.AppendSlice(list, crv.Slice(0, last.Index))
+			list = reflect.AppendSlice(list, crv.Slice(last.Index+1, crv.Len()))
+			crv.Set(list)
+			removed = true
+
+		case crv.Kind() == reflect.Map:
+			key, err := mapKey(crv, last)
+			if err != nil || !crv.MapIndex(key).IsValid() {
+				return nil // Fails below, as it doesn't exist.
+			}
+			crv.SetMapIndex(key, reflect.Value{})
+			removed = true
+
+		case crv.Kind() == reflect.Interface && (last.Name == "type" || last.Name == "value"):
+			return fmt.Errorf("cannot remove %v: an interface value has both a type and value", path)
+		}
+		return nil
+	})
+	if err != nil || removed {
+		return err
+	}
+	// Set other values to zero, if they exist.
+	return cdc.patchReplace(rv, path, []byte("null"))
+}
+
+// Calls fn with the dereferenced value that contains the value at path, or
+// for a value with .MarshalAmino(), its repr, which is set back.
+func (cdc *Codec) atContainer(rv reflect.Value, path Path, fn func(crv reflect.Value, fopts FieldOptions) error) error {
+	var done = path[:len(path)-1]
+	return cdc.atPath(rv, FieldOptions{}, done, nil, true, func(crv reflect.Value, fopts FieldOptions) error {
+		for crv.Kind() == reflect.Ptr {
+			if crv.IsNil() {
+				crv.Set(reflect.New(crv.Type().Elem()))
+			}
+			crv = crv.Elem()
+		}
+		if crv.Kind() != reflect.Interface {
+			info, err := cdc.getTypeInfoWlock(crv.Type())
+			if err != nil {
+				return err
+			}
+			if info.IsAminoMarshaler {
+				return cdc.withRepr(crv, info, true, func(repr reflect.Value) error {
+					return fn(repr, fopts)
+				})
+			}
+		}
+		return fn(crv, fopts)
+	})
+}
+
+var jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// Parses a JSON pointer (RFC 6901) as a Path.  Numeric tokens are list
+// indices (or map keys), and "-" is the index -1, past the end of a list.
+func parseJSONPointer(s string) (Path, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", s)
+	}
+	var path Path
+	for _, token := range strings.Split(s[1:], "/") {
+		token = jsonPointerUnescaper.Replace(token)
+		if token == "-" {
+			path = append(path, PathStep{Index: -1})
+			continue
+		}
+		if index, err := strconv.Atoi(token); err == nil && index >= 0 && strconv.Itoa(index) == token {
+			path = append(path, PathStep{Index: index})
+			continue
+		}
+		if token == "" {
+			return nil, fmt.Errorf("invalid JSON pointer %q: empty token", s)
+		}
+		path = append(path, PathStep{Name: token})
+	}
+	return path, nil
+}
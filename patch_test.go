@@ -0,0 +1,126 @@
+package amino_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPatchTx() pathTx {
+	return pathTx{
+		Msgs: []Vehicle{
+			pathSend{To: "b", Amount: []pathCoin{{"stake", 1}, {"uatom", 2}}},
+			Car("Tesla"),
+		},
+		Memo: walkRepr{"a b"},
+		Tags: map[string]string{"k": "v", "0": "zero"},
+		Note: "kept",
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	var cdc = newPathCodec()
+	var tx = newPatchTx()
+
+	require.NoError(t, cdc.ApplyMergePatch(&tx, []byte(`{
+		"msgs": [
+			{"type": "path/send", "value": {"to": "c", "amount": [{"denom": "stake", "amount": "5"}]}},
+			{"type": "boat", "value": "Poseidon"}
+		],
+		"fee": {"denom": "uatom", "amount": "3"},
+		"memo": ["x", "y"],
+		"tags": {"k": null, "n": "new"}
+	}`)))
+	assert.Equal(t, []Vehicle{
+		pathSend{To: "c", Amount: []pathCoin{{"stake", 5}}},
+		Boat("Poseidon"),
+	}, tx.Msgs)
+	assert.Equal(t, &pathCoin{"uatom", 3}, tx.Fee)
+	assert.Equal(t, walkRepr{"x y"}, tx.Memo)
+	assert.Equal(t, map[string]string{"0": "zero", "n": "new"}, tx.Tags)
+	assert.Equal(t, "kept", tx.Note, "fields not in the JSON are kept")
+
+	// Objects patch the values within, and null zeroes fields.
+	require.NoError(t, cdc.ApplyMergePatch(&tx, []byte(`{"fee": {"amount": "4"}, "memo": null}`)))
+	assert.Equal(t, &pathCoin{"uatom", 4}, tx.Fee)
+	assert.Equal(t, walkRepr{}, tx.Memo)
+
+	// Failures leave the value as it was.
+	var before = tx
+	assert.EqualError(t, cdc.ApplyMergePatch(&tx, []byte(`{"fee": {"amount": "5"}, "from": "a"}`)),
+		"from does not exist: amino_test.pathTx has no field from")
+	assert.Error(t, cdc.ApplyMergePatch(&tx, []byte(`{"msgs": [{"type": "car"}]}`))) // Lists are replaced.
+	assert.Error(t, cdc.ApplyMergePatch(&tx, []byte(`{"fee": {"amount": 5}}`)))
+	assert.Error(t, cdc.ApplyMergePatch(&tx, []byte(`{"fee"`)))
+	assert.Equal(t, before, tx)
+}
+
+func TestApplyMergePatchInterface(t *testing.T) {
+	var cdc = newPathCodec()
+	var v Vehicle = pathSend{To: "b", Amount: []pathCoin{{"stake", 1}}}
+
+	// The registered root is patched without its type wrapper, as GetPath.
+	require.NoError(t, cdc.ApplyMergePatch(&v, []byte(`{"value": {"to": "c"}}`)))
+	assert.Equal(t, pathSend{To: "c", Amount: []pathCoin{{"stake", 1}}}, v)
+
+	// Patching the type switches to the zero value of the concrete type.
+	require.NoError(t, cdc.ApplyMergePatch(&v, []byte(`{"type": "car"}`)))
+	assert.Equal(t, Car(""), v)
+	require.NoError(t, cdc.ApplyMergePatch(&v, []byte(`{"type": "boat", "value": "Poseidon"}`)))
+	assert.Equal(t, Boat("Poseidon"), v)
+
+	assert.EqualError(t, cdc.ApplyMergePatch(&v, []byte(`{"type": "insuranceplan"}`)),
+		"cannot set type: amino_test.insurancePlan does not implement amino_test.Vehicle")
+	assert.EqualError(t, cdc.ApplyMergePatch(&v, []byte(`{"name": "x"}`)),
+		"name does not exist: the root is an interface value, of a type and value")
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	var cdc = newPathCodec()
+	var tx = newPatchTx()
+
+	require.NoError(t, cdc.ApplyJSONPatch(&tx, []byte(`[
+		{"op": "test", "path": "/msgs/0/value/amount/1", "value": {"denom": "uatom", "amount": "2"}},
+		{"op": "replace", "path": "/msgs/0/value/amount/1/amount", "value": "7"},
+		{"op": "add", "path": "/msgs/0/value/amount/0", "value": {"denom": "atom", "amount": "9"}},
+		{"op": "add", "path": "/msgs/0/value/amount/-", "value": {"denom": "photon", "amount": "1"}},
+		{"op": "remove", "path": "/msgs/0/value/amount/1"},
+		{"op": "replace", "path": "/msgs/1/type", "value": "boat"},
+		{"op": "add", "path": "/msgs/1/value", "value": "Poseidon"},
+		{"op": "copy", "from": "/msgs/0/value/amount/0", "path": "/fee"},
+		{"op": "move", "from": "/tags/k", "path": "/tags/a~1b"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "add", "path": "/memo/1", "value": "c"},
+		{"op": "remove", "path": "/time"}
+	]`)))
+	assert.Equal(t, pathTx{
+		Msgs: []Vehicle{
+			pathSend{To: "b", Amount: []pathCoin{{"atom", 9}, {"uatom", 7}, {"photon", 1}}},
+			Boat("Poseidon"),
+		},
+		Fee:  &pathCoin{"atom", 9},
+		Memo: walkRepr{"a c b"},
+		Tags: map[string]string{"a/b": "v"},
+		Note: "kept",
+	}, tx)
+
+	// Failures leave the value as it was.
+	var before = tx
+	for ops, want := range map[string]string{
+		`[{"op": "replace", "path": "/fee/amount", "value": "1"}, {"op": "test", "path": "/fee/amount", "value": "2"}]`: `op 1 (test /fee/amount): test failed: fee.amount is not "2"`,
+		`[{"op": "remove", "path": "/msgs/2"}]`:                                      "op 0 (remove /msgs/2): msgs[2] does not exist: msgs has 2 elements",
+		`[{"op": "add", "path": "/msgs/3", "value": {"type": "car", "value": "A"}}]`: "op 0 (add /msgs/3): msgs[3] does not exist: msgs has 2 elements",
+		`[{"op": "replace", "path": "/tags/x", "value": "y"}]`:                       "op 0 (replace /tags/x): tags.x does not exist",
+		`[{"op": "remove", "path": "/msgs/0/type"}]`:                                 "op 0 (remove /msgs/0/type): cannot remove msgs[0].type: an interface value has both a type and value",
+		`[{"op": "remove", "path": ""}]`:                                             "op 0 (remove ): cannot remove the root",
+		`[{"op": "move", "from": "/msgs", "path": "/msgs/0"}]`:                       "op 0 (move /msgs/0): cannot move /msgs into itself",
+		`[{"op": "frob", "path": "/fee"}]`:                                           `op 0 (frob /fee): unknown op "frob"`,
+		`[{"op": "remove", "path": "fee"}]`:                                          `op 0 (remove fee): invalid JSON pointer "fee": must start with /`,
+	} {
+		assert.EqualError(t, cdc.ApplyJSONPatch(&tx, []byte(ops)), want, ops)
+	}
+	assert.Error(t, cdc.ApplyJSONPatch(&tx, []byte(`[{"op": "replace", "path": "/fee/amount", "value": 1}]`)))
+	assert.Error(t, cdc.ApplyJSONPatch(&tx, []byte(`{"op": "remove", "path": "/fee"}`)))
+	assert.Equal(t, before, tx)
+}
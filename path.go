@@ -0,0 +1,330 @@
+package amino
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//----------------------------------------
+// Path
+
+// Path is the location of a value nested within another, as the struct
+// fields and list elements to step into, outermost first.
+type Path []PathStep
+
+// PathStep is a step of a Path into the struct field of JSON name Name, or if
+// Name is empty, into the list element Index.
+type PathStep struct {
+	Name  string
+	Index int
+}
+
+// String returns the path as parsed by ParsePath, e.g.
+// "msgs[0].value.amount[1].denom".
+func (path Path) String() string {
+	var sb strings.Builder
+	for i, step := range path {
+		if step.Name == "" {
+			sb.WriteString("[" + strconv.Itoa(step.Index) + "]")
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(step.Name)
+	}
+	return sb.String()
+}
+
+// Returns the path with step appended, never sharing memory with other
+// children of path.
+func (path Path) child(step PathStep) Path {
+	return append(path[:len(path):len(path)], step)
+}
+
+// Like String, but for error messages.
+func (path Path) describe() string {
+	if len(path) == 0 {
+		return "the root"
+	}
+	return path.String()
+}
+
+// ParsePath parses a path of JSON field names, separated by dots, and list
+// indices in brackets, e.g. "msgs[0].value.amount[1].denom".  The empty
+// string is the empty path, of the outermost value itself.
+func ParsePath(s string) (path Path, err error) {
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed [ at %v", s, i)
+			}
+			index, err := strconv.Atoi(s[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: invalid index %q at %v", s, s[i+1:i+end], i)
+			}
+			path = append(path, PathStep{Index: index})
+			i += end + 1
+		case i > 0 && s[i] != '.':
+			return nil, fmt.Errorf("invalid path %q: expected . or [ at %v", s, i)
+		default:
+			if i > 0 {
+				i++ // The dot.
+			}
+			end := strings.IndexAny(s[i:], ".[")
+			if end < 0 {
+				end = len(s) - i
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q: empty field name at %v", s, i)
+			}
+			path = append(path, PathStep{Name: s[i : i+end]})
+			i += end
+		}
+	}
+	return path, nil
+}
+
+//----------------------------------------
+// GetPath & SetPath
+
+// GetPath returns the value at path (see ParsePath) within o.  Paths are
+// those of the amino JSON of o, but for the type wrapper of o itself, if it
+// is registered: an interface value steps into "type", the registered name
+// of its concrete value, or "value", its concrete value, and the fields or
+// elements of a value with `.MarshalAmino()` are those of its repr.
+func (cdc *Codec) GetPath(o interface{}, path string) (interface{}, error) {
+	steps, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	var rv = reflect.ValueOf(o)
+	if !rv.IsValid() {
+		return nil, errors.New("GetPath cannot get from nil")
+	}
+	var value interface{}
+	err = cdc.atPath(rv, FieldOptions{}, steps, nil, false, func(rv reflect.Value, _ FieldOptions) error {
+		value = rv.Interface()
+		return nil
+	})
+	return value, err
+}
+
+// SetPath sets the value at path (see GetPath) within the value ptr points
+// to by decoding jsonValue, amino JSON of the value's own type, into it.  Nil
+// pointers on the way are constructed, and maps are added to.  Setting the
+// "type" of an interface value to another registered name sets it to the
+// zero value of that concrete type.
+func (cdc *Codec) SetPath(ptr interface{}, path string, jsonValue []byte) error {
+	steps, err := ParsePath(path)
+	if err != nil {
+		return err
+	}
+	var rv = reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("SetPath expects a non-nil pointer")
+	}
+	return cdc.atPath(rv.Elem(), FieldOptions{}, steps, nil, true, func(rv reflect.Value, fopts FieldOptions) error {
+		info, err := cdc.getTypeInfoWlock(rv.Type())
+		if err != nil {
+			return err
+		}
+		return cdc.decodeReflectJSON(jsonValue, info, rv, fopts, false)
+	})
+}
+
+// Calls fn with the value at path within rv, where done is the path of rv,
+// and fopts its options.  If set, rv is settable, fn is given a settable
+// value too, and what it sets is set within rv.
+func (cdc *Codec) atPath(rv reflect.Value, fopts FieldOptions, path, done Path, set bool,
+	fn func(rv reflect.Value, fopts FieldOptions) error) error {
+	if len(path) == 0 {
+		return fn(rv, fopts)
+	}
+
+	// Dereference pointers, constructing them if setting.
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			if !set {
+				return fmt.Errorf("%v does not exist: %v is nil", done.child(path[0]), done.describe())
+			}
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	var step, next = path[0], done.child(path[0])
+
+	if rv.Kind() == reflect.Interface {
+		switch {
+		case step.Name == "type" && len(path) == 1:
+			return cdc.atInterfaceType(rv, next, set, fn)
+		case step.Name != "value":
+			return fmt.Errorf("%v does not exist: %v is an interface value, of a type and value", next, done.describe())
+		case rv.IsNil():
+			return fmt.Errorf("%v does not exist: %v is nil", next, done.describe())
+		}
+		// Use a settable copy, and set it back.
+		var cpy = reflect.New(rv.Elem().Type()).Elem()
+		cpy.Set(rv.Elem())
+		if err := cdc.atPath(cpy, FieldOptions{}, path[1:], next, set, fn); err != nil {
+			return err
+		}
+		if set {
+			rv.Set(cpy)
+		}
+		return nil
+	}
+
+	info, err := cdc.getTypeInfoWlock(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	if info.IsAminoMarshaler {
+		return cdc.withRepr(rv, info, set, func(repr reflect.Value) error {
+			return cdc.atPath(repr, fopts, path, done, set, fn)
+		})
+	}
+
+	switch {
+	case (rv.Kind() == reflect.Array || rv.Kind() == reflect.Slice) && rv.Type().Elem().Kind() != reflect.Uint8:
+		if step.Name != "" {
+			return fmt.Errorf("%v does not exist: %v is a list", next, done.describe())
+		}
+		if step.Index < 0 || step.Index >= rv.Len() {
+			return fmt.Errorf("%v does not exist: %v has %v elements", next, done.describe(), rv.Len())
+		}
+		return cdc.atPath(rv.Index(step.Index), fopts, path[1:], next, set, fn)
+
+	case rv.Kind() == reflect.Map:
+		key, err := mapKey(rv, step)
+		if err != nil {
+			return fmt.Errorf("%v does not exist: %v", next, err)
+		}
+		var val = rv.MapIndex(key)
+		if !val.IsValid() && !set {
+			return fmt.Errorf("%v does not exist", next)
+		}
+		// Use a settable copy, and set it back.
+		var cpy = reflect.New(rv.Type().Elem()).Elem()
+		if val.IsValid() {
+			cpy.Set(val)
+		}
+		if err := cdc.atPath(cpy, fopts, path[1:], next, set, fn); err != nil {
+			return err
+		}
+		if set {
+			if rv.IsNil() {
+				rv.Set(reflect.MakeMap(rv.Type()))
+			}
+			rv.SetMapIndex(key, cpy)
+		}
+		return nil
+
+	case rv.Kind() == reflect.Struct && rv.Type() != timeType:
+		if step.Name == "" {
+			return fmt.Errorf("%v does not exist: %v is a struct", next, done.describe())
+		}
+		field, ok := jsonField(info, step.Name)
+		if !ok {
+			return fmt.Errorf("%v does not exist: %v has no field %v", next, rv.Type(), step.Name)
+		}
+		return cdc.atPath(rv.Field(field.Index), field.FieldOptions, path[1:], next, set, fn)
+
+	default:
+		return fmt.Errorf("%v does not exist: %v is a %v", next, done.describe(), rv.Type())
+	}
+}
+
+// Calls fn with a settable copy of the repr of rv, of a type with
+// .MarshalAmino(), and if set, sets rv to what fn leaves it by
+// .UnmarshalAmino().
+func (cdc *Codec) withRepr(rv reflect.Value, info *TypeInfo, set bool, fn func(repr reflect.Value) error) error {
+	rrv, err := toReprObject(rv)
+	if err != nil {
+		return err
+	}
+	var repr = reflect.New(rrv.Type()).Elem()
+	repr.Set(rrv)
+	if err = fn(repr); err != nil || !set {
+		return err
+	}
+	if !info.IsAminoUnmarshaler {
+		return fmt.Errorf("cannot set within the repr of %v, which has no UnmarshalAmino", rv.Type())
+	}
+	uwrm := rv.Addr().MethodByName("UnmarshalAmino")
+	uwouts := uwrm.Call([]reflect.Value{repr})
+	if erri := uwouts[0].Interface(); erri != nil {
+		return erri.(error)
+	}
+	return nil
+}
+
+// Returns the field of JSON name name, unless it is skipped by JSON, e.g.
+// amino:"-json".
+func jsonField(info *TypeInfo, name string) (FieldInfo, bool) {
+	for _, field := range info.Fields {
+		if field.JSONName == name && !field.JSONSkip {
+			return field, true
+		}
+	}
+	return FieldInfo{}, false
+}
+
+// Returns the key of the map rv for step, its name, or index as a string.
+func mapKey(rv reflect.Value, step PathStep) (reflect.Value, error) {
+	if rv.Type().Key().Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("%v is not a map of string keys", rv.Type())
+	}
+	var name = step.Name
+	if name == "" {
+		if step.Index < 0 {
+			return reflect.Value{}, fmt.Errorf("invalid key %v", step.Index)
+		}
+		name = strconv.Itoa(step.Index)
+	}
+	return reflect.ValueOf(name).Convert(rv.Type().Key()), nil
+}
+
+// Calls fn with the registered name of the concrete value of rv, an interface
+// value, and if setting, sets rv to the zero value of the concrete type of
+// the set name, if different.
+func (cdc *Codec) atInterfaceType(rv reflect.Value, path Path, set bool,
+	fn func(rv reflect.Value, fopts FieldOptions) error) error {
+	var name = reflect.New(reflect.TypeOf("")).Elem()
+	if !rv.IsNil() {
+		var crv, _, _ = derefPointers(rv.Elem())
+		cinfo, err := cdc.getTypeInfoWlock(crv.Type())
+		if err != nil {
+			return err
+		}
+		name.SetString(cinfo.Name)
+	} else if !set {
+		return fmt.Errorf("%v does not exist: %v is nil", path, path[:len(path)-1].describe())
+	}
+	if !set {
+		return fn(reflect.ValueOf(name.String()), FieldOptions{}) // Not settable.
+	}
+	var old = name.String()
+	if err := fn(name, FieldOptions{}); err != nil {
+		return err
+	}
+	if name.String() == old {
+		return nil
+	}
+	cinfo, err := cdc.getTypeInfoFromNameRlock(name.String())
+	if err != nil {
+		return err
+	}
+	if !cinfo.Type.Implements(rv.Type()) && !cinfo.PtrToType.Implements(rv.Type()) {
+		return fmt.Errorf("cannot set %v: %v does not implement %v", path, cinfo.Type, rv.Type())
+	}
+	var _, irvSet = constructConcreteType(cinfo)
+	rv.Set(irvSet)
+	return nil
+}
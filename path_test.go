@@ -0,0 +1,169 @@
+package amino_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+func TestParsePath(t *testing.T) {
+	cases := []struct {
+		path    string
+		want    amino.Path
+		wantErr string
+	}{
+		{"", nil, ""},
+		{"a", amino.Path{{Name: "a"}}, ""},
+		{"[3]", amino.Path{{Index: 3}}, ""},
+		{"msgs[0].value.amount[1].denom", amino.Path{
+			{Name: "msgs"}, {Index: 0}, {Name: "value"}, {Name: "amount"}, {Index: 1}, {Name: "denom"},
+		}, ""},
+		{"a[0][12]", amino.Path{{Name: "a"}, {Index: 0}, {Index: 12}}, ""},
+		{".a", nil, `invalid path ".a": empty field name at 0`},
+		{"a.", nil, `invalid path "a.": empty field name at 2`},
+		{"a[0]b", nil, `invalid path "a[0]b": expected . or [ at 4`},
+		{"a[0", nil, `invalid path "a[0": unclosed [ at 1`},
+		{"a[-1]", nil, `invalid path "a[-1]": invalid index "-1" at 1`},
+		{"a[x]", nil, `invalid path "a[x]": invalid index "x" at 1`},
+	}
+	for _, tc := range cases {
+		path, err := amino.ParsePath(tc.path)
+		if tc.wantErr != "" {
+			assert.EqualError(t, err, tc.wantErr, tc.path)
+			continue
+		}
+		if assert.NoError(t, err, tc.path) {
+			assert.Equal(t, tc.want, path, tc.path)
+			assert.Equal(t, tc.path, path.String(), tc.path)
+		}
+	}
+}
+
+type pathCoin struct {
+	Denom  string `json:"denom"`
+	Amount int64  `json:"amount"`
+}
+
+type pathSend struct {
+	To     string     `json:"to"`
+	Amount []pathCoin `json:"amount"`
+}
+
+func (pathSend) Move() error { return nil }
+
+type pathTx struct {
+	Msgs []Vehicle         `json:"msgs"`
+	Fee  *pathCoin         `json:"fee"`
+	Memo walkRepr          `json:"memo"`
+	Tags map[string]string `json:"tags"`
+	Time time.Time         `json:"time"`
+	Note string            `amino:"-json"`
+}
+
+func newPathCodec() *amino.Codec {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+	cdc.RegisterConcrete(pathSend{}, "path/send", nil)
+	return cdc
+}
+
+func TestGetPath(t *testing.T) {
+	var cdc = newPathCodec()
+	var tx = pathTx{
+		Msgs: []Vehicle{
+			pathSend{To: "b", Amount: []pathCoin{{"stake", 1}, {"uatom", 2}}},
+			Car("Tesla"),
+		},
+		Memo: walkRepr{"a b"},
+		Tags: map[string]string{"k": "v"},
+		Note: "n",
+	}
+
+	for path, want := range map[string]interface{}{
+		"":                              tx,
+		"msgs[0].type":                  "path/send",
+		"msgs[0].value.amount[1].denom": "uatom",
+		"msgs[0].value.amount[1]":       pathCoin{"uatom", 2},
+		"msgs[1]":                       Car("Tesla"),
+		"msgs[1].value":                 Car("Tesla"),
+		"fee":                           (*pathCoin)(nil),
+		"memo":                          walkRepr{"a b"},
+		"memo[1]":                       "b",
+		"tags.k":                        "v",
+	} {
+		got, err := cdc.GetPath(tx, path)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, want, got, path)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"msgs[2]":                 "msgs[2] does not exist: msgs has 2 elements",
+		"msgs.x":                  "msgs.x does not exist: msgs is a list",
+		"msgs[0].to":              "msgs[0].to does not exist: msgs[0] is an interface value, of a type and value",
+		"msgs[0].value.from":      "msgs[0].value.from does not exist: amino_test.pathSend has no field from",
+		"msgs[0].value[0]":        "msgs[0].value[0] does not exist: msgs[0].value is a struct",
+		"msgs[0].value.to.length": "msgs[0].value.to.length does not exist: msgs[0].value.to is a string",
+		"fee.denom":               "fee.denom does not exist: fee is nil",
+		"tags.missing":            "tags.missing does not exist",
+		"time.seconds":            "time.seconds does not exist: time is a time.Time",
+		"Note":                    "Note does not exist: amino_test.pathTx has no field Note",
+		"msgs[0]..to":             `invalid path "msgs[0]..to": empty field name at 8`,
+	} {
+		_, err := cdc.GetPath(tx, path)
+		assert.EqualError(t, err, want, path)
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	var cdc = newPathCodec()
+	var tx = pathTx{
+		Msgs: []Vehicle{
+			pathSend{To: "b", Amount: []pathCoin{{"stake", 1}, {"uatom", 2}}},
+			Car("Tesla"),
+		},
+		Memo: walkRepr{"a b"},
+	}
+
+	for _, tc := range []struct{ path, json string }{
+		{"msgs[0].value.amount[1].amount", `"7"`},
+		{"msgs[0].value.to", `"c"`},
+		{"msgs[1].type", `"boat"`},
+		{"msgs[1].value", `"Poseidon"`},
+		{"fee.denom", `"uatom"`},
+		{"memo[0]", `"x"`},
+		{"tags.new", `"n"`},
+		{"time", `"2020-01-01T00:00:00Z"`},
+	} {
+		assert.NoError(t, cdc.SetPath(&tx, tc.path, []byte(tc.json)), tc.path)
+	}
+	assert.Equal(t, pathTx{
+		Msgs: []Vehicle{
+			pathSend{To: "c", Amount: []pathCoin{{"stake", 1}, {"uatom", 7}}},
+			Boat("Poseidon"),
+		},
+		Fee:  &pathCoin{Denom: "uatom"},
+		Memo: walkRepr{"x b"},
+		Tags: map[string]string{"new": "n"},
+		Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, tx)
+
+	// Whole values, and interface values by their wrappers.
+	assert.NoError(t, cdc.SetPath(&tx, "msgs[1]", []byte(`{"type":"car","value":"Audi"}`)))
+	assert.NoError(t, cdc.SetPath(&tx, "fee", []byte(`null`)))
+	assert.NoError(t, cdc.SetPath(&tx, "msgs[0].value", []byte(`{"to":"d"}`)))
+	assert.Equal(t, []Vehicle{pathSend{To: "d"}, Car("Audi")}, tx.Msgs)
+	assert.Nil(t, tx.Fee)
+
+	assert.EqualError(t, cdc.SetPath(&tx, "msgs[1].type", []byte(`"insuranceplan"`)),
+		"cannot set msgs[1].type: amino_test.insurancePlan does not implement amino_test.Vehicle")
+	assert.EqualError(t, cdc.SetPath(&tx, "msgs[1].type", []byte(`"nope"`)),
+		"unrecognized concrete type name nope")
+	assert.EqualError(t, cdc.SetPath(&tx, "msgs[2]", []byte(`null`)),
+		"msgs[2] does not exist: msgs has 2 elements")
+	assert.Error(t, cdc.SetPath(&tx, "msgs[0].value.to", []byte(`5`)))
+	assert.EqualError(t, cdc.SetPath(tx, "fee", []byte(`null`)), "SetPath expects a non-nil pointer")
+}
@@ -39,11 +39,22 @@ func checkUnsafe(field FieldInfo) {
 		return
 	}
 	switch field.Type.Kind() {
-	case reflect.Float32, reflect.Float64:
+	case reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128:
 		panic("floating point types are unsafe for go-amino")
 	}
 }
 
+func checkOptional(field FieldInfo) {
+	if !field.Optional {
+		return
+	}
+	if field.Type.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("optional field %v must be a pointer, got %v",
+			field.Name, field.Type))
+	}
+}
+
 // CONTRACT: by the time this is called, len(bz) >= _n
 // Returns true so you can write one-liners.
 func slide(bz *[]byte, n *int, _n int) bool {
@@ -125,6 +136,15 @@ func isDefaultValue(rv reflect.Value) (erv reflect.Value, isDefaultValue bool) {
 	}
 }
 
+// Returns the value to set for a struct field that is absent from the
+// encoding.  Optional fields are left nil, otherwise see defaultValue.
+func absentFieldValue(field FieldInfo) reflect.Value {
+	if field.Optional {
+		return field.ZeroValue
+	}
+	return defaultValue(field.Type)
+}
+
 // Returns the default value of a type.  For a time type or a pointer(s) to
 // time, the default value is not zero (or nil), but the time value of 1970.
 func defaultValue(rt reflect.Type) (rv reflect.Value) {
@@ -188,6 +208,56 @@ func constructConcreteType(cinfo *TypeInfo) (crv, irvSet reflect.Value) {
 	return
 }
 
+// Like constructConcreteType, but when merging into a non-nil interface rv that
+// holds a value of the same concrete type, crv starts as a copy of that value.
+func constructConcreteTypeMerge(cinfo *TypeInfo, rv reflect.Value, merge bool) (crv, irvSet reflect.Value) {
+	crv, irvSet = constructConcreteType(cinfo)
+	if !merge || rv.IsNil() {
+		return
+	}
+	var erv, _, isNilPtr = derefPointers(rv.Elem())
+	if !isNilPtr && erv.Type() == cinfo.Type {
+		crv.Set(erv)
+	}
+	return
+}
+
+// Returns a new repr instance to decode into for an AminoUnmarshaler rv.  When
+// merging, it starts as the repr of rv (if rv is also an AminoMarshaler).
+func newReprObjectMerge(info *TypeInfo, rv reflect.Value, merge bool) (rrv reflect.Value, err error) {
+	rrv = reflect.New(info.AminoUnmarshalReprType).Elem()
+	if !merge || !info.IsAminoMarshaler ||
+		info.AminoMarshalReprType != info.AminoUnmarshalReprType {
+		return
+	}
+	var crrv reflect.Value
+	crrv, err = toReprObject(rv)
+	if err != nil {
+		return
+	}
+	rrv.Set(crrv)
+	return
+}
+
+// Returns true iff info is a list (but not a byteslice or bytearray) whose
+// elements are written in packed form, e.g. []int64 or [4]uint32.
+func isPackedNestedList(info *TypeInfo) bool {
+	if info.IsAminoMarshaler {
+		return false
+	}
+	if info.Type.Kind() != reflect.Slice && info.Type.Kind() != reflect.Array {
+		return false
+	}
+	ert := info.Type.Elem()
+	if ert.Kind() == reflect.Uint8 {
+		return false
+	}
+	for ert.Kind() == reflect.Ptr {
+		ert = ert.Elem()
+	}
+	return typeToTyp3(ert, FieldOptions{}) != Typ3ByteLength
+}
+
 // CONTRACT: rt.Kind() != reflect.Ptr
 func typeToTyp3(rt reflect.Type, opts FieldOptions) Typ3 {
 	switch rt.Kind() {
@@ -217,6 +287,9 @@ func typeToTyp3(rt reflect.Type, opts FieldOptions) Typ3 {
 		return Typ38Byte
 	case reflect.Float32:
 		return Typ3_4Byte
+	case reflect.Complex64, reflect.Complex128:
+		// Encoded as a message with real and imaginary parts.
+		return Typ3ByteLength
 	default:
 		panic(fmt.Sprintf("unsupported field type %v", rt))
 	}
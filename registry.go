@@ -0,0 +1,283 @@
+package amino
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//----------------------------------------
+// Registry description
+
+// RegistryDescription is a serializable description of all the types known
+// to a Codec, enough to decode amino bytes without the Go types at hand (see
+// cmd/aminoscan).  Types refer to each other by their TypeString, e.g.
+// "[]*github.com/tendermint/go-amino/tests.Foo".
+type RegistryDescription struct {
+	Types []TypeDescription `json:"types"`
+}
+
+// TypeDescription describes a Go type as seen by amino.
+type TypeDescription struct {
+	Type string `json:"type"` // TypeString, e.g. "github.com/tendermint/go-amino/tests.Foo"
+	Kind string `json:"kind"` // reflect.Kind, e.g. "struct"
+
+	// For pointers, arrays, slices and maps, the element (or value) type.
+	Elem string `json:"elem,omitempty"`
+	Key  string `json:"key,omitempty"` // Maps only.
+	Len  int    `json:"len,omitempty"` // Arrays only.
+
+	// For structs.
+	Fields []FieldDescription `json:"fields,omitempty"`
+
+	// For registered concrete types.
+	Registered       bool   `json:"registered,omitempty"`
+	Name             string `json:"name,omitempty"`
+	Prefix           string `json:"prefix,omitempty"` // Hex
+	Disamb           string `json:"disamb,omitempty"` // Hex
+	PointerPreferred bool   `json:"pointer_preferred,omitempty"`
+
+	// For interfaces, the types of the registered implementers.
+	Implementers       []string `json:"implementers,omitempty"`
+	AlwaysDisambiguate bool     `json:"always_disambiguate,omitempty"`
+
+	// For types that implement MarshalAmino, the type of the repr.
+	ReprType string `json:"repr_type,omitempty"`
+}
+
+// FieldDescription describes a struct field and its FieldOptions.
+type FieldDescription struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"` // TypeString, e.g. "*int64"
+	JSONName      string   `json:"json_name"`
+	JSONOmitEmpty bool     `json:"json_omit_empty,omitempty"`
+	JSONAliases   []string `json:"json_aliases,omitempty"`
+	JSONSkip      bool     `json:"json_skip,omitempty"`
+	BinFieldNum   uint32   `json:"bin_field_num,omitempty"`
+	BinFixed64    bool     `json:"bin_fixed64,omitempty"`
+	BinFixed32    bool     `json:"bin_fixed32,omitempty"`
+	BinSkip       bool     `json:"bin_skip,omitempty"`
+	Unsafe        bool     `json:"unsafe,omitempty"`
+	WriteEmpty    bool     `json:"write_empty,omitempty"`
+	EmptyElements bool     `json:"empty_elements,omitempty"`
+	Optional      bool     `json:"optional,omitempty"`
+	UnpackedList  bool     `json:"unpacked_list,omitempty"`
+}
+
+// FieldOptions returns the FieldOptions described by fd.
+func (fd FieldDescription) FieldOptions() FieldOptions {
+	return FieldOptions{
+		JSONName:      fd.JSONName,
+		JSONOmitEmpty: fd.JSONOmitEmpty,
+		JSONAliases:   fd.JSONAliases,
+		JSONSkip:      fd.JSONSkip,
+		BinFixed64:    fd.BinFixed64,
+		BinFixed32:    fd.BinFixed32,
+		BinFieldNum:   fd.BinFieldNum,
+		BinSkip:       fd.BinSkip,
+		Unsafe:        fd.Unsafe,
+		WriteEmpty:    fd.WriteEmpty,
+		EmptyElements: fd.EmptyElements,
+		Optional:      fd.Optional,
+	}
+}
+
+// Type returns the description of the type rt, a TypeString.
+func (rd RegistryDescription) Type(rt string) (td TypeDescription, ok bool) {
+	for _, td := range rd.Types {
+		if td.Type == rt {
+			return td, true
+		}
+	}
+	return
+}
+
+// ExportRegistry writes the RegistryDescription of cdc as JSON to w.
+func (cdc *Codec) ExportRegistry(w io.Writer) error {
+	rd, err := cdc.DescribeRegistry()
+	if err != nil {
+		return err
+	}
+	bz, err := json.MarshalIndent(rd, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(bz, '\n'))
+	return err
+}
+
+// DescribeRegistry returns the description of all registered types, all
+// types that have been used with cdc, and all the types they refer to.
+// Their TypeStrings must be unique.
+func (cdc *Codec) DescribeRegistry() (rd RegistryDescription, err error) {
+	// Collect the known types.
+	cdc.mtx.RLock()
+	var rts = make([]reflect.Type, 0, len(cdc.typeInfos))
+	for rt := range cdc.typeInfos {
+		rts = append(rts, rt)
+	}
+	cdc.mtx.RUnlock()
+
+	// Describe them and the types they refer to.
+	var seen = make(map[reflect.Type]bool)
+	var described = make(map[string]bool) // By TypeString.
+	for len(rts) > 0 {
+		var rt = rts[0]
+		rts = rts[1:]
+		if seen[rt] {
+			continue
+		}
+		seen[rt] = true
+		var td TypeDescription
+		var refs []reflect.Type
+		td, refs, err = cdc.describeType(rt)
+		if err != nil {
+			return
+		}
+		if described[td.Type] {
+			// E.g. types of the same name declared in different functions.
+			return rd, fmt.Errorf("cannot describe distinct types of the same TypeString %v", td.Type)
+		}
+		described[td.Type] = true
+		rd.Types = append(rd.Types, td)
+		rts = append(rts, refs...)
+	}
+
+	sort.Slice(rd.Types, func(i, j int) bool {
+		return rd.Types[i].Type < rd.Types[j].Type
+	})
+	return rd, nil
+}
+
+// Returns the description of rt and the types it refers to.
+func (cdc *Codec) describeType(rt reflect.Type) (td TypeDescription, refs []reflect.Type, err error) {
+	td.Type = TypeString(rt)
+	td.Kind = rt.Kind().String()
+
+	switch rt.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		td.Elem = TypeString(rt.Elem())
+		refs = append(refs, rt.Elem())
+	case reflect.Array:
+		td.Elem = TypeString(rt.Elem())
+		td.Len = rt.Len()
+		refs = append(refs, rt.Elem())
+	case reflect.Map:
+		td.Elem = TypeString(rt.Elem())
+		td.Key = TypeString(rt.Key())
+		refs = append(refs, rt.Key(), rt.Elem())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		// Not supported by amino, nothing more to describe.
+		return
+	}
+	if rt.Kind() == reflect.Ptr {
+		return
+	}
+
+	var info *TypeInfo
+	info, err = cdc.getTypeInfoWlock(rt)
+	if err != nil {
+		if rt.Kind() == reflect.Interface {
+			// Unregistered interfaces have no implementers.
+			return td, refs, nil
+		}
+		return
+	}
+
+	if info.Registered {
+		td.Registered = true
+		td.Name = info.Name
+		td.Prefix = fmt.Sprintf("%X", info.Prefix.Bytes())
+		td.Disamb = fmt.Sprintf("%X", info.Disamb.Bytes())
+		td.PointerPreferred = info.PointerPreferred
+	}
+	if info.IsAminoMarshaler {
+		td.ReprType = TypeString(info.AminoMarshalReprType)
+		refs = append(refs, info.AminoMarshalReprType)
+	}
+
+	switch rt.Kind() {
+	case reflect.Interface:
+		cdc.mtx.RLock()
+		for _, cinfos := range info.Implementers {
+			for _, cinfo := range cinfos {
+				td.Implementers = append(td.Implementers, TypeString(cinfo.Type))
+				refs = append(refs, cinfo.Type)
+			}
+		}
+		cdc.mtx.RUnlock()
+		sort.Strings(td.Implementers)
+		td.AlwaysDisambiguate = info.AlwaysDisambiguate
+	case reflect.Struct:
+		if rt == timeType {
+			// Encoded specially, see EncodeTime.
+			return
+		}
+		for _, field := range info.Fields {
+			td.Fields = append(td.Fields, FieldDescription{
+				Name:          field.Name,
+				Type:          TypeString(field.Type),
+				JSONName:      field.JSONName,
+				JSONOmitEmpty: field.JSONOmitEmpty,
+				JSONAliases:   field.JSONAliases,
+				JSONSkip:      field.JSONSkip,
+				BinFieldNum:   field.BinFieldNum,
+				BinFixed64:    field.BinFixed64,
+				BinFixed32:    field.BinFixed32,
+				BinSkip:       field.BinSkip,
+				Unsafe:        field.Unsafe,
+				WriteEmpty:    field.WriteEmpty,
+				EmptyElements: field.EmptyElements,
+				Optional:      field.Optional,
+				UnpackedList:  field.UnpackedList,
+			})
+			refs = append(refs, field.Type)
+		}
+	}
+	return td, refs, nil
+}
+
+// TypeString returns the Go type string of rt, as rt.String(), but with named
+// types qualified by their package path rather than name, e.g.
+// "[]*github.com/tendermint/go-amino/tests.Foo", so that it is unique.
+func TypeString(rt reflect.Type) string {
+	if rt.Name() != "" {
+		if rt.PkgPath() == "" {
+			return rt.Name() // Predeclared, e.g. "int64" or "error".
+		}
+		return rt.PkgPath() + "." + rt.Name()
+	}
+	switch rt.Kind() {
+	case reflect.Ptr:
+		return "*" + TypeString(rt.Elem())
+	case reflect.Slice:
+		return "[]" + TypeString(rt.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%v]%v", rt.Len(), TypeString(rt.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%v]%v", TypeString(rt.Key()), TypeString(rt.Elem()))
+	case reflect.Struct:
+		if rt.NumField() == 0 {
+			return "struct {}"
+		}
+		var fields = make([]string, rt.NumField())
+		for i := range fields {
+			var field = rt.Field(i)
+			fields[i] = TypeString(field.Type)
+			if !field.Anonymous {
+				fields[i] = field.Name + " " + fields[i]
+			}
+			if field.Tag != "" {
+				fields[i] += " " + strconv.Quote(string(field.Tag))
+			}
+		}
+		return "struct { " + strings.Join(fields, "; ") + " }"
+	default:
+		// E.g. anonymous interfaces, which amino can't register.
+		return rt.String()
+	}
+}
@@ -0,0 +1,327 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// A Change is a difference between two encodings, a and b.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	Path string     `json:"path"` // e.g. "/Shapes/1/Side", or "/@3/1/@1" without names.
+
+	// The byte offsets of the value in a and b, or -1 if absent (or JSON).
+	OffsetA int `json:"offset_a"`
+	OffsetB int `json:"offset_b"`
+
+	// The values in a and b, as hex and interpretations, or JSON.
+	A string `json:"a,omitempty"`
+	B string `json:"b,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %v @%v: %v", c.Path, c.OffsetB, c.B)
+	case Removed:
+		return fmt.Sprintf("- %v @%v: %v", c.Path, c.OffsetA, c.A)
+	default:
+		return fmt.Sprintf("~ %v @%v/@%v: %v -> %v", c.Path, c.OffsetA, c.OffsetB, c.A, c.B)
+	}
+}
+
+//----------------------------------------
+// Binary
+
+// Diff aligns the scanned encodings a and b by field number and nesting, and
+// returns their differences.  The nodes should be scanned alike, i.e. both
+// with Scan or both with the same Schema, so that field names are used in
+// the paths if known.
+func Diff(a, b *Node) []Change {
+	var changes []Change
+	diffNodes(&changes, "", a, b)
+	return changes
+}
+
+func diffNodes(changes *[]Change, path string, a, b *Node) {
+	if bytes.Equal(a.Bytes, b.Bytes) {
+		return
+	}
+	leadA, elemsA, isStructA := elements(a)
+	leadB, elemsB, isStructB := elements(b)
+	// Different prefix bytes mean different concrete types.
+	if elemsA == nil || elemsB == nil || isStructA != isStructB || !bytes.Equal(leadA, leadB) {
+		*changes = append(*changes, changed(path, a, b))
+		return
+	}
+
+	// Align list elements by index.
+	if !isStructA {
+		diffRepeated(changes, path, elemsA, elemsB, false)
+		return
+	}
+
+	// Align fields by number.
+	var numsA, numsB = groupFields(elemsA), groupFields(elemsB)
+	var nums []uint32
+	for num := range numsA {
+		nums = append(nums, num)
+	}
+	for num := range numsB {
+		if _, ok := numsA[num]; !ok {
+			nums = append(nums, num)
+		}
+	}
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	for _, num := range nums {
+		fieldsA, fieldsB := numsA[num], numsB[num]
+		var field = fieldsA
+		if len(field) == 0 {
+			field = fieldsB
+		}
+		var fpath = path + "/" + fieldName(field[0])
+		diffRepeated(changes, fpath, fieldValues(fieldsA), fieldValues(fieldsB),
+			len(fieldsA) > 1 || len(fieldsB) > 1)
+	}
+}
+
+// Pairs up elemsA and elemsB by index.  If indexed, the index is added to
+// the path.
+func diffRepeated(changes *[]Change, path string, elemsA, elemsB []*Node, indexed bool) {
+	for i := 0; i < len(elemsA) || i < len(elemsB); i++ {
+		var epath = path
+		if indexed {
+			epath += "/" + strconv.Itoa(i)
+		}
+		switch {
+		case i >= len(elemsB):
+			*changes = append(*changes, Change{Kind: Removed, Path: epath,
+				OffsetA: elemsA[i].Offset, OffsetB: -1, A: describe(elemsA[i])})
+		case i >= len(elemsA):
+			*changes = append(*changes, Change{Kind: Added, Path: epath,
+				OffsetA: -1, OffsetB: elemsB[i].Offset, B: describe(elemsB[i])})
+		default:
+			diffNodes(changes, epath, elemsA[i], elemsB[i])
+		}
+	}
+}
+
+// Returns the leading key, length and prefix bytes of n, and the elements
+// to align: fields if isStruct, or else list elements.  If n has no
+// elements, elems is nil.
+func elements(n *Node) (lead []byte, elems []*Node, isStruct bool) {
+	var children = n.Children
+	for len(children) > 0 && isLeading(children[0].Kind) {
+		if children[0].Kind == KindPrefix {
+			lead = append(lead, children[0].Bytes...)
+		}
+		children = children[1:]
+	}
+	if len(children) == 0 {
+		return lead, nil, false
+	}
+	// Look through wrappers, e.g. the struct guessed in a ByteLength.
+	if len(children) == 1 && children[0].Kind != KindField && len(children[0].Children) > 0 {
+		clead, elems, isStruct := elements(children[0])
+		return append(lead, clead...), elems, isStruct
+	}
+	for _, child := range children {
+		if child.Kind == KindRemaining {
+			return lead, nil, false
+		}
+	}
+	if children[0].Kind == KindField {
+		return lead, children, true
+	}
+	if n.Kind == KindList {
+		return lead, children, false
+	}
+	return lead, nil, false
+}
+
+func groupFields(fields []*Node) map[uint32][]*Node {
+	var nums = make(map[uint32][]*Node)
+	for _, field := range fields {
+		nums[field.FieldNum] = append(nums[field.FieldNum], field)
+	}
+	return nums
+}
+
+// Returns the value nodes of fields, or the fields themselves if truncated.
+func fieldValues(fields []*Node) []*Node {
+	var values = make([]*Node, len(fields))
+	for i, field := range fields {
+		values[i] = field
+		if len(field.Children) == 2 {
+			values[i] = field.Children[1]
+		}
+	}
+	return values
+}
+
+func fieldName(field *Node) string {
+	if field.Name != "" {
+		return field.Name
+	}
+	return fmt.Sprintf("@%v", field.FieldNum)
+}
+
+func changed(path string, a, b *Node) Change {
+	return Change{Kind: Changed, Path: path,
+		OffsetA: a.Offset, OffsetB: b.Offset, A: describe(a), B: describe(b)}
+}
+
+// Returns the hex of the bytes of n and its interpretations.
+func describe(n *Node) string {
+	var parts = []string{n.Bytes.String()}
+	if n.Type != "" {
+		parts = append(parts, "("+n.Type+")")
+	}
+	for _, v := range n.Values {
+		parts = append(parts, v.As+":"+v.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+//----------------------------------------
+// JSON
+
+// DiffJSON aligns the amino JSON documents a and b by key and index, and
+// returns their differences.  Interface values are compared through their
+// {"type","value"} wrappers, so a change of concrete type is reported as a
+// change of the whole value.
+func DiffJSON(a, b []byte) ([]Change, error) {
+	va, err := decodeJSON(a)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON a: %v", err)
+	}
+	vb, err := decodeJSON(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON b: %v", err)
+	}
+	var changes []Change
+	diffJSON(&changes, "", va, vb)
+	return changes, nil
+}
+
+func decodeJSON(bz []byte) (v interface{}, err error) {
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	err = dec.Decode(&v)
+	return
+}
+
+func diffJSON(changes *[]Change, path string, a, b interface{}) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	// Unwrap interface values of the same type.
+	typeA, valueA, okA := unwrapJSON(a)
+	typeB, valueB, okB := unwrapJSON(b)
+	if okA && okB && typeA == typeB {
+		diffJSON(changes, path, valueA, valueB)
+		return
+	}
+
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || okA || okB {
+			break
+		}
+		var keys []string
+		for key := range a {
+			keys = append(keys, key)
+		}
+		for key := range b {
+			if _, ok := a[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			diffJSONElem(changes, path+"/"+amino.EscapeJSONPointer(key), a, b, key)
+		}
+		return
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(a) || i < len(b); i++ {
+			var epath = path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(b):
+				*changes = append(*changes, jsonChange(Removed, epath, a[i], nil))
+			case i >= len(a):
+				*changes = append(*changes, jsonChange(Added, epath, nil, b[i]))
+			default:
+				diffJSON(changes, epath, a[i], b[i])
+			}
+		}
+		return
+	}
+	*changes = append(*changes, jsonChange(Changed, path, a, b))
+}
+
+func diffJSONElem(changes *[]Change, path string, a, b map[string]interface{}, key string) {
+	va, okA := a[key]
+	vb, okB := b[key]
+	switch {
+	case !okB:
+		*changes = append(*changes, jsonChange(Removed, path, va, nil))
+	case !okA:
+		*changes = append(*changes, jsonChange(Added, path, nil, vb))
+	default:
+		diffJSON(changes, path, va, vb)
+	}
+}
+
+// Returns the type and value of a {"type","value"} interface wrapper.
+func unwrapJSON(v interface{}) (typ string, value interface{}, ok bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) != 2 {
+		return "", nil, false
+	}
+	typ, ok = m["type"].(string)
+	if !ok {
+		return "", nil, false
+	}
+	value, ok = m["value"]
+	return
+}
+
+func jsonChange(kind ChangeKind, path string, a, b interface{}) Change {
+	var c = Change{Kind: kind, Path: path, OffsetA: -1, OffsetB: -1}
+	if kind != Added {
+		c.A = encodeJSON(a)
+	}
+	if kind != Removed {
+		c.B = encodeJSON(b)
+	}
+	return c
+}
+
+func encodeJSON(v interface{}) string {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bz)
+}
@@ -0,0 +1,75 @@
+package scan
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// PrefixLookup maps prefix bytes back to the concrete names they may be the
+// prefix of.
+type PrefixLookup map[amino.PrefixBytes][]string
+
+// NewPrefixLookup returns the PrefixLookup of names (see amino.NameToDisfix).
+func NewPrefixLookup(names []string) PrefixLookup {
+	var pl = make(PrefixLookup, len(names))
+	for _, name := range names {
+		_, pb := amino.NameToDisfix(name)
+		pl[pb] = append(pl[pb], name)
+	}
+	for _, names := range pl {
+		sort.Strings(names)
+	}
+	return pl
+}
+
+// ReadPrefixLookup reads concrete names, one per line.  Blank lines and lines
+// starting with "#" are skipped.
+func ReadPrefixLookup(r io.Reader) (PrefixLookup, error) {
+	var names []string
+	var scanner = bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewPrefixLookup(names), nil
+}
+
+// Lookup returns the candidate concrete names of the prefix bytes bz starts
+// with, if any.
+func (pl PrefixLookup) Lookup(bz []byte) []string {
+	if len(bz) < amino.PrefixBytesLen {
+		return nil
+	}
+	var pb amino.PrefixBytes
+	copy(pb[:], bz)
+	return pl[pb]
+}
+
+// ScanConcrete is like Scan, but bz starts with the prefix bytes of a
+// registered concrete struct, which is one of names.
+func ScanConcrete(bz []byte, names []string) (node *Node, err error) {
+	node = newNode(KindStruct, bz, 0)
+	if len(bz) >= amino.PrefixBytesLen {
+		prefix := newLeaf(KindPrefix, bz, 0, amino.PrefixBytesLen)
+		for _, name := range names {
+			prefix.addValue("concrete", name)
+			node.addValue("concrete", name)
+		}
+		node.add(prefix)
+		var fields *Node
+		fields, err = scanFields(bz[len(node.Bytes):], len(node.Bytes))
+		node.add(fields)
+	}
+	addRemaining(node, bz)
+	return
+}
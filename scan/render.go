@@ -0,0 +1,93 @@
+package scan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A Colorizer colors s, the hex of bytes of the given kind, e.g. with ANSI
+// escape codes.  A nil Colorizer leaves s as is.
+type Colorizer func(kind Kind, s string) string
+
+func (color Colorizer) color(kind Kind, s string) string {
+	if color == nil {
+		return s
+	}
+	return color(kind, s)
+}
+
+// Hex returns the hex of the bytes of n, colored leaf by leaf.
+func (n *Node) Hex(color Colorizer) string {
+	if len(n.Children) == 0 {
+		return color.color(n.Kind, n.Bytes.String())
+	}
+	var s string
+	for _, child := range n.Children {
+		s += child.Hex(color)
+	}
+	return s
+}
+
+// Label describes n without its bytes, e.g. `@1 ByteLength Name (string) "foo"`.
+func (n *Node) Label() string {
+	var parts []string
+	if n.Kind == KindField {
+		parts = append(parts, fmt.Sprintf("@%v %v", n.FieldNum, n.Typ3))
+		if n.Name != "" {
+			parts = append(parts, n.Name)
+		}
+	}
+	if n.Type != "" {
+		parts = append(parts, "("+n.Type+")")
+	} else if n.Kind != KindField {
+		parts = append(parts, "("+string(n.Kind)+")")
+	}
+	for _, v := range n.Values {
+		parts = append(parts, v.As+":"+v.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// WriteTree writes n as an indented tree, one line per node.  The key,
+// length and prefix bytes that lead a node are written on the node's line,
+// unless they are all there is to show of the node's value.
+func WriteTree(w io.Writer, n *Node, color Colorizer) error {
+	return writeTree(w, n, color, "")
+}
+
+func writeTree(w io.Writer, n *Node, color Colorizer, indent string) error {
+	var lead string
+	var children = n.Children
+	if len(children) == 0 {
+		lead = n.Hex(color)
+	}
+	for len(children) > 0 && len(children[0].Children) == 0 && isLeading(children[0].Kind) &&
+		(len(children) > 1 || children[0].Kind == KindKey) {
+		lead += children[0].Hex(color)
+		children = children[1:]
+	}
+	// Leaves are shown on the line of their parent.
+	if len(children) == 1 && len(children[0].Children) == 0 && children[0].Kind == KindRaw {
+		lead += children[0].Hex(color)
+		children = nil
+	}
+
+	var line = indent + lead
+	if lead != "" {
+		line += " "
+	}
+	if _, err := fmt.Fprintln(w, line+n.Label()); err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := writeTree(w, child, color, indent+"  "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isLeading(kind Kind) bool {
+	return kind == KindKey || kind == KindLength || kind == KindPrefix
+}
@@ -0,0 +1,275 @@
+// Package scan scans amino binary bytes into a tree of nodes, each spanning
+// some of the bytes, for tools like aminoscan to render.
+//
+// Without type information, bytes are scanned as a struct (like
+// MarshalBinaryBare writes them) and each value is given all its plausible
+// interpretations.  With a Schema, bytes are scanned the way the decoder
+// would, with field names and Go types.
+package scan
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// Kind is the kind of a Node.
+type Kind string
+
+const (
+	KindStruct    Kind = "struct"    // Fields, possibly length-prefixed.
+	KindField     Kind = "field"     // A field key and its value.
+	KindKey       Kind = "key"       // A field key: uvarint(num<<3|typ3).
+	KindLength    Kind = "length"    // A uvarint length prefix.
+	KindPrefix    Kind = "prefix"    // Disamb and/or prefix bytes.
+	KindVarint    Kind = "varint"    // A (u)varint.
+	Kind8Byte     Kind = "8byte"     // A fixed64 or float64.
+	Kind4Byte     Kind = "4byte"     // A fixed32 or float32.
+	KindBytes     Kind = "bytes"     // A ByteLength value, e.g. a string.
+	KindRaw       Kind = "raw"       // The contents of a ByteLength value.
+	KindList      Kind = "list"      // A packed or unpacked list.
+	KindInterface Kind = "interface" // An interface value.
+	KindRemaining Kind = "remaining" // Bytes left after scanning.
+)
+
+// A Node spans Bytes, starting at Offset of the scanned bytes.  The Bytes of
+// a node with children are the concatenation of the children's Bytes.
+type Node struct {
+	Kind   Kind     `json:"kind"`
+	Offset int      `json:"offset"`
+	Bytes  HexBytes `json:"bytes"`
+
+	// For fields.
+	FieldNum uint32 `json:"field_num,omitempty"`
+	Typ3     string `json:"typ3,omitempty"`
+
+	// If known, the field name and the Go type of the value.
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+
+	Values   []Value `json:"values,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// A Value is an interpretation of the bytes of a node, e.g. as an int64.
+type Value struct {
+	As    string `json:"as"` // e.g. "i64", "u64", "string"
+	Value string `json:"value"`
+}
+
+// HexBytes are bytes that encode to JSON as an uppercase hex string.
+type HexBytes []byte
+
+func (bz HexBytes) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%X"`, []byte(bz))), nil
+}
+
+func (bz *HexBytes) UnmarshalJSON(data []byte) error {
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return err
+	}
+	*bz, err = hex.DecodeString(s)
+	return err
+}
+
+func (bz HexBytes) String() string {
+	return fmt.Sprintf("%X", []byte(bz))
+}
+
+// Value returns the value of the interpretation as, or "".
+func (n *Node) Value(as string) string {
+	for _, v := range n.Values {
+		if v.As == as {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+// The node spans bz[:0] until children are added.
+func newNode(kind Kind, bz []byte, off int) *Node {
+	return &Node{Kind: kind, Offset: off, Bytes: bz[:0]}
+}
+
+func newLeaf(kind Kind, bz []byte, off int, n int, values ...Value) *Node {
+	return &Node{Kind: kind, Offset: off, Bytes: bz[:n], Values: values}
+}
+
+// Appends child, which must follow the bytes of n.
+func (n *Node) add(child *Node) {
+	n.Children = append(n.Children, child)
+	n.Bytes = n.Bytes[:len(n.Bytes)+len(child.Bytes)]
+}
+
+func (n *Node) addValue(as string, value interface{}) {
+	n.Values = append(n.Values, Value{As: as, Value: fmt.Sprintf("%v", value)})
+}
+
+//----------------------------------------
+// Scanning without type information
+
+// Scan scans bz as a struct, guessing what each value is.  On error, the
+// returned node holds what could be scanned, followed by the remaining bytes.
+func Scan(bz []byte) (*Node, error) {
+	node, err := scanFields(bz, 0)
+	addRemaining(node, bz)
+	return node, err
+}
+
+func addRemaining(node *Node, bz []byte) {
+	if len(node.Bytes) < len(bz) {
+		node.add(newLeaf(KindRemaining, bz[len(node.Bytes):], len(node.Bytes), len(bz)-len(node.Bytes)))
+	}
+}
+
+// Scans fields until bz is exhausted.
+func scanFields(bz []byte, off int) (node *Node, err error) {
+	node = newNode(KindStruct, bz, off)
+	for len(node.Bytes) < len(bz) {
+		var field *Node
+		field, err = scanField(bz[len(node.Bytes):], off+len(node.Bytes))
+		if field != nil {
+			node.add(field)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func scanField(bz []byte, off int) (node *Node, err error) {
+	key, typ, num, err := scanKey(bz, off)
+	if err != nil {
+		return
+	}
+	node = newNode(KindField, bz, off)
+	node.FieldNum, node.Typ3 = num, typ.String()
+	node.add(key)
+	value, err := scanAny(typ, bz[len(node.Bytes):], off+len(node.Bytes))
+	if value != nil {
+		node.add(value)
+	}
+	return
+}
+
+func scanKey(bz []byte, off int) (node *Node, typ amino.Typ3, num uint32, err error) {
+	u64, n := binary.Uvarint(bz)
+	if n <= 0 {
+		err = errors.New("error decoding field key")
+		return
+	}
+	typ, num = amino.Typ3(u64&0x07), uint32(u64>>3)
+	if num == 0 {
+		err = errors.New("invalid field number 0")
+		return
+	}
+	node = newLeaf(KindKey, bz, off, n)
+	return
+}
+
+func scanAny(typ amino.Typ3, bz []byte, off int) (*Node, error) {
+	switch typ {
+	case amino.Typ3Varint:
+		return scanVarint(bz, off)
+	case amino.Typ38Byte:
+		return scan8Byte(bz, off)
+	case amino.Typ3ByteLength:
+		return scanByteLength(bz, off)
+	case amino.Typ3_4Byte:
+		return scan4Byte(bz, off)
+	default:
+		return nil, fmt.Errorf("invalid typ3 %v", typ)
+	}
+}
+
+func scanVarint(bz []byte, off int) (*Node, error) {
+	u64, n := binary.Uvarint(bz)
+	if n <= 0 {
+		return nil, errors.New("invalid (u)varint")
+	}
+	i64, _ := binary.Varint(bz)
+	node := newLeaf(KindVarint, bz, off, n)
+	node.addValue("i64", i64)
+	node.addValue("u64", u64)
+	return node, nil
+}
+
+func scan8Byte(bz []byte, off int) (*Node, error) {
+	if len(bz) < 8 {
+		return nil, errors.New("EOF while reading 8byte field")
+	}
+	u64 := binary.LittleEndian.Uint64(bz)
+	node := newLeaf(Kind8Byte, bz, off, 8)
+	node.addValue("u64", u64)
+	node.addValue("i64", int64(u64))
+	node.addValue("f64", math.Float64frombits(u64))
+	return node, nil
+}
+
+func scan4Byte(bz []byte, off int) (*Node, error) {
+	if len(bz) < 4 {
+		return nil, errors.New("EOF while reading 4byte field")
+	}
+	u32 := binary.LittleEndian.Uint32(bz)
+	node := newLeaf(Kind4Byte, bz, off, 4)
+	node.addValue("u32", u32)
+	node.addValue("i32", int32(u32))
+	node.addValue("f32", math.Float32frombits(u32))
+	return node, nil
+}
+
+// Scans a length-prefixed value, guessing whether it is a string or a
+// struct.
+func scanByteLength(bz []byte, off int) (node *Node, err error) {
+	node = newNode(KindBytes, bz, off)
+	length, content, err := scanLength(bz, off)
+	if err != nil {
+		return nil, err
+	}
+	node.add(length)
+	if isPrintable(content) {
+		node.addValue("string", strconv.Quote(string(content)))
+	}
+	// If the contents scan as fields, they might be a struct.
+	if fields, err := scanFields(content, off+len(node.Bytes)); err == nil && len(content) > 0 {
+		node.add(fields)
+	} else {
+		node.add(newLeaf(KindRaw, content, off+len(node.Bytes), len(content)))
+	}
+	return node, nil
+}
+
+// Scans a length prefix, returning the node and the contents that follow.
+func scanLength(bz []byte, off int) (node *Node, content []byte, err error) {
+	u64, n := binary.Uvarint(bz)
+	if n <= 0 {
+		err = errors.New("error decoding length prefix")
+		return
+	}
+	if u64 > uint64(len(bz)-n) {
+		err = fmt.Errorf("length prefix %v exceeds the remaining %v bytes", u64, len(bz)-n)
+		return
+	}
+	node = newLeaf(KindLength, bz, off, n)
+	node.addValue("length", u64)
+	content = bz[n : n+int(u64)]
+	return
+}
+
+func isPrintable(bz []byte) bool {
+	if !utf8.Valid(bz) {
+		return false
+	}
+	return strings.IndexFunc(string(bz), func(r rune) bool {
+		return r < 0x20 && r != '\n' && r != '\t'
+	}) < 0
+}
@@ -0,0 +1,246 @@
+package scan_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/scan"
+)
+
+type Shape interface{}
+
+type Square struct {
+	Side int64
+}
+
+type Drawing struct {
+	Title   string
+	Created time.Time
+	Shapes  []Shape
+	Grid    [][]int8
+	Scale   float64 `amino:"unsafe"`
+}
+
+func newCodec() *amino.Codec {
+	cdc := amino.NewCodec()
+	cdc.RegisterInterface((*Shape)(nil), nil)
+	cdc.RegisterConcrete(Square{}, "scan/Square", nil)
+	cdc.RegisterConcrete(Drawing{}, "scan/Drawing", nil)
+	return cdc
+}
+
+// Checks that the bytes of each node are the concatenation of its children's.
+func checkSpans(t *testing.T, bz []byte, node *scan.Node) {
+	assert.Equal(t, bz[node.Offset:node.Offset+len(node.Bytes)], []byte(node.Bytes), node.Label())
+	if len(node.Children) == 0 {
+		return
+	}
+	var concat []byte
+	for _, child := range node.Children {
+		checkSpans(t, bz, child)
+		concat = append(concat, child.Bytes...)
+	}
+	assert.Equal(t, []byte(node.Bytes), concat, node.Label())
+}
+
+func prefix(name string) []byte {
+	_, pb := amino.NameToDisfix(name)
+	return pb.Bytes()
+}
+
+func TestScan(t *testing.T) {
+	// {1: "hi", 2: -3 (zigzag), 3: {1: 1}} followed by a truncated field.
+	bz := []byte{0x0A, 0x02, 'h', 'i', 0x10, 0x05, 0x1A, 0x02, 0x08, 0x01, 0x21, 0x01}
+	node, err := scan.Scan(bz)
+	require.Error(t, err)
+	checkSpans(t, bz, node)
+
+	require.Len(t, node.Children, 5)
+	title := node.Children[0]
+	assert.Equal(t, scan.KindField, title.Kind)
+	assert.Equal(t, uint32(1), title.FieldNum)
+	assert.Equal(t, "ByteLength", title.Typ3)
+	assert.Equal(t, `"hi"`, title.Children[1].Value("string"))
+
+	num := node.Children[1].Children[1]
+	assert.Equal(t, scan.KindVarint, num.Kind)
+	assert.Equal(t, "-3", num.Value("i64"))
+	assert.Equal(t, "5", num.Value("u64"))
+
+	// The nested struct is guessed.
+	nested := node.Children[2].Children[1]
+	require.Len(t, nested.Children, 2)
+	assert.Equal(t, scan.KindStruct, nested.Children[1].Kind)
+
+	// The 8byte value is truncated, only its key is scanned.
+	assert.Equal(t, "21", node.Children[3].Bytes.String())
+	assert.Equal(t, scan.KindRemaining, node.Children[4].Kind)
+	assert.Equal(t, "01", node.Children[4].Bytes.String())
+}
+
+func TestSchemaScan(t *testing.T) {
+	cdc := newCodec()
+	rd, err := cdc.DescribeRegistry()
+	require.NoError(t, err)
+	sc, err := scan.NewSchema(rd)
+	require.NoError(t, err)
+
+	d := Drawing{
+		Title:   "plan",
+		Created: time.Unix(100, 5).UTC(),
+		Shapes:  []Shape{Square{2}},
+		Grid:    [][]int8{{-1}, {}},
+		Scale:   1.5,
+	}
+	bz, err := cdc.MarshalBinaryBare(d)
+	require.NoError(t, err)
+
+	// The type is detected from the prefix bytes.
+	node, err := sc.Scan(bz, "")
+	require.NoError(t, err)
+	checkSpans(t, bz, node)
+
+	buf := new(bytes.Buffer)
+	err = scan.WriteTree(buf, node, nil)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`%X (github.com/tendermint/go-amino/scan_test.Drawing)
+  (github.com/tendermint/go-amino/scan_test.Drawing)
+    0A @1 ByteLength Title (string)
+      04706C616E (string) string:"plan"
+    12 @2 ByteLength Created (time.Time)
+      0408641005 (time.Time) time:1970-01-01T00:01:40.000000005Z
+    1A @3 ByteLength Shapes ([]github.com/tendermint/go-amino/scan_test.Shape)
+      06%X (github.com/tendermint/go-amino/scan_test.Shape) concrete:scan/Square
+        (github.com/tendermint/go-amino/scan_test.Square)
+          08 @1 (U)Varint Side (int64)
+            02 (int64) int64:2
+    22 @4 ByteLength Grid ([][]int8)
+      03 (struct)
+        0A @1 ByteLength ([]int8)
+          01 ([]int8)
+            01 (int8) int8:-1
+    22 @4 ByteLength Grid ([][]int8)
+      00 (length) empty:[]int8
+    29 @5 8Byte Scale (float64)
+      000000000000F83F (float64) float64:1.5
+`, bz[:4], prefix("scan/Square")), buf.String())
+}
+
+func TestNodeJSON(t *testing.T) {
+	bz := []byte{0x0A, 0x02, 'h', 'i'}
+	node, err := scan.Scan(bz)
+	require.NoError(t, err)
+
+	jsonBz, err := json.Marshal(node.Children[0].Children[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"kind":"key","offset":0,"bytes":"0A"}`, string(jsonBz))
+
+	// Nodes round-trip.
+	jsonBz, err = json.Marshal(node)
+	require.NoError(t, err)
+	var node2 *scan.Node
+	err = json.Unmarshal(jsonBz, &node2)
+	require.NoError(t, err)
+	assert.Equal(t, node.Hex(nil), node2.Hex(nil))
+	assert.Equal(t, node.Children[0].Children[1].Values, node2.Children[0].Children[1].Values)
+}
+
+func TestNewSchemaDuplicates(t *testing.T) {
+	foo := amino.TypeDescription{Type: "example.com/a/pkg.Foo", Kind: "struct", Registered: true, Name: "foo"}
+	bar := amino.TypeDescription{Type: "example.com/b/pkg.Foo", Kind: "struct", Registered: true, Name: "bar"}
+	_, err := scan.NewSchema(amino.RegistryDescription{Types: []amino.TypeDescription{foo, bar}})
+	assert.NoError(t, err)
+
+	_, err = scan.NewSchema(amino.RegistryDescription{Types: []amino.TypeDescription{foo, foo}})
+	assert.EqualError(t, err, "type example.com/a/pkg.Foo is described twice")
+
+	bar.Name = "foo"
+	_, err = scan.NewSchema(amino.RegistryDescription{Types: []amino.TypeDescription{foo, bar}})
+	assert.EqualError(t, err, "types example.com/a/pkg.Foo and example.com/b/pkg.Foo are both registered as foo")
+}
+
+func TestPrefixLookup(t *testing.T) {
+	pl, err := scan.ReadPrefixLookup(strings.NewReader("# Concrete names.\nscan/Square\n\nscan/Drawing\n"))
+	require.NoError(t, err)
+
+	cdc := newCodec()
+	bz, err := cdc.MarshalBinaryBare(Square{Side: 2})
+	require.NoError(t, err)
+	names := pl.Lookup(bz)
+	assert.Equal(t, []string{"scan/Square"}, names)
+	assert.Nil(t, pl.Lookup([]byte{0x08, 0x02}))
+
+	node, err := scan.ScanConcrete(bz, names)
+	require.NoError(t, err)
+	checkSpans(t, bz, node)
+	require.Len(t, node.Children, 2)
+	assert.Equal(t, scan.KindPrefix, node.Children[0].Kind)
+	assert.Equal(t, "scan/Square", node.Children[0].Value("concrete"))
+	assert.Equal(t, "2", node.Children[1].Children[0].Children[1].Value("u64"))
+}
+
+func TestDiff(t *testing.T) {
+	cdc := newCodec()
+	rd, err := cdc.DescribeRegistry()
+	require.NoError(t, err)
+	sc, err := scan.NewSchema(rd)
+	require.NoError(t, err)
+
+	a := Drawing{Title: "plan", Shapes: []Shape{Square{2}, Square{3}}, Scale: 1}
+	b := Drawing{Title: "plan", Shapes: []Shape{Square{4}}, Grid: [][]int8{{1}}, Scale: 1}
+	bzA, err := cdc.MarshalBinaryBare(a)
+	require.NoError(t, err)
+	bzB, err := cdc.MarshalBinaryBare(b)
+	require.NoError(t, err)
+	nodeA, err := sc.Scan(bzA, "")
+	require.NoError(t, err)
+	nodeB, err := sc.Scan(bzB, "")
+	require.NoError(t, err)
+
+	changes := scan.Diff(nodeA, nodeB)
+	require.Len(t, changes, 3)
+	assert.Equal(t, scan.Change{Kind: scan.Changed, Path: "/Shapes/0/Side",
+		OffsetA: 30, OffsetB: 30, A: "02 (int64) int64:2", B: "04 (int64) int64:4"}, changes[0])
+	assert.Equal(t, scan.Removed, changes[1].Kind)
+	assert.Equal(t, "/Shapes/1", changes[1].Path)
+	assert.Equal(t, -1, changes[1].OffsetB)
+	assert.Equal(t, scan.Added, changes[2].Kind)
+	assert.Equal(t, "/Grid", changes[2].Path)
+
+	// Without a schema, fields are numbered and interfaces are opaque.
+	nodeA, err = scan.Scan(bzA[4:])
+	require.NoError(t, err)
+	nodeB, err = scan.Scan(bzB[4:])
+	require.NoError(t, err)
+	changes = scan.Diff(nodeA, nodeB)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "/@3/0", changes[0].Path)
+	assert.Equal(t, fmt.Sprintf("06%X0804", prefix("scan/Square")), changes[0].B)
+
+	assert.Empty(t, scan.Diff(nodeA, nodeA))
+}
+
+func TestDiffJSON(t *testing.T) {
+	a := `{"title":"plan","shapes":[{"type":"scan/Square","value":{"side":"2"}},{"type":"scan/Square","value":{}}]}`
+	b := `{"shapes":[{"type":"scan/Square","value":{"side":"4"}},{"type":"scan/Circle","value":{}}],"scale":1}`
+	changes, err := scan.DiffJSON([]byte(a), []byte(b))
+	require.NoError(t, err)
+	assert.Equal(t, []scan.Change{
+		{Kind: scan.Added, Path: "/scale", OffsetA: -1, OffsetB: -1, B: "1"},
+		{Kind: scan.Changed, Path: "/shapes/0/side", OffsetA: -1, OffsetB: -1, A: `"2"`, B: `"4"`},
+		{Kind: scan.Changed, Path: "/shapes/1", OffsetA: -1, OffsetB: -1,
+			A: `{"type":"scan/Square","value":{}}`, B: `{"type":"scan/Circle","value":{}}`},
+		{Kind: scan.Removed, Path: "/title", OffsetA: -1, OffsetB: -1, A: `"plan"`},
+	}, changes)
+
+	_, err = scan.DiffJSON([]byte(a), []byte(`{`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,546 @@
+package scan
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// Schema holds the types of a registry description (see
+// amino.Codec.ExportRegistry), which lets bytes be scanned the way the
+// decoder would.
+type Schema struct {
+	types map[string]amino.TypeDescription // By amino.TypeString.
+	names map[string]amino.TypeDescription // By registered name.
+}
+
+// NewSchema returns the Schema of rd, whose types and registered names must
+// be unique.
+func NewSchema(rd amino.RegistryDescription) (*Schema, error) {
+	sc := &Schema{
+		types: make(map[string]amino.TypeDescription, len(rd.Types)),
+		names: make(map[string]amino.TypeDescription),
+	}
+	for _, td := range rd.Types {
+		if _, ok := sc.types[td.Type]; ok {
+			return nil, fmt.Errorf("type %v is described twice", td.Type)
+		}
+		sc.types[td.Type] = td
+		if td.Registered {
+			if other, ok := sc.names[td.Name]; ok {
+				return nil, fmt.Errorf("types %v and %v are both registered as %v", other.Type, td.Type, td.Name)
+			}
+			sc.names[td.Name] = td
+		}
+	}
+	return sc, nil
+}
+
+// ReadSchema reads the JSON written by amino.Codec.ExportRegistry.
+func ReadSchema(r io.Reader) (*Schema, error) {
+	bz, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rd amino.RegistryDescription
+	if err = json.Unmarshal(bz, &rd); err != nil {
+		return nil, fmt.Errorf("invalid registry: %v", err)
+	}
+	return NewSchema(rd)
+}
+
+// Lookup returns the type with the amino.TypeString or registered name t.
+func (sc *Schema) Lookup(t string) (td amino.TypeDescription, err error) {
+	if td, ok := sc.types[t]; ok {
+		return td, nil
+	}
+	if td, ok := sc.names[t]; ok {
+		return td, nil
+	}
+	return td, fmt.Errorf("type %v not found in registry", t)
+}
+
+// Detect returns the registered concrete type whose prefix bz starts with.
+func (sc *Schema) Detect(bz []byte) (td amino.TypeDescription, err error) {
+	if len(bz) < amino.PrefixBytesLen {
+		return td, errors.New("EOF while reading prefix bytes")
+	}
+	var prefix = fmt.Sprintf("%X", bz[:amino.PrefixBytesLen])
+	for _, td := range sc.registered() {
+		if td.Prefix == prefix {
+			return td, nil
+		}
+	}
+	return td, fmt.Errorf("no registered concrete type with prefix %v", prefix)
+}
+
+// Scan scans bz as written by MarshalBinaryBare for the amino.TypeString or
+// registered name t.  If t is empty, the type is detected from the prefix
+// bytes.  On error, the returned node holds what could be scanned, followed
+// by the remaining bytes.
+func (sc *Schema) Scan(bz []byte, t string) (node *Node, err error) {
+	var td amino.TypeDescription
+	if t == "" {
+		td, err = sc.Detect(bz)
+	} else {
+		td, err = sc.Lookup(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	node, err = sc.scanRoot(bz, td)
+	addRemaining(node, bz)
+	return
+}
+
+// Registered types sorted by name, so that lookups are deterministic.
+func (sc *Schema) registered() []amino.TypeDescription {
+	var tds = make([]amino.TypeDescription, 0, len(sc.names))
+	for _, td := range sc.names {
+		tds = append(tds, td)
+	}
+	sort.Slice(tds, func(i, j int) bool { return tds[i].Name < tds[j].Name })
+	return tds
+}
+
+// Returns the concrete implementer of the interface itd with the given
+// prefix (and disamb, if any).
+func (sc *Schema) concrete(itd amino.TypeDescription, db amino.DisambBytes, hasDb bool,
+	pb amino.PrefixBytes) (td amino.TypeDescription, err error) {
+	var candidates []amino.TypeDescription
+	if len(itd.Implementers) == 0 {
+		candidates = sc.registered()
+	}
+	for _, impl := range itd.Implementers {
+		candidates = append(candidates, sc.types[impl])
+	}
+	for _, td := range candidates {
+		if td.Prefix != fmt.Sprintf("%X", pb.Bytes()) {
+			continue
+		}
+		if hasDb && td.Disamb != fmt.Sprintf("%X", db.Bytes()) {
+			continue
+		}
+		return td, nil
+	}
+	return td, fmt.Errorf("no implementer of %v with prefix %X", itd.Type, pb.Bytes())
+}
+
+func (sc *Schema) deref(td amino.TypeDescription) amino.TypeDescription {
+	for td.Kind == "ptr" {
+		td = sc.types[td.Elem]
+	}
+	return td
+}
+
+// Mirrors typeToTyp3 of the amino package.
+func (sc *Schema) typ3(td amino.TypeDescription, fd amino.FieldDescription) amino.Typ3 {
+	switch sc.deref(td).Kind {
+	case "interface", "array", "slice", "string", "struct", "map", "complex64", "complex128":
+		return amino.Typ3ByteLength
+	case "int64", "uint64":
+		if fd.BinFixed64 {
+			return amino.Typ38Byte
+		}
+		return amino.Typ3Varint
+	case "int32", "uint32":
+		if fd.BinFixed32 {
+			return amino.Typ3_4Byte
+		}
+		return amino.Typ3Varint
+	case "float64":
+		return amino.Typ38Byte
+	case "float32":
+		return amino.Typ3_4Byte
+	default:
+		return amino.Typ3Varint
+	}
+}
+
+func (sc *Schema) isList(td amino.TypeDescription) bool {
+	td = sc.deref(td)
+	return td.ReprType == "" && (td.Kind == "slice" || td.Kind == "array") &&
+		sc.deref(sc.types[td.Elem]).Kind != "uint8"
+}
+
+// Mirrors isPackedNestedList of the amino package.
+func (sc *Schema) isPackedNestedList(td amino.TypeDescription) bool {
+	return sc.isList(td) && sc.typ3(sc.types[sc.deref(td).Elem], amino.FieldDescription{}) != amino.Typ3ByteLength
+}
+
+func (sc *Schema) isUnpackedList(td amino.TypeDescription, fd amino.FieldDescription) bool {
+	return sc.isList(td) && sc.typ3(sc.types[sc.deref(td).Elem], fd) == amino.Typ3ByteLength
+}
+
+// Mirrors isStructOrRepeatedStruct of the amino package.
+func (sc *Schema) isStructOrRepeatedStruct(td amino.TypeDescription) bool {
+	switch td.Kind {
+	case "struct":
+		return true
+	case "array", "slice":
+		return sc.types[td.Elem].Kind == "struct"
+	default:
+		return false
+	}
+}
+
+//----------------------------------------
+// Scanning
+
+func (sc *Schema) scanRoot(bz []byte, td amino.TypeDescription) (node *Node, err error) {
+	td = sc.deref(td)
+	node = newNode(KindStruct, bz, 0)
+	node.Type = td.Type
+	if td.Registered {
+		if len(bz) < amino.PrefixBytesLen {
+			return node, errors.New("EOF while reading prefix bytes")
+		}
+		prefix := newLeaf(KindPrefix, bz, 0, amino.PrefixBytesLen)
+		prefix.addValue("concrete", td.Name)
+		node.add(prefix)
+	}
+	var fd = amino.FieldDescription{Type: td.Type, BinFieldNum: 1}
+	var child *Node
+	if sc.isStructOrRepeatedStruct(td) {
+		child, err = sc.scanValue(bz[len(node.Bytes):], len(node.Bytes), td, fd, true)
+		if child != nil {
+			node.add(child)
+		}
+		return
+	}
+	// Other values are written as field 1 (or not at all, if empty).
+	for len(node.Bytes) < len(bz) {
+		child, err = sc.scanField(bz[len(node.Bytes):], len(node.Bytes), td, fd, false)
+		if child != nil {
+			node.add(child)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Scans a field key and the value that follows.  If elem is set, td is an
+// unpacked list and the value is a single element.
+func (sc *Schema) scanField(bz []byte, off int, td amino.TypeDescription, fd amino.FieldDescription,
+	elem bool) (node *Node, err error) {
+	key, typ, num, err := scanKey(bz, off)
+	if err != nil {
+		return
+	}
+	node = newNode(KindField, bz, off)
+	node.FieldNum, node.Typ3 = num, typ.String()
+	node.Name, node.Type = fd.Name, td.Type
+	node.add(key)
+	bz, off = bz[len(node.Bytes):], off+len(node.Bytes)
+
+	var value *Node
+	if !elem {
+		value, err = sc.scanValue(bz, off, td, fd, false)
+	} else {
+		// A single element of an unpacked list.
+		etd := sc.types[sc.deref(td).Elem]
+		switch {
+		case len(bz) > 0 && bz[0] == 0x00:
+			value = newLeaf(KindLength, bz, off, 1, Value{As: "empty", Value: etd.Type})
+		case sc.isPackedNestedList(etd):
+			value, err = sc.scanNestedList(bz, off, etd, fd)
+		default:
+			efd := fd
+			efd.BinFieldNum = 1
+			value, err = sc.scanValue(bz, off, etd, efd, false)
+		}
+	}
+	if value != nil {
+		node.add(value)
+	}
+	return
+}
+
+// Scans the value of Go type td.  Unless bare, ByteLength values are
+// length-prefixed.
+func (sc *Schema) scanValue(bz []byte, off int, td amino.TypeDescription, fd amino.FieldDescription,
+	bare bool) (node *Node, err error) {
+	td = sc.deref(td)
+	var rt = td.Type
+	if td.ReprType != "" {
+		td = sc.deref(sc.types[td.ReprType])
+	}
+	defer func() {
+		if node != nil {
+			node.Type = rt
+		}
+	}()
+
+	if td.Type == "time.Time" {
+		return sc.scanMessage(bz, off, bare, func(node *Node, content []byte) error {
+			t, _, err := amino.DecodeTime(content)
+			node.addValue("time", t.Format(time.RFC3339Nano))
+			return err
+		})
+	}
+
+	switch td.Kind {
+	case "interface":
+		return sc.scanInterface(bz, off, td, fd, bare)
+	case "array", "slice":
+		if sc.deref(sc.types[td.Elem]).Kind == "uint8" {
+			return sc.scanMessage(bz, off, false, func(node *Node, content []byte) error {
+				node.addValue("hex", fmt.Sprintf("%X", content))
+				return nil
+			})
+		}
+		return sc.scanList(bz, off, td, fd, bare)
+	case "struct":
+		return sc.scanStruct(bz, off, td, bare)
+	case "complex64", "complex128":
+		return sc.scanMessage(bz, off, bare, func(node *Node, content []byte) error {
+			c, err := decodeComplex(content, td.Kind == "complex64")
+			node.addValue(td.Kind, c)
+			return err
+		})
+	case "string":
+		return sc.scanMessage(bz, off, false, func(node *Node, content []byte) error {
+			node.addValue("string", fmt.Sprintf("%q", content))
+			return nil
+		})
+	default:
+		return scanScalar(bz, off, td, sc.typ3(td, fd))
+	}
+}
+
+// Scans a ByteLength value (bare or length-prefixed) and interprets its
+// contents with decode.
+func (sc *Schema) scanMessage(bz []byte, off int, bare bool,
+	decode func(node *Node, content []byte) error) (node *Node, err error) {
+	node = newNode(KindBytes, bz, off)
+	var content = bz
+	if !bare {
+		var length *Node
+		length, content, err = scanLength(bz, off)
+		if err != nil {
+			return nil, err
+		}
+		node.add(length)
+	}
+	node.add(newLeaf(KindRaw, content, off+len(node.Bytes), len(content)))
+	err = decode(node, content)
+	return
+}
+
+// Scans a struct, or the length prefix and fields of one.
+func (sc *Schema) scanStruct(bz []byte, off int, td amino.TypeDescription, bare bool) (node *Node, err error) {
+	node = newNode(KindStruct, bz, off)
+	var content = bz
+	if !bare {
+		var length *Node
+		length, content, err = scanLength(bz, off)
+		if err != nil {
+			return nil, err
+		}
+		node.add(length)
+	}
+	var end = len(node.Bytes) + len(content)
+
+	for len(node.Bytes) < end {
+		var rest = bz[len(node.Bytes):end]
+		var roff = off + len(node.Bytes)
+		// Peek the field number to find the field.
+		u64, _ := binary.Uvarint(rest)
+		num := uint32(u64 >> 3)
+		var field *amino.FieldDescription
+		for i := range td.Fields {
+			if !td.Fields[i].BinSkip && td.Fields[i].BinFieldNum == num {
+				field = &td.Fields[i]
+				break
+			}
+		}
+		var child *Node
+		if field == nil {
+			// Unknown field, scan generically.
+			child, err = scanField(rest, roff)
+		} else {
+			child, err = sc.scanField(rest, roff, sc.types[field.Type], *field, field.UnpackedList)
+		}
+		if child != nil {
+			node.add(child)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (sc *Schema) scanList(bz []byte, off int, td amino.TypeDescription, fd amino.FieldDescription,
+	bare bool) (node *Node, err error) {
+	node = newNode(KindList, bz, off)
+	var content = bz
+	if !bare {
+		var length *Node
+		length, content, err = scanLength(bz, off)
+		if err != nil {
+			return nil, err
+		}
+		node.add(length)
+	}
+	var end = len(node.Bytes) + len(content)
+
+	for len(node.Bytes) < end {
+		var rest = bz[len(node.Bytes):end]
+		var roff = off + len(node.Bytes)
+		var child *Node
+		if sc.isUnpackedList(td, fd) {
+			// Repeated fields, one per element.
+			child, err = sc.scanField(rest, roff, td, fd, true)
+		} else {
+			// Packed elements.
+			child, err = sc.scanValue(rest, roff, sc.types[td.Elem], fd, false)
+		}
+		if child != nil {
+			node.add(child)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Scans a packed inner list of a nested list, wrapped as field 1 of a
+// message.
+func (sc *Schema) scanNestedList(bz []byte, off int, td amino.TypeDescription,
+	fd amino.FieldDescription) (node *Node, err error) {
+	var wfd = amino.FieldDescription{Type: td.Type, BinFieldNum: 1,
+		BinFixed64: fd.BinFixed64, BinFixed32: fd.BinFixed32}
+	var wtd = amino.TypeDescription{Type: "(wrapper)", Kind: "struct",
+		Fields: []amino.FieldDescription{wfd}}
+	return sc.scanStruct(bz, off, wtd, false)
+}
+
+func (sc *Schema) scanInterface(bz []byte, off int, td amino.TypeDescription, fd amino.FieldDescription,
+	bare bool) (node *Node, err error) {
+	node = newNode(KindInterface, bz, off)
+	var content = bz
+	if !bare {
+		var length *Node
+		length, content, err = scanLength(bz, off)
+		if err != nil {
+			return nil, err
+		}
+		node.add(length)
+	}
+	if len(content) == 0 {
+		node.addValue("concrete", "nil")
+		return
+	}
+
+	db, hasDb, pb, _, n, err := amino.DecodeDisambPrefixBytes(content)
+	if err != nil {
+		return
+	}
+	cd, err := sc.concrete(td, db, hasDb, pb)
+	if err != nil {
+		return
+	}
+	prefix := newLeaf(KindPrefix, content, off+len(node.Bytes), n)
+	prefix.addValue("concrete", cd.Name)
+	node.add(prefix)
+	node.addValue("concrete", cd.Name)
+
+	// The concrete value is written bare.
+	value, err := sc.scanValue(content[n:], off+len(node.Bytes), cd, fd, true)
+	if value != nil {
+		node.add(value)
+	}
+	return
+}
+
+func decodeComplex(bz []byte, isComplex64 bool) (complex128, error) {
+	var parts [2]float64
+	for len(bz) > 0 {
+		u64, n, err := amino.DecodeUvarint(bz)
+		if err != nil {
+			return 0, err
+		}
+		bz = bz[n:]
+		num := int(u64 >> 3)
+		if num < 1 || num > 2 {
+			return 0, fmt.Errorf("unexpected field number %v", num)
+		}
+		if isComplex64 { // Of float32 parts.
+			f, n, err := amino.DecodeFloat32(bz)
+			if err != nil {
+				return 0, err
+			}
+			parts[num-1] = float64(f)
+			bz = bz[n:]
+		} else {
+			f, n, err := amino.DecodeFloat64(bz)
+			if err != nil {
+				return 0, err
+			}
+			parts[num-1] = f
+			bz = bz[n:]
+		}
+	}
+	return complex(parts[0], parts[1]), nil
+}
+
+func scanScalar(bz []byte, off int, td amino.TypeDescription, typ amino.Typ3) (node *Node, err error) {
+	var value interface{}
+	var kind = KindVarint
+	var n int
+	switch typ {
+	case amino.Typ38Byte:
+		kind = Kind8Byte
+		switch td.Kind {
+		case "float64":
+			value, n, err = amino.DecodeFloat64(bz)
+		case "int64":
+			value, n, err = amino.DecodeInt64(bz)
+		default:
+			value, n, err = amino.DecodeUint64(bz)
+		}
+	case amino.Typ3_4Byte:
+		kind = Kind4Byte
+		switch td.Kind {
+		case "float32":
+			value, n, err = amino.DecodeFloat32(bz)
+		case "int32":
+			value, n, err = amino.DecodeInt32(bz)
+		default:
+			value, n, err = amino.DecodeUint32(bz)
+		}
+	default:
+		switch td.Kind {
+		case "bool":
+			value, n, err = amino.DecodeBool(bz)
+		case "int8", "int16":
+			// Zigzag encoded.
+			value, n, err = amino.DecodeVarint(bz)
+		case "int", "int32", "int64":
+			var u64 uint64
+			u64, n, err = amino.DecodeUvarint(bz)
+			value = int64(u64)
+		case "uint", "uint8", "uint16", "uint32", "uint64":
+			value, n, err = amino.DecodeUvarint(bz)
+		default:
+			err = fmt.Errorf("unsupported kind %v", td.Kind)
+		}
+	}
+	if err != nil {
+		return
+	}
+	node = newLeaf(kind, bz, off, n)
+	node.addValue(td.Kind, value)
+	return
+}
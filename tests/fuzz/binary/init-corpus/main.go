@@ -3,21 +3,30 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"time"
 
 	amino "github.com/tendermint/go-amino"
 	"github.com/tendermint/go-amino/tests"
+	"github.com/tendermint/go-amino/tests/fuzz"
+	"github.com/tendermint/go-amino/vectors"
 )
 
 func main() {
 	corpusParentDir := flag.String("corpus-parent", ".", "the directory in which we should place the corpus directory")
+	native := flag.Bool("native", false, "write the seed corpus of the native fuzz targets (see package tests/fuzz) to testdata/fuzz instead")
 	flag.Parse()
 
 	corpusDir := filepath.Join(*corpusParentDir, "corpus")
+	if *native {
+		corpusDir = filepath.Join(*corpusParentDir, "testdata", "fuzz")
+	}
 	if err := os.MkdirAll(corpusDir, 0755); err != nil {
 		log.Fatalf("Cannot mkdirAll: %q err: %v", corpusDir, err)
 	}
@@ -132,6 +141,11 @@ func main() {
 		{PrField: ps, ArField: as, SlField: ss, PtField: pts1},
 	}
 
+	if *native {
+		writeNativeCorpus(corpusDir, seeds)
+		return
+	}
+
 	cdc := amino.NewCodec()
 	cdc.RegisterConcrete(&tests.ComplexSt{}, "com.tendermint/complex_st", nil)
 	cdc.RegisterConcrete(&tests.PrimitivesStruct{}, "com.tendermint/primitive_st", nil)
@@ -156,3 +170,68 @@ func main() {
 		_ = f.Close()
 	}
 }
+
+// Writes the seeds, interface and repr seeds, and samples of every type in
+// fuzz.Types to the corpus of FuzzBinary and FuzzJSON in corpusDir.
+func writeNativeCorpus(corpusDir string, seeds []*tests.ComplexSt) {
+	cdc := fuzz.NewCodec()
+	var values []interface{}
+	for _, seed := range seeds {
+		values = append(values, seed)
+	}
+	hex := fuzz.Hex("DEEZMINTS")
+	values = append(values,
+		&tests.InterfaceFieldsStruct{
+			F1: &tests.InterfaceFieldsStruct{F1: tests.Concrete1{}},
+			F2: tests.ConcreteWrappedBytes{Value: []byte("VIVA LA VIDA!")},
+		},
+		&fuzz.ReprStruct{
+			Hex:    fuzz.Hex("Tendermint!"),
+			HexSl:  []fuzz.Hex{nil, fuzz.Hex("Fuzzing")},
+			HexPt:  &hex,
+			Time:   time.Date(2018, 3, 2, 21, 10, 12, 1e5, time.UTC),
+			Nested: &fuzz.ReprStruct{Nested: tests.Concrete2{}},
+		},
+	)
+	samples, err := vectors.Samples(cdc, &vectors.Options{Samples: 2})
+	if err != nil {
+		log.Fatalf("Failed to make samples: %v", err)
+	}
+	values = append(values, samples...)
+
+	for _, target := range []string{"FuzzBinary", "FuzzJSON"} {
+		dir := filepath.Join(corpusDir, target)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Fatalf("Cannot mkdirAll: %q err: %v", dir, err)
+		}
+		for i, value := range values {
+			var blob []byte
+			if target == "FuzzBinary" {
+				blob, err = cdc.MarshalBinaryLengthPrefixed(value)
+			} else {
+				blob, err = cdc.MarshalJSON(value)
+			}
+			if err != nil {
+				log.Fatalf("Failed to marshal seed %d: %v", i, err)
+			}
+			// See the "go test fuzz v1" format of testing.F.
+			entry := fmt.Sprintf("go test fuzz v1\nuint8(%d)\n[]byte(%s)\n",
+				typeIndex(reflect.TypeOf(value).Elem()), strconv.Quote(string(blob)))
+			fullPath := filepath.Join(dir, fmt.Sprintf("seed-%03d", i))
+			if err := ioutil.WriteFile(fullPath, []byte(entry), 0644); err != nil {
+				log.Fatalf("Failed to write %q: %v", fullPath, err)
+			}
+		}
+	}
+}
+
+// Returns the index of rt in fuzz.Types.
+func typeIndex(rt reflect.Type) int {
+	for i, ft := range fuzz.Types {
+		if ft == rt {
+			return i
+		}
+	}
+	log.Fatalf("%v is not in fuzz.Types", rt)
+	return -1
+}
@@ -0,0 +1,87 @@
+// Package fuzz has the native Go fuzz targets FuzzBinary and FuzzJSON (see
+// fuzz_test.go, which requires go1.18), which decode into each of Types.
+// Their seed corpus in testdata/fuzz is written by
+//
+//	go run tests/fuzz/binary/init-corpus/main.go --native --corpus-parent=tests/fuzz
+//
+// The legacy go-fuzz harnesses are in the binary and json subpackages.
+package fuzz
+
+import (
+	"encoding/hex"
+	"reflect"
+	"time"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/tests"
+)
+
+// Types are the types the fuzz targets decode into, selected by index: every
+// struct and def type of package tests, the implementers of tests.Interface1,
+// and ReprStruct.
+var Types []reflect.Type
+
+func init() {
+	for _, ptr := range tests.StructTypes {
+		Types = append(Types, reflect.TypeOf(ptr).Elem())
+	}
+	for _, ptr := range tests.DefTypes {
+		Types = append(Types, reflect.TypeOf(ptr).Elem())
+	}
+	for _, o := range interface1Types {
+		Types = append(Types, reflect.TypeOf(o))
+	}
+	Types = append(Types, reflect.TypeOf(ReprStruct{}))
+}
+
+// The registered implementers of tests.Interface1.
+var interface1Types = []interface{}{
+	tests.Concrete1{},
+	tests.Concrete2{},
+	tests.ConcreteWrappedBytes{},
+	tests.InterfaceFieldsStruct{},
+}
+
+// NewCodec returns a codec with all of Types registered, as "tests/<Name>",
+// and tests.Interface1.
+func NewCodec() *amino.Codec {
+	cdc := amino.NewCodec()
+	cdc.RegisterInterface((*tests.Interface1)(nil), nil)
+	for _, rt := range Types {
+		// Register pointers to the types that implement interfaces by
+		// pointer, so that they decode as pointers.
+		var o = reflect.New(rt).Interface()
+		if rt.Implements(reflect.TypeOf((*tests.Interface1)(nil)).Elem()) {
+			o = reflect.Zero(rt).Interface()
+		}
+		cdc.RegisterConcrete(o, "tests/"+rt.Name(), nil)
+	}
+	return cdc.Seal()
+}
+
+//----------------------------------------
+// Repr types
+
+// Hex is encoded as its repr, a hex string.
+type Hex []byte
+
+func (h Hex) MarshalAmino() (string, error) {
+	return hex.EncodeToString(h), nil
+}
+
+func (h *Hex) UnmarshalAmino(repr string) (err error) {
+	*h, err = hex.DecodeString(repr)
+	return
+}
+
+// ReprStruct covers the MarshalAmino repr path, also within lists, pointers
+// and interfaces.
+type ReprStruct struct {
+	Hex    Hex
+	HexSl  []Hex
+	HexPt  *Hex
+	Time   time.Time
+	Nested tests.Interface1
+}
+
+func (*ReprStruct) AssertInterface1() {}
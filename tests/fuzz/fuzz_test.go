@@ -0,0 +1,61 @@
+//go:build go1.18
+// +build go1.18
+
+package fuzz_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/tendermint/go-amino/tests/fuzz"
+)
+
+var cdc = fuzz.NewCodec()
+
+// FuzzBinary tests that decoding binary into any of fuzz.Types never panics,
+// and that what it decodes re-encodes canonically.
+func FuzzBinary(f *testing.F) {
+	f.Fuzz(func(t *testing.T, typ uint8, bz []byte) {
+		rt := fuzz.Types[int(typ)%len(fuzz.Types)]
+		ptr := reflect.New(rt).Interface()
+		if err := cdc.UnmarshalBinaryLengthPrefixed(bz, ptr); err != nil {
+			return
+		}
+		checkCanonical(t, ptr, cdc.MarshalBinaryLengthPrefixed, cdc.UnmarshalBinaryLengthPrefixed)
+	})
+}
+
+// FuzzJSON tests that decoding JSON into any of fuzz.Types never panics, and
+// that what it decodes re-encodes canonically.
+func FuzzJSON(f *testing.F) {
+	f.Fuzz(func(t *testing.T, typ uint8, bz []byte) {
+		rt := fuzz.Types[int(typ)%len(fuzz.Types)]
+		ptr := reflect.New(rt).Interface()
+		if err := cdc.UnmarshalJSON(bz, ptr); err != nil {
+			return
+		}
+		checkCanonical(t, ptr, cdc.MarshalJSON, cdc.UnmarshalJSON)
+	})
+}
+
+// Checks that encoding ptr, decoding it, and encoding it again gives the
+// same bytes.
+func checkCanonical(t *testing.T, ptr interface{},
+	marshal func(interface{}) ([]byte, error), unmarshal func([]byte, interface{}) error) {
+	bz, err := marshal(ptr)
+	if err != nil {
+		t.Fatalf("cannot encode decoded %T: %v", ptr, err)
+	}
+	ptr2 := reflect.New(reflect.TypeOf(ptr).Elem()).Interface()
+	if err = unmarshal(bz, ptr2); err != nil {
+		t.Fatalf("cannot decode encoded %T: %v\n%X", ptr, err, bz)
+	}
+	bz2, err := marshal(ptr2)
+	if err != nil {
+		t.Fatalf("cannot encode decoded %T: %v", ptr, err)
+	}
+	if !bytes.Equal(bz, bz2) {
+		t.Fatalf("%T is not canonical:\nencoded:   %X\nreencoded: %X", ptr, bz, bz2)
+	}
+}
@@ -113,8 +113,13 @@ func TestMultidimensionalSlices(t *testing.T) {
 		{1, 2},
 		{3, 4, 5}}
 
-	_, err := cdc.MarshalBinaryBare(s)
-	assert.Error(t, err, "expected error: multidimensional slices are not allowed")
+	bz, err := cdc.MarshalBinaryBare(s)
+	assert.NoError(t, err, "unexpected error: multidimensional slices are encoded as nested wrapper messages")
+
+	var s2 [][]int8
+	err = cdc.UnmarshalBinaryBare(bz, &s2)
+	assert.NoError(t, err)
+	assert.Equal(t, s, s2)
 }
 
 func TestMultidimensionalArrays(t *testing.T) {
@@ -122,8 +127,42 @@ func TestMultidimensionalArrays(t *testing.T) {
 		{1, 2},
 		{3, 4}}
 
-	_, err := cdc.MarshalBinaryBare(arr)
-	assert.Error(t, err, "expected error: multidimensional arrays are not allowed")
+	bz, err := cdc.MarshalBinaryBare(arr)
+	assert.NoError(t, err, "unexpected error: multidimensional arrays are encoded as nested wrapper messages")
+
+	var arr2 [2][2]int8
+	err = cdc.UnmarshalBinaryBare(bz, &arr2)
+	assert.NoError(t, err)
+	assert.Equal(t, arr, arr2)
+}
+
+func TestMultidimensionalStructSlice(t *testing.T) {
+	// message Inner { repeated sint32 values = 1; }
+	// message Outer { repeated Inner values = 1; }
+	type Outer struct {
+		Values [][]int8
+	}
+	o := Outer{Values: [][]int8{{1, -1}, {2}}}
+
+	ab, err := cdc.MarshalBinaryBare(o)
+	assert.NoError(t, err)
+
+	inner := func(vals ...int8) []byte {
+		packed := proto.NewBuffer(nil)
+		for _, v := range vals {
+			packed.EncodeZigzag32(uint64(v))
+		}
+		pb := proto.NewBuffer(nil)
+		pb.EncodeVarint(1<<3 | proto.WireBytes)
+		pb.EncodeRawBytes(packed.Bytes())
+		return pb.Bytes()
+	}
+	pb := proto.NewBuffer(nil)
+	pb.EncodeVarint(1<<3 | proto.WireBytes)
+	pb.EncodeRawBytes(inner(1, -1))
+	pb.EncodeVarint(1<<3 | proto.WireBytes)
+	pb.EncodeRawBytes(inner(2))
+	assert.Equal(t, pb.Bytes(), ab, "nested lists should be encoded like repeated wrapper messages")
 }
 
 func TestMultidimensionalByteArraysAndSlices(t *testing.T) {
@@ -0,0 +1,638 @@
+package amino
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+//----------------------------------------
+// Text values
+
+// The kinds of textValue.
+const (
+	textNull = iota
+	textBool
+	textNumber
+	textString
+	textBytes
+	textList
+	textObject
+	textInterface
+)
+
+// A textValue is a parsed value of the text format, e.g.
+//
+//	@bank/MsgSend {            # textInterface of a textObject
+//	  from: "cosmos1..."       # textString
+//	  amount: [10, 20]         # textList of textNumbers
+//	  memo: 0x0102             # textBytes
+//	}
+type textValue struct {
+	kind int
+	pos  int    // Offset in the text, for errors.
+	str  string // Unquoted for textString, hex for textBytes, else as written.
+
+	keys   []string     // For textObject, with the values.
+	values []*textValue // For textObject and textList.
+
+	name  string     // For textInterface, the concrete name.
+	value *textValue // For textInterface.
+}
+
+func (tv *textValue) kindString() string {
+	return [...]string{"null", "bool", "number", "string", "bytes", "list", "object", "interface"}[tv.kind]
+}
+
+// Parses bz as a single text value, with only whitespace and comments after.
+func parseText(bz []byte) (tv *textValue, err error) {
+	p := &textParser{text: string(bz)}
+	tv, err = p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return nil, p.errorf("unexpected %q after value", p.text[p.pos])
+	}
+	return tv, nil
+}
+
+type textParser struct {
+	text string
+	pos  int
+}
+
+// Returns an error at the current position, e.g. "amino:Text 3:5: ...".
+func (p *textParser) errorf(format string, args ...interface{}) error {
+	line := 1 + strings.Count(p.text[:p.pos], "\n")
+	col := 1 + p.pos - (strings.LastIndex(p.text[:p.pos], "\n") + 1)
+	return errors.Errorf("amino:Text %v:%v: %v", line, col, fmt.Sprintf(format, args...))
+}
+
+// Skips whitespace and # comments.
+func (p *textParser) skipSpace() {
+	for p.pos < len(p.text) {
+		switch c := p.text[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.text) && p.text[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Skips an optional comma separator.
+func (p *textParser) skipComma() {
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == ',' {
+		p.pos++
+	}
+}
+
+// Reads a word: an identifier, number or name.
+func (p *textParser) readWord() string {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if c <= ' ' || strings.IndexByte(`{}[]:,#"`, c) >= 0 {
+			break
+		}
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *textParser) readString() (string, error) {
+	start := p.pos
+	p.pos++ // Opening quote.
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case '\\':
+			p.pos += 2
+			continue
+		case '\n':
+			p.pos = start
+			return "", p.errorf("newline in string")
+		case '"':
+			p.pos++
+			s, err := strconv.Unquote(p.text[start:p.pos])
+			if err != nil {
+				p.pos = start
+				return "", p.errorf("invalid string: %v", err)
+			}
+			return s, nil
+		}
+		p.pos++
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+func (p *textParser) parseValue() (tv *textValue, err error) {
+	p.skipSpace()
+	if p.pos >= len(p.text) {
+		return nil, p.errorf("unexpected end of text")
+	}
+	tv = &textValue{pos: p.pos}
+	switch c := p.text[p.pos]; {
+
+	case c == '{':
+		tv.kind = textObject
+		p.pos++
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.text) {
+				return nil, p.errorf("unterminated object")
+			}
+			if p.text[p.pos] == '}' {
+				p.pos++
+				return tv, nil
+			}
+			// Read key.
+			var key string
+			if p.text[p.pos] == '"' {
+				key, err = p.readString()
+				if err != nil {
+					return nil, err
+				}
+			} else if key = p.readWord(); key == "" {
+				return nil, p.errorf("expected key, got %q", p.text[p.pos])
+			}
+			p.skipSpace()
+			if p.pos >= len(p.text) || p.text[p.pos] != ':' {
+				return nil, p.errorf("expected ':' after key %q", key)
+			}
+			p.pos++
+			// Read value.
+			var value *textValue
+			value, err = p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			tv.keys = append(tv.keys, key)
+			tv.values = append(tv.values, value)
+			p.skipComma()
+		}
+
+	case c == '[':
+		tv.kind = textList
+		p.pos++
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.text) {
+				return nil, p.errorf("unterminated list")
+			}
+			if p.text[p.pos] == ']' {
+				p.pos++
+				return tv, nil
+			}
+			var value *textValue
+			value, err = p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			tv.values = append(tv.values, value)
+			p.skipComma()
+		}
+
+	case c == '"':
+		tv.kind = textString
+		tv.str, err = p.readString()
+		return tv, err
+
+	case c == '@':
+		tv.kind = textInterface
+		p.pos++
+		if tv.name = p.readWord(); tv.name == "" {
+			return nil, p.errorf("expected concrete name after '@'")
+		}
+		tv.value, err = p.parseValue()
+		return tv, err
+
+	default:
+		word := p.readWord()
+		switch {
+		case word == "":
+			return nil, p.errorf("unexpected %q", c)
+		case word == "null":
+			tv.kind = textNull
+		case word == "true" || word == "false":
+			tv.kind = textBool
+		case strings.HasPrefix(word, "0x"):
+			tv.kind = textBytes
+		default:
+			tv.kind = textNumber
+		}
+		tv.str = word
+		return tv, nil
+	}
+}
+
+//----------------------------------------
+// cdc.decodeReflectText
+
+// Decodes tv into rv like decodeReflectJSON does.
+// CONTRACT: rv.CanAddr() is true.
+func (cdc *Codec) decodeReflectText(tv *textValue, info *TypeInfo, rv reflect.Value, fopts FieldOptions) (err error) {
+	if !rv.CanAddr() {
+		panic("rv not addressable")
+	}
+	if info.Type.Kind() == reflect.Interface && rv.Kind() == reflect.Ptr {
+		panic("should not happen")
+	}
+	if printLog {
+		spew.Printf("(D) decodeReflectText(tv: %v, info: %v, rv: %#v (%v), fopts: %v)\n",
+			tv.kindString(), info, rv.Interface(), rv.Type(), fopts)
+		defer func() {
+			fmt.Printf("(D) -> err: %v\n", err)
+		}()
+	}
+
+	// Special case for null for either interface, pointer, slice.
+	if tv.kind == textNull {
+		rv.Set(reflect.Zero(rv.Type()))
+		return
+	}
+
+	// Dereference-and-construct pointers all the way.
+	// This works for pointer-pointers.
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			newPtr := reflect.New(rv.Type().Elem())
+			rv.Set(newPtr)
+		}
+		rv = rv.Elem()
+	}
+
+	// Special case:
+	if rv.Type() == timeType {
+		if tv.kind != textString {
+			return textTypeError(tv, "an RFC3339 time string", rv.Type())
+		}
+		var t time.Time
+		t, err = time.Parse(time.RFC3339Nano, tv.str)
+		if err != nil {
+			return errors.Errorf("amino:Text time must be RFC3339: %v", err)
+		}
+		// Amino time strips the timezone.
+		rv.Set(reflect.ValueOf(t.UTC()))
+		return
+	}
+
+	// Handle override if a pointer to rv implements UnmarshalAmino.
+	if info.IsAminoUnmarshaler {
+		// First, decode repr instance from text.
+		var rrv = reflect.New(info.AminoUnmarshalReprType).Elem()
+		var rinfo *TypeInfo
+		rinfo, err = cdc.getTypeInfoWlock(info.AminoUnmarshalReprType)
+		if err != nil {
+			return
+		}
+		err = cdc.decodeReflectText(tv, rinfo, rrv, fopts)
+		if err != nil {
+			return
+		}
+		// Then, decode from repr instance.
+		uwrm := rv.Addr().MethodByName("UnmarshalAmino")
+		uwouts := uwrm.Call([]reflect.Value{rrv})
+		erri := uwouts[0].Interface()
+		if erri != nil {
+			err = erri.(error)
+		}
+		return
+	}
+
+	switch ikind := info.Type.Kind(); ikind {
+
+	//----------------------------------------
+	// Complex
+
+	case reflect.Interface:
+		err = cdc.decodeReflectTextInterface(tv, info, rv, fopts)
+
+	case reflect.Array, reflect.Slice:
+		err = cdc.decodeReflectTextList(tv, info, rv, fopts)
+
+	case reflect.Struct:
+		err = cdc.decodeReflectTextStruct(tv, info, rv, fopts)
+
+	case reflect.Map:
+		err = cdc.decodeReflectTextMap(tv, info, rv, fopts)
+
+	//----------------------------------------
+	// Signed, Unsigned
+
+	case reflect.Int64, reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8:
+		if tv.kind != textNumber {
+			return textTypeError(tv, "an integer", rv.Type())
+		}
+		var i int64
+		i, err = strconv.ParseInt(tv.str, 10, rv.Type().Bits())
+		if err != nil {
+			return
+		}
+		rv.SetInt(i)
+
+	case reflect.Uint64, reflect.Uint, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		if tv.kind != textNumber {
+			return textTypeError(tv, "an unsigned integer", rv.Type())
+		}
+		var u uint64
+		u, err = strconv.ParseUint(tv.str, 10, rv.Type().Bits())
+		if err != nil {
+			return
+		}
+		rv.SetUint(u)
+
+	//----------------------------------------
+	// Misc
+
+	case reflect.Float32, reflect.Float64:
+		if !fopts.Unsafe {
+			return errors.New("amino:Text float* support requires `amino:\"unsafe\"`")
+		}
+		var f float64
+		f, err = parseTextFloat(tv, rv.Type().Bits())
+		if err != nil {
+			return
+		}
+		rv.SetFloat(f)
+
+	case reflect.Bool:
+		if tv.kind != textBool {
+			return textTypeError(tv, "true or false", rv.Type())
+		}
+		rv.SetBool(tv.str == "true")
+
+	case reflect.String:
+		if tv.kind != textString {
+			return textTypeError(tv, "a string", rv.Type())
+		}
+		rv.SetString(tv.str)
+
+	case reflect.Complex128, reflect.Complex64:
+		if !fopts.Unsafe {
+			return errors.New("amino:Text complex* support requires `amino:\"unsafe\"`")
+		}
+		// Expect a two element list of the real and imaginary parts.
+		if tv.kind != textList || len(tv.values) != 2 {
+			return textTypeError(tv, "[real, imag]", rv.Type())
+		}
+		var parts [2]float64
+		for i, ptv := range tv.values {
+			parts[i], err = parseTextFloat(ptv, rv.Type().Bits()/2)
+			if err != nil {
+				return
+			}
+		}
+		rv.SetComplex(complex(parts[0], parts[1]))
+
+	//----------------------------------------
+	// Default
+
+	default:
+		panic(fmt.Sprintf("unsupported type %v", info.Type.Kind()))
+	}
+
+	return err
+}
+
+// CONTRACT: rv.CanAddr() is true.
+func (cdc *Codec) decodeReflectTextInterface(tv *textValue, iinfo *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(d) decodeReflectTextInterface")
+		defer func() {
+			fmt.Printf("(d) -> err: %v\n", err)
+		}()
+	}
+
+	if tv.kind != textInterface {
+		return textTypeError(tv, "@<concrete name> <value>", rv.Type())
+	}
+	if !rv.IsNil() {
+		// We don't strictly need to set it nil, but lets keep it here for a
+		// while in case we forget, for defensive purposes.
+		rv.Set(iinfo.ZeroValue)
+	}
+
+	// Get concrete type info.
+	var cinfo *TypeInfo
+	cinfo, err = cdc.getTypeInfoFromNameRlock(tv.name)
+	if err != nil {
+		return
+	}
+
+	// Construct the concrete type.
+	var crv, irvSet = constructConcreteType(cinfo)
+	if !irvSet.Type().AssignableTo(rv.Type()) {
+		return errors.Errorf("amino:Text %v is not a %v", tv.name, rv.Type())
+	}
+
+	// Decode into the concrete type.
+	err = cdc.decodeReflectText(tv.value, cinfo, crv, fopts)
+	if err != nil {
+		return
+	}
+	rv.Set(irvSet)
+	return
+}
+
+// CONTRACT: rv.CanAddr() is true.
+func (cdc *Codec) decodeReflectTextList(tv *textValue, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(d) decodeReflectTextList")
+		defer func() {
+			fmt.Printf("(d) -> err: %v\n", err)
+		}()
+	}
+	ert := info.Type.Elem()
+
+	// Special case: byte array or slice, in hex.
+	if ert.Kind() == reflect.Uint8 {
+		if tv.kind != textBytes {
+			return textTypeError(tv, "0x<hex bytes>", rv.Type())
+		}
+		var bz []byte
+		bz, err = hex.DecodeString(tv.str[2:])
+		if err != nil {
+			return errors.Errorf("amino:Text invalid hex bytes %v: %v", tv.str, err)
+		}
+		if rv.Kind() == reflect.Array {
+			if len(bz) != rv.Len() {
+				return errors.Errorf("amino:Text byte-length mismatch, got %v want %v", len(bz), rv.Len())
+			}
+			reflect.Copy(rv, reflect.ValueOf(bz))
+		} else if len(bz) == 0 {
+			// NOTE: We prefer nil slices.
+			rv.Set(info.ZeroValue)
+		} else {
+			rv.Set(reflect.ValueOf(bz).Convert(rv.Type()))
+		}
+		return
+	}
+
+	if tv.kind != textList {
+		return textTypeError(tv, "a list", rv.Type())
+	}
+	var einfo *TypeInfo
+	einfo, err = cdc.getTypeInfoWlock(ert)
+	if err != nil {
+		return
+	}
+	var length = len(tv.values)
+	var lrv = rv
+	if rv.Kind() == reflect.Array {
+		if length != rv.Len() {
+			return errors.Errorf("amino:Text length mismatch, got %v want %v", length, rv.Len())
+		}
+	} else if length == 0 {
+		// NOTE: We prefer nil slices.
+		rv.Set(info.ZeroValue)
+		return
+	} else {
+		lrv = reflect.MakeSlice(rv.Type(), length, length)
+	}
+	for i, etv := range tv.values {
+		err = cdc.decodeReflectText(etv, einfo, lrv.Index(i), fopts)
+		if err != nil {
+			return
+		}
+	}
+	rv.Set(lrv)
+	return
+}
+
+// CONTRACT: rv.CanAddr() is true.
+func (cdc *Codec) decodeReflectTextStruct(tv *textValue, info *TypeInfo, rv reflect.Value,
+	_ FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(d) decodeReflectTextStruct")
+		defer func() {
+			fmt.Printf("(d) -> err: %v\n", err)
+		}()
+	}
+	if tv.kind != textObject {
+		return textTypeError(tv, "an object", rv.Type())
+	}
+
+	// Map all JSON names (and aliases) to their fields.
+	var fields = make(map[string]*FieldInfo, len(info.Fields))
+	for i := range info.Fields {
+		field := &info.Fields[i]
+		if field.JSONSkip {
+			continue // e.g. amino:"-json"
+		}
+		fields[field.JSONName] = field
+		for _, alias := range field.JSONAliases {
+			if _, ok := fields[alias]; !ok {
+				fields[alias] = field
+			}
+		}
+	}
+
+	// Unlike JSON, unknown and duplicate keys are errors, as the text format
+	// is written by hand.
+	var values = make(map[*FieldInfo]*textValue, len(tv.keys))
+	for i, key := range tv.keys {
+		field, ok := fields[key]
+		if !ok {
+			return errors.Errorf("amino:Text unknown field %q for %v", key, rv.Type())
+		}
+		if _, ok := values[field]; ok {
+			return errors.Errorf("amino:Text duplicate field %q for %v", key, rv.Type())
+		}
+		values[field] = tv.values[i]
+	}
+
+	for i := range info.Fields {
+		field := &info.Fields[i]
+		if field.JSONSkip {
+			continue // e.g. amino:"-json"
+		}
+		var frv = rv.Field(field.Index)
+		var ftv, ok = values[field]
+		if !ok {
+			// Set nil/zero on frv.
+			frv.Set(reflect.Zero(frv.Type()))
+			continue
+		}
+		var finfo *TypeInfo
+		finfo, err = cdc.getTypeInfoWlock(field.Type)
+		if err != nil {
+			return
+		}
+		err = cdc.decodeReflectText(ftv, finfo, frv, field.FieldOptions)
+		if err != nil {
+			return
+		}
+	}
+	return nil
+}
+
+// CONTRACT: rv.CanAddr() is true.
+func (cdc *Codec) decodeReflectTextMap(tv *textValue, info *TypeInfo, rv reflect.Value,
+	fopts FieldOptions) (err error) {
+	if printLog {
+		fmt.Println("(d) decodeReflectTextMap")
+		defer func() {
+			fmt.Printf("(d) -> err: %v\n", err)
+		}()
+	}
+	if tv.kind != textObject {
+		return textTypeError(tv, "an object", rv.Type())
+	}
+	if rv.Type().Key().Kind() != reflect.String {
+		return errors.New("decodeReflectTextMap: key type must be string")
+	}
+	var vinfo *TypeInfo
+	vinfo, err = cdc.getTypeInfoWlock(rv.Type().Elem())
+	if err != nil {
+		return
+	}
+
+	var mrv = reflect.MakeMapWithSize(rv.Type(), len(tv.keys))
+	for i, key := range tv.keys {
+		krv := reflect.New(rv.Type().Key()).Elem()
+		krv.SetString(key)
+		if mrv.MapIndex(krv).IsValid() {
+			return errors.Errorf("amino:Text duplicate key %q", key)
+		}
+		vrv := reflect.New(rv.Type().Elem()).Elem()
+		err = cdc.decodeReflectText(tv.values[i], vinfo, vrv, fopts)
+		if err != nil {
+			return
+		}
+		mrv.SetMapIndex(krv, vrv)
+	}
+	rv.Set(mrv)
+	return nil
+}
+
+//----------------------------------------
+// Misc.
+
+func parseTextFloat(tv *textValue, bits int) (float64, error) {
+	if tv.kind != textNumber {
+		return 0, errors.Errorf("amino:Text expected a number, got %v", tv.kindString())
+	}
+	return strconv.ParseFloat(tv.str, bits)
+}
+
+func textTypeError(tv *textValue, want string, rt reflect.Type) error {
+	return errors.Errorf("amino:Text expected %v for %v, got %v at offset %v",
+		want, rt, tv.kindString(), tv.pos)
+}
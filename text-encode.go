@@ -0,0 +1,212 @@
+package amino
+
+import (
+	"encoding/hex"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//----------------------------------------
+// textFormat
+
+// textFormat is the Format of MarshalText.  Composite values are written over
+// several lines, indented by two spaces per level.
+type textFormat struct {
+	frames []textFrame // One per open list, struct or map.
+}
+
+type textFrame struct {
+	inline bool // Elements are written on one line.
+	length int  // The number of elements written.
+}
+
+var _ Format = (*textFormat)(nil)
+
+func newTextFormat() Format {
+	return &textFormat{}
+}
+
+func (tf *textFormat) EncodeNull(w io.Writer) error {
+	return writeStr(w, `null`)
+}
+
+func (tf *textFormat) EncodeBool(w io.Writer, b bool, _ FieldOptions) error {
+	return writeStr(w, strconv.FormatBool(b))
+}
+
+func (tf *textFormat) EncodeInt(w io.Writer, i int64, _ *TypeInfo, _ FieldOptions) error {
+	return writeStr(w, strconv.FormatInt(i, 10)) // Unlike JSON, never quoted.
+}
+
+func (tf *textFormat) EncodeUint(w io.Writer, u uint64, _ *TypeInfo, _ FieldOptions) error {
+	return writeStr(w, strconv.FormatUint(u, 10))
+}
+
+func (tf *textFormat) EncodeFloat(w io.Writer, f float64, info *TypeInfo, _ FieldOptions) error {
+	return writeStr(w, formatTextFloat(f, info.Type.Bits()))
+}
+
+func (tf *textFormat) EncodeComplex(w io.Writer, c complex128, info *TypeInfo, _ FieldOptions) error {
+	// Encoded as a two element list of the real and imaginary parts.
+	bits := info.Type.Bits() / 2
+	return writeStr(w, "["+formatTextFloat(real(c), bits)+", "+formatTextFloat(imag(c), bits)+"]")
+}
+
+func (tf *textFormat) EncodeString(w io.Writer, s string, _ FieldOptions) error {
+	return writeStr(w, strconv.Quote(s))
+}
+
+func (tf *textFormat) EncodeBytes(w io.Writer, bz []byte, _ FieldOptions) error {
+	return writeStr(w, "0x"+hex.EncodeToString(bz))
+}
+
+func (tf *textFormat) EncodeTime(w io.Writer, t time.Time, _ FieldOptions) error {
+	// Amino time strips the timezone.
+	t = t.Round(0).UTC()
+	return writeStr(w, strconv.Quote(t.Format(time.RFC3339Nano)))
+}
+
+func (tf *textFormat) BeginInterface(w io.Writer, cinfo *TypeInfo, _ FieldOptions) error {
+	return writeStr(w, "@"+cinfo.Name+" ")
+}
+
+func (tf *textFormat) EndInterface(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	return nil
+}
+
+// NOTE: Unlike JSON, nil slices are written as empty lists.
+func (tf *textFormat) EncodeNilSlice(w io.Writer, info *TypeInfo, fopts FieldOptions) error {
+	if info.Type.Elem().Kind() == reflect.Uint8 {
+		return tf.EncodeBytes(w, nil, fopts)
+	}
+	return writeStr(w, `[]`)
+}
+
+func (tf *textFormat) BeginList(w io.Writer, _, einfo *TypeInfo, _ int, _ FieldOptions) error {
+	// Scalars are written on one line, anything else one per line.
+	tf.frames = append(tf.frames, textFrame{inline: isTextScalar(einfo)})
+	return writeStr(w, `[`)
+}
+
+func (tf *textFormat) BeginElem(w io.Writer, index int) error {
+	frame := tf.top()
+	frame.length++
+	if !frame.inline {
+		return writeStr(w, tf.newline())
+	}
+	if index > 0 {
+		return writeStr(w, `, `)
+	}
+	return nil
+}
+
+func (tf *textFormat) EndList(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	return tf.end(w, `]`)
+}
+
+func (tf *textFormat) BeginStruct(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	tf.frames = append(tf.frames, textFrame{})
+	return writeStr(w, `{`)
+}
+
+// Fields are skipped as in JSON.
+func (tf *textFormat) SkipField(field *FieldInfo, rv reflect.Value) bool {
+	return SkipFieldJSON(field, rv)
+}
+
+func (tf *textFormat) BeginField(w io.Writer, field *FieldInfo) error {
+	tf.top().length++
+	return writeStr(w, tf.newline()+textKey(field.JSONName)+": ")
+}
+
+func (tf *textFormat) EndStruct(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	return tf.end(w, `}`)
+}
+
+func (tf *textFormat) BeginMap(w io.Writer, _ *TypeInfo, _ int, _ FieldOptions) error {
+	tf.frames = append(tf.frames, textFrame{})
+	return writeStr(w, `{`)
+}
+
+func (tf *textFormat) BeginKey(w io.Writer, key string) error {
+	tf.top().length++
+	return writeStr(w, tf.newline()+textKey(key)+": ")
+}
+
+func (tf *textFormat) EndMap(w io.Writer, _ *TypeInfo, _ FieldOptions) error {
+	return tf.end(w, `}`)
+}
+
+func (tf *textFormat) top() *textFrame {
+	return &tf.frames[len(tf.frames)-1]
+}
+
+// Returns a newline, indented for the elements of the top frame.
+func (tf *textFormat) newline() string {
+	return "\n" + strings.Repeat("  ", len(tf.frames))
+}
+
+// Pops the top frame and writes delim, on its own line if the elements were.
+func (tf *textFormat) end(w io.Writer, delim string) error {
+	frame := *tf.top()
+	tf.frames = tf.frames[:len(tf.frames)-1]
+	if !frame.inline && frame.length > 0 {
+		delim = tf.newline() + delim
+	}
+	return writeStr(w, delim)
+}
+
+//----------------------------------------
+// Misc.
+
+// Scalars (and bytes) are written on one line.
+func isTextScalar(info *TypeInfo) bool {
+	var rt = info.Type
+	if info.IsAminoMarshaler {
+		rt = info.AminoMarshalReprType
+	}
+	switch rt.Kind() {
+	case reflect.Interface, reflect.Struct, reflect.Map:
+		return rt == timeType
+	case reflect.Array, reflect.Slice:
+		return rt.Elem().Kind() == reflect.Uint8
+	default:
+		return true
+	}
+}
+
+// Keys are written bare if they are identifiers, else quoted.
+func textKey(key string) string {
+	if isTextIdent(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+func isTextIdent(s string) bool {
+	if s == "" || s == "null" || s == "true" || s == "false" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') ||
+			(i > 0 && '0' <= r && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func formatTextFloat(f float64, bits int) string {
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	// Keep floats distinguishable from ints.
+	for _, r := range s {
+		if r == '.' || r == 'e' || r == 'n' || r == 'N' || r == 'I' {
+			return s
+		}
+	}
+	return s + ".0"
+}
@@ -0,0 +1,119 @@
+package amino_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+type textFleet struct {
+	Name     string
+	Owner    []byte
+	Since    time.Time
+	Vehicles []Vehicle
+	Counts   map[string]int64
+	Grid     [][]int8
+	Sizes    [2]uint16
+	Flagship Vehicle `json:"flagship,omitempty"`
+}
+
+func TestMarshalText(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+	cdc.RegisterConcrete(textFleet{}, "our/fleet", nil)
+
+	fleet := textFleet{
+		Name:     "Fleet \"One\"",
+		Owner:    []byte{0xDE, 0xAD},
+		Since:    time.Date(2019, 1, 2, 3, 4, 5, 6, time.UTC),
+		Vehicles: []Vehicle{Car("Tesla"), Plane{Name: "Cessna", MaxAltitude: 10000}},
+		Counts:   map[string]int64{"b": -9007199254740993, "a": 1, "x y": 2},
+		Grid:     [][]int8{{1, -2}, {}},
+		Sizes:    [2]uint16{3, 4},
+	}
+	bz, err := cdc.MarshalText(fleet)
+	require.NoError(t, err)
+	assert.Equal(t, `@our/fleet {
+  Name: "Fleet \"One\""
+  Owner: 0xdead
+  Since: "2019-01-02T03:04:05.000000006Z"
+  Vehicles: [
+    @car "Tesla"
+    @plane {
+      Name: "Cessna"
+      MaxAltitude: 10000
+    }
+  ]
+  Counts: {
+    a: 1
+    b: -9007199254740993
+    "x y": 2
+  }
+  Grid: [
+    [1, -2]
+    []
+  ]
+  Sizes: [3, 4]
+}
+`, string(bz))
+
+	var fleet2 textFleet
+	require.NoError(t, cdc.UnmarshalText(bz, &fleet2))
+	fleet.Grid[1] = nil // Empty slices are decoded as nil.
+	assert.Equal(t, fleet, fleet2)
+}
+
+func TestUnmarshalText(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+	cdc.RegisterConcrete(textFleet{}, "our/fleet", nil)
+
+	// Comments, commas and quoted keys are allowed.
+	var fleet textFleet
+	err := cdc.UnmarshalText([]byte(`# A fleet.
+@our/fleet {
+  "Name": "f", # The name.
+  Vehicles: [@boat "b", @car "c",]
+  flagship: @plane {Name: "p", MaxAltitude: 1}
+  Counts: {}
+}`), &fleet)
+	require.NoError(t, err)
+	assert.Equal(t, textFleet{
+		Name:     "f",
+		Vehicles: []Vehicle{Boat("b"), Car("c")},
+		Counts:   map[string]int64{},
+		Flagship: Plane{Name: "p", MaxAltitude: 1},
+	}, fleet)
+
+	cases := []struct {
+		text string
+		err  string
+	}{
+		{`@our/fleet {Nom: "f"}`, `unknown field "Nom"`},
+		{`@our/fleet {Name: "f", Name: "g"}`, `duplicate field "Name"`},
+		{`@our/fleet {Name: 1}`, `expected a string`},
+		{`@our/fleet {Owner: 0xabc}`, `invalid hex bytes`},
+		{`@our/fleet {Sizes: [1]}`, `length mismatch`},
+		{`@our/fleet {Sizes: [1, 65536]}`, `out of range`},
+		{`@our/fleet {Since: "yesterday"}`, `RFC3339`},
+		{`@our/fleet {Vehicles: [@nope {}]}`, `unrecognized concrete type name nope`},
+		{`@our/fleet {Vehicles: ["Tesla"]}`, `expected @<concrete name>`},
+		{`@our/fleet {Vehicles: [@insuranceplan 1]}`, `is not a amino_test.Vehicle`},
+		{`@our/fleet {Name: "f"} }`, `1:24: unexpected '}'`},
+		{"@our/fleet {\n  Name: \"f\n}", `2:9: newline in string`},
+		{`@our/fleet {Name "f"}`, `expected ':'`},
+		{`@car "Tesla"`, `wanted to decode our/fleet but found car`},
+		{`{}`, `wanted to decode @our/fleet`},
+	}
+	for _, tc := range cases {
+		var fleet textFleet
+		err := cdc.UnmarshalText([]byte(tc.text), &fleet)
+		if assert.Error(t, err, tc.text) {
+			assert.Contains(t, err.Error(), tc.err, tc.text)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+// Package tsgen generates TypeScript types and helpers for the amino JSON
+// encoding of the types in a registry (see amino.RegistryDescription), for
+// clients like wallets that would otherwise reimplement it by hand.
+//
+// Every registered concrete type and named struct becomes a TypeScript type,
+// and every registered interface a discriminated union of the {"type","value"}
+// wrappers of its implementers, keyed by the amino name.  GenerateTests writes
+// a test file of golden vectors, JSON written by the Go codec, that must
+// typecheck against the generated types and round-trip through them.
+package tsgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/vectors"
+)
+
+// Header is written at the top of generated files.
+const Header = "// Code generated by aminogen. DO NOT EDIT.\n"
+
+// Generator maps the Go types of a registry to TypeScript types.
+type Generator struct {
+	types map[string]amino.TypeDescription
+	names map[string]string // Go type to TypeScript name, for declared types.
+}
+
+// NewGenerator returns a Generator for the types in rd.  It fails if two
+// types would get the same TypeScript name.
+func NewGenerator(rd amino.RegistryDescription) (*Generator, error) {
+	g := &Generator{
+		types: make(map[string]amino.TypeDescription, len(rd.Types)),
+		names: make(map[string]string),
+	}
+	for _, td := range rd.Types {
+		if _, ok := g.types[td.Type]; ok {
+			return nil, fmt.Errorf("type %v is described twice", td.Type)
+		}
+		g.types[td.Type] = td
+	}
+
+	// Name the declared types by their Go name, or where that is ambiguous,
+	// qualified by as much of their package path as tells them apart.
+	var byName = make(map[string][]string)
+	var declared []string
+	for _, td := range rd.Types {
+		if isDeclared(td) {
+			declared = append(declared, td.Type)
+			byName[goName(td.Type)] = append(byName[goName(td.Type)], td.Type)
+		}
+	}
+	var named = make(map[string]string) // TypeScript name to Go type.
+	for _, rt := range declared {
+		name := exported(qualifiedName(rt, byName[goName(rt)]))
+		if other, ok := named[name]; ok {
+			return nil, fmt.Errorf("types %v and %v are both named %v in TypeScript", other, rt, name)
+		}
+		named[name] = rt
+		g.names[rt] = name
+	}
+	return g, nil
+}
+
+// Generate writes the TypeScript declarations of the types in rd to w.
+func Generate(w io.Writer, rd amino.RegistryDescription) error {
+	g, err := NewGenerator(rd)
+	if err != nil {
+		return err
+	}
+	return g.Generate(w)
+}
+
+// Generate writes the TypeScript declarations of the types to w.
+func (g *Generator) Generate(w io.Writer) error {
+	p := &printer{w: w}
+	p.printf("%v", Header)
+	p.printf(`
+/** The JSON wrapper of a registered concrete value, e.g. in an interface. */
+export interface Wrapped<T extends string, V> {
+  type: T;
+  value: V;
+}
+
+/** Wraps value as the registered concrete type named type. */
+export function wrap<T extends string, V>(type: T, value: V): Wrapped<T, V> {
+  return { type, value };
+}
+
+/**
+ * Returns the value of u if it is of the registered concrete type named
+ * type, or else undefined.
+ */
+export function unwrap<U extends Wrapped<string, unknown>, T extends U["type"]>(
+  u: U | null | undefined,
+  type: T,
+): Extract<U, { type: T }>["value"] | undefined {
+  return u != null && u.type === type ? (u.value as Extract<U, { type: T }>["value"]) : undefined;
+}
+`)
+
+	for _, rt := range g.declared() {
+		td := g.types[rt]
+		name := g.names[rt]
+		p.printf("\n")
+		switch {
+		case td.Kind == "interface":
+			if err := g.generateInterface(p, td, name); err != nil {
+				return err
+			}
+		case td.Kind == "struct" && td.ReprType == "":
+			p.printf("/** %v */\n", docComment(td))
+			p.printf("export interface %v ", name)
+			if err := g.generateFields(p, td, "", ""); err != nil {
+				return err
+			}
+			p.printf("\n")
+		default:
+			ts, err := g.underlying(td, "")
+			if err != nil {
+				return err
+			}
+			p.printf("/** %v */\n", docComment(td))
+			p.printf("export type %v = %v;\n", name, ts)
+		}
+		if td.Registered {
+			p.printf(`
+export const %[1]vName = %[2]v;
+
+export function wrap%[1]v(value: %[1]v): Wrapped<typeof %[1]vName, %[1]v> {
+  return wrap(%[1]vName, value);
+}
+`, name, strconv.Quote(td.Name))
+		}
+	}
+	return p.err
+}
+
+func (g *Generator) generateInterface(p *printer, td amino.TypeDescription, name string) error {
+	var cases []string
+	var names []string
+	for _, crt := range td.Implementers {
+		ctd, ok := g.types[crt]
+		if !ok {
+			return fmt.Errorf("unknown implementer %v of %v", crt, td.Type)
+		}
+		cases = append(cases, fmt.Sprintf("Wrapped<%v, %v>", strconv.Quote(ctd.Name), g.names[crt]))
+		names = append(names, strconv.Quote(ctd.Name))
+	}
+	sort.Strings(cases)
+	sort.Strings(names)
+
+	p.printf("/** %v, keyed by the amino name. */\n", docComment(td))
+	p.printf("export type %v =\n  | %v;\n", name, strings.Join(cases, "\n  | "))
+	p.printf(`
+export const %[1]vNames: readonly string[] = [%[2]v];
+
+export function is%[1]v(v: unknown): v is %[1]v {
+  return typeof v === "object" && v !== null && %[1]vNames.includes((v as { type?: string }).type as string);
+}
+`, name, strings.Join(names, ", "))
+	return nil
+}
+
+// Writes the fields of the struct td as an object type, indented by indent.
+func (g *Generator) generateFields(p *printer, td amino.TypeDescription, indent string, qual string) error {
+	p.printf("{\n")
+	for _, fd := range td.Fields {
+		if fd.JSONSkip {
+			continue // e.g. amino:"-json"
+		}
+		ts, err := g.fieldType(fd, qual)
+		if err != nil {
+			return fmt.Errorf("field %v.%v: %v", td.Type, fd.Name, err)
+		}
+		var optional = ""
+		if fd.Optional || fd.JSONOmitEmpty {
+			optional = "?"
+		}
+		p.printf("%v  %v%v: %v;\n", indent, tsKey(fd.JSONName), optional, ts)
+	}
+	p.printf("%v}", indent)
+	return nil
+}
+
+func (g *Generator) fieldType(fd amino.FieldDescription, qual string) (string, error) {
+	if fd.Optional {
+		// Optional fields are written iff non-nil, so they are never null.
+		return g.typeOf(strings.TrimLeft(fd.Type, "*"), qual)
+	}
+	return g.typeOf(fd.Type, qual)
+}
+
+// TypeOf returns the TypeScript type of the amino JSON encoding of the Go
+// type rt, e.g. "string | null" for "*int64".
+func (g *Generator) TypeOf(rt string) (string, error) {
+	return g.typeOf(rt, "")
+}
+
+// Like TypeOf, but declared types are qualified by qual, e.g. "amino.".
+func (g *Generator) typeOf(rt string, qual string) (string, error) {
+	td, ok := g.types[rt]
+	if !ok {
+		return "", fmt.Errorf("unknown type %v", rt)
+	}
+	if name, ok := g.names[rt]; ok {
+		if td.Kind == "interface" {
+			return qual + name + " | null", nil // Nil interfaces are null.
+		}
+		return qual + name, nil
+	}
+	return g.underlying(td, qual)
+}
+
+// Returns the TypeScript type of td, without its declared name.
+func (g *Generator) underlying(td amino.TypeDescription, qual string) (string, error) {
+	if td.Type == "time.Time" {
+		return "string", nil // RFC3339 in UTC.
+	}
+	if td.ReprType != "" {
+		return g.typeOf(td.ReprType, qual)
+	}
+
+	switch td.Kind {
+	case "ptr":
+		return g.nullable(td.Elem, qual)
+	case "slice", "array":
+		if isByte(td.Elem) {
+			// Base64, or null for nil slices.
+			if td.Kind == "slice" {
+				return "string | null", nil
+			}
+			return "string", nil
+		}
+		ets, err := g.typeOf(td.Elem, qual)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(ets, " ") {
+			ets = "(" + ets + ")"
+		}
+		if td.Kind == "slice" {
+			return ets + "[] | null", nil // Nil slices are null.
+		}
+		return ets + "[]", nil
+	case "map":
+		vts, err := g.typeOf(td.Elem, qual)
+		if err != nil {
+			return "", err
+		}
+		return "{ [key: string]: " + vts + " }", nil
+	case "struct":
+		// Anonymous structs are inlined.
+		var sb strings.Builder
+		p := &printer{w: &sb}
+		if err := g.generateFields(p, td, "", qual); err != nil {
+			return "", err
+		}
+		return strings.Join(strings.Fields(sb.String()), " "), p.err
+	case "interface":
+		return "null", nil // Without implementers, only nil can be written.
+	case "int64", "int", "uint64", "uint":
+		return "string", nil // Quoted, as JS can't handle 64-bit ints.
+	case "int32", "int16", "int8", "uint32", "uint16", "uint8", "float64", "float32":
+		return "number", nil
+	case "complex128", "complex64":
+		return "[number, number]", nil
+	case "bool":
+		return "boolean", nil
+	case "string":
+		return "string", nil
+	default:
+		return "", fmt.Errorf("unsupported kind %v of %v", td.Kind, td.Type)
+	}
+}
+
+// Returns the type of rt or null, e.g. for pointers.
+func (g *Generator) nullable(rt string, qual string) (string, error) {
+	ts, err := g.typeOf(rt, qual)
+	if err != nil || strings.HasSuffix(ts, " | null") {
+		return ts, err
+	}
+	return ts + " | null", nil
+}
+
+// Returns the declared Go types, sorted by TypeScript name.
+func (g *Generator) declared() []string {
+	var rts []string
+	for rt := range g.names {
+		rts = append(rts, rt)
+	}
+	sort.Slice(rts, func(i, j int) bool { return g.names[rts[i]] < g.names[rts[j]] })
+	return rts
+}
+
+// Types are declared if they are registered concrete types, registered
+// interfaces or named structs.
+func isDeclared(td amino.TypeDescription) bool {
+	switch {
+	case td.Registered:
+		return true
+	case td.Type == "time.Time" || !isNamed(td.Type):
+		return false
+	default:
+		return td.Kind == "struct" || (td.Kind == "interface" && len(td.Implementers) > 0)
+	}
+}
+
+//----------------------------------------
+// Vectors
+
+// GenerateTests writes a TypeScript test of the golden vectors to w, which
+// imports the generated types from module.  The vectors are as written by
+// vectors.Generate, e.g. by aminovectors, and read by vectors.Read.
+// Each vector's JSON must typecheck as its wrapped type, and be unchanged by
+// JSON.stringify and unwrapping and rewrapping.
+func (g *Generator) GenerateTests(w io.Writer, module string, vs []vectors.Vector) error {
+	p := &printer{w: w}
+	p.printf("%v", Header)
+	p.printf(`
+import { strict as assert } from "assert";
+import * as amino from %v;
+
+function check(name: string, v: unknown, json: string) {
+  assert.equal(JSON.stringify(v), json, name);
+}
+`, strconv.Quote(module))
+
+	var counts = make(map[string]int)
+	for i, vector := range vs {
+		// Named as the files of vectors.Generate, e.g. "tests/Foo-0".
+		name := fmt.Sprintf("%v-%v", vector.Name, counts[vector.Name])
+		counts[vector.Name]++
+
+		td, ok := g.types[vector.GoType]
+		if !ok {
+			return fmt.Errorf("vector %v: unknown type %v", name, vector.GoType)
+		}
+		if !td.Registered {
+			return fmt.Errorf("vector %v: %v is not registered", name, vector.GoType)
+		}
+		ts, err := g.typeOf(vector.GoType, "amino.")
+		if err != nil {
+			return fmt.Errorf("vector %v: %v", name, err)
+		}
+		// Registered concrete values are wrapped, as in MarshalJSON.
+		ts = fmt.Sprintf("amino.Wrapped<typeof amino.%vName, %v>", g.names[vector.GoType], ts)
+		js, err := compactJSON(vector.JSON)
+		if err != nil {
+			return fmt.Errorf("vector %v: %v", name, err)
+		}
+
+		p.printf("\n// %v\n", name)
+		p.printf("const v%v: %v = %v;\n", i, ts, js)
+		p.printf("check(%v, v%v, %v);\n", strconv.Quote(name), i, strconv.Quote(js))
+		p.printf("check(%[1]v, amino.wrap%[2]v(amino.unwrap(v%[3]v, amino.%[2]vName)!), %[4]v);\n",
+			strconv.Quote(name+" rewrapped"), g.names[vector.GoType], i, strconv.Quote(js))
+	}
+	return p.err
+}
+
+//----------------------------------------
+// Misc.
+
+// printer writes formatted output, keeping the first error.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func docComment(td amino.TypeDescription) string {
+	if td.Registered {
+		return fmt.Sprintf("%v, registered as %v", td.Type, strconv.Quote(td.Name))
+	}
+	return td.Type
+}
+
+var namedRe = regexp.MustCompile(`^[\w/.-]*\.\w+$`)
+
+// Returns whether rt is a named type of some package, e.g.
+// "github.com/org/tests.Foo".
+func isNamed(rt string) bool {
+	return namedRe.MatchString(rt)
+}
+
+// Returns the name of a named type, e.g. "Foo" for "github.com/org/tests.Foo".
+func goName(rt string) string {
+	return rt[strings.LastIndex(rt, ".")+1:]
+}
+
+// Returns the name of rt, one of the named types rts of the same Go name,
+// qualified by the fewest last elements of their package paths that are
+// unique among rts, e.g. "b/tests/Foo" for "github.com/b/tests.Foo" among
+// "github.com/a/tests.Foo".
+func qualifiedName(rt string, rts []string) string {
+	if len(rts) < 2 {
+		return goName(rt)
+	}
+	for n := 1; ; n++ {
+		var seen = make(map[string]bool)
+		for _, other := range rts {
+			seen[pkgSuffix(other, n)] = true
+		}
+		if len(seen) == len(rts) || pkgSuffix(rt, n) == goPackagePath(rt) {
+			return pkgSuffix(rt, n) + "/" + goName(rt)
+		}
+	}
+}
+
+// Returns the package path of a named type, e.g. "github.com/org/tests" for
+// "github.com/org/tests.Foo".
+func goPackagePath(rt string) string {
+	return rt[:strings.LastIndex(rt, ".")]
+}
+
+// Returns the last n elements of the package path of rt.
+func pkgSuffix(rt string, n int) string {
+	elems := strings.Split(goPackagePath(rt), "/")
+	if n > len(elems) {
+		n = len(elems)
+	}
+	return strings.Join(elems[len(elems)-n:], "/")
+}
+
+func exported(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' || r == '/' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = []rune(strings.ToUpper(string(r)))[0]
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func isByte(rt string) bool {
+	return rt == "uint8"
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// Keys are written bare if they are identifiers, else quoted.
+func tsKey(key string) string {
+	if identRe.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+func compactJSON(bz []byte) (string, error) {
+	var buf bytes.Buffer
+	err := json.Compact(&buf, bz)
+	return buf.String(), err
+}
@@ -0,0 +1,180 @@
+package tsgen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/tsgen"
+	"github.com/tendermint/go-amino/vectors"
+)
+
+type Shape interface{}
+
+type Square struct {
+	Side int64 `json:"side"`
+}
+
+type Label string
+
+type Drawing struct {
+	Title   string            `json:"title"`
+	Created time.Time         `json:"created"`
+	Shapes  []Shape           `json:"shapes"`
+	Main    Shape             `json:"main,omitempty"`
+	Hash    [4]byte           `json:"hash"`
+	Tags    map[string]uint16 `json:"tags"`
+	Parent  *Drawing          `json:"parent" amino:"optional"`
+	Note    *struct {
+		Text string `json:"text"`
+	} `json:"note"`
+	Hidden int `amino:"-json"`
+}
+
+func newCodec() *amino.Codec {
+	cdc := amino.NewCodec()
+	cdc.RegisterInterface((*Shape)(nil), nil)
+	cdc.RegisterConcrete(Square{}, "tsgen/Square", nil)
+	cdc.RegisterConcrete(Label(""), "tsgen/Label", nil)
+	cdc.RegisterConcrete(&Drawing{}, "tsgen/Drawing", nil)
+	return cdc
+}
+
+func TestGenerate(t *testing.T) {
+	cdc := newCodec()
+	rd, err := cdc.DescribeRegistry()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, tsgen.Generate(&buf, rd))
+	ts := buf.String()
+	assert.True(t, strings.HasPrefix(ts, tsgen.Header))
+
+	// Structs are interfaces, with their JSON names.
+	assert.Contains(t, ts, `
+/** github.com/tendermint/go-amino/tsgen_test.Drawing, registered as "tsgen/Drawing" */
+export interface Drawing {
+  title: string;
+  created: string;
+  shapes: (Shape | null)[] | null;
+  main?: Shape | null;
+  hash: string;
+  tags: { [key: string]: number };
+  parent?: Drawing;
+  note: { text: string; } | null;
+}
+
+export const DrawingName = "tsgen/Drawing";
+
+export function wrapDrawing(value: Drawing): Wrapped<typeof DrawingName, Drawing> {
+  return wrap(DrawingName, value);
+}
+`)
+	assert.Contains(t, ts, `
+/** github.com/tendermint/go-amino/tsgen_test.Square, registered as "tsgen/Square" */
+export interface Square {
+  side: string;
+}
+`)
+	assert.Contains(t, ts, `
+/** github.com/tendermint/go-amino/tsgen_test.Label, registered as "tsgen/Label" */
+export type Label = string;
+`)
+
+	// Interfaces are unions of the wrappers of their implementers.
+	assert.Contains(t, ts, `
+/** github.com/tendermint/go-amino/tsgen_test.Shape, keyed by the amino name. */
+export type Shape =
+  | Wrapped<"tsgen/Drawing", Drawing>
+  | Wrapped<"tsgen/Label", Label>
+  | Wrapped<"tsgen/Square", Square>;
+
+export const ShapeNames: readonly string[] = ["tsgen/Drawing", "tsgen/Label", "tsgen/Square"];
+`)
+}
+
+func TestNewGeneratorNames(t *testing.T) {
+	structs := func(rts ...string) (rd amino.RegistryDescription) {
+		for _, rt := range rts {
+			rd.Types = append(rd.Types, amino.TypeDescription{Type: rt, Kind: "struct"})
+		}
+		return rd
+	}
+
+	// Types of the same name are qualified by enough of their package path.
+	g, err := tsgen.NewGenerator(structs("github.com/a/rv.Inner", "github.com/b/rv.Inner", "github.com/b/rv.Outer"))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, g.Generate(&buf))
+	assert.Contains(t, buf.String(), "export interface ARvInner {")
+	assert.Contains(t, buf.String(), "export interface BRvInner {")
+	assert.Contains(t, buf.String(), "export interface Outer {")
+
+	_, err = tsgen.NewGenerator(structs("github.com/a/rv.Inner", "github.com/b/rv.Inner", "github.com/c/x.ARvInner"))
+	assert.EqualError(t, err, "types github.com/a/rv.Inner and github.com/c/x.ARvInner are both named ARvInner in TypeScript")
+
+	_, err = tsgen.NewGenerator(structs("github.com/a/rv.Inner", "github.com/a/rv.Inner"))
+	assert.EqualError(t, err, "type github.com/a/rv.Inner is described twice")
+}
+
+// Frame is of registered types that can be encoded in binary, as vectors are.
+type Frame struct {
+	Shape Shape `json:"shape"`
+}
+
+func TestGenerateTests(t *testing.T) {
+	cdc := amino.NewCodec()
+	cdc.RegisterInterface((*Shape)(nil), nil)
+	cdc.RegisterConcrete(Square{}, "tsgen/Square", nil)
+	cdc.RegisterConcrete(Label(""), "tsgen/Label", nil)
+	cdc.RegisterConcrete(Frame{}, "tsgen/Frame", nil)
+	rd, err := cdc.DescribeRegistry()
+	require.NoError(t, err)
+
+	// The vectors are as written by aminovectors.
+	dir, err := ioutil.TempDir("", "vectors")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, vectors.Generate(cdc, dir, &vectors.Options{Samples: 2}))
+	vs, err := vectors.Read(dir)
+	require.NoError(t, err)
+	require.Len(t, vs, 6)
+
+	g, err := tsgen.NewGenerator(rd)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, g.GenerateTests(&buf, "./amino", vs))
+	ts := buf.String()
+	assert.Contains(t, ts, `import * as amino from "./amino";`)
+	for i, want := range []struct{ name, typ string }{
+		{"tsgen/Frame-0", "Frame"},
+		{"tsgen/Frame-1", "Frame"},
+		{"tsgen/Label-0", "Label"},
+		{"tsgen/Label-1", "Label"},
+		{"tsgen/Square-0", "Square"},
+		{"tsgen/Square-1", "Square"},
+	} {
+		var js bytes.Buffer
+		require.NoError(t, json.Compact(&js, vs[i].JSON))
+		assert.Contains(t, ts, fmt.Sprintf(`
+// %[1]v
+const v%[2]v: amino.Wrapped<typeof amino.%[3]vName, amino.%[3]v> = %[4]v;
+check(%[1]q, v%[2]v, %[4]q);
+check("%[1]v rewrapped", amino.wrap%[3]v(amino.unwrap(v%[2]v, amino.%[3]vName)!), %[4]q);
+`, want.name, i, want.typ, js.String()))
+	}
+
+	// Vectors must be of known types.
+	vs = append(vs, vectors.Vector{Name: "uint32", GoType: "uint32", JSON: []byte(`"1"`)})
+	err = g.GenerateTests(&buf, "./amino", vs)
+	assert.EqualError(t, err, "vector uint32-0: unknown type uint32")
+}
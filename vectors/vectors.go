@@ -0,0 +1,459 @@
+// Package vectors makes golden test vectors of the types registered with an
+// amino codec: samples made by gofuzz, in each of the amino encodings.  Ports
+// of amino to other languages can check that they decode and encode each
+// vector the same way (see cmd/aminovectors).
+package vectors
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/pkg/errors"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// Vector is a sample value of a registered concrete type in each of the amino
+// encodings.  Ports of amino to other languages can check that they decode
+// and encode each vector the same way.
+type Vector struct {
+	Name              string          `json:"name"`    // The registered name.
+	GoType            string          `json:"go_type"` // TypeString, e.g. "github.com/tendermint/go-amino/tests.PrimitivesStruct"
+	JSON              json.RawMessage `json:"json"`
+	BinaryHex         string          `json:"binary_hex"`
+	LengthPrefixedHex string          `json:"length_prefixed_hex"`
+}
+
+// Options are the options of Vectors, Samples and Generate.
+type Options struct {
+	Seed    int64 // The gofuzz seed.  The samples are the same for the same seed.
+	Samples int   // The number of samples per registered type, 4 if 0.
+}
+
+// Vectors returns the samples of Samples, encoded.
+func Vectors(cdc *amino.Codec, opts *Options) ([]Vector, error) {
+	samples, err := newSamples(cdc, opts)
+	if err != nil {
+		return nil, err
+	}
+	var vectors = make([]Vector, 0, len(samples))
+	for _, sample := range samples {
+		v, err := newVector(cdc, sample.info, sample.ptr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "sample %v of %v", sample.index, sample.info.Name)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+// Samples returns pointers to samples of every registered concrete type, in
+// order of registration, made by gofuzz.  Each type is fuzzed from
+// opts.Seed afresh, so registering more types doesn't change the samples of
+// the others.  Interface values are set to one of their registered
+// implementers, or nil.
+//
+// The samples are as amino would decode them, e.g. pointers to zero values
+// are nil, so that they survive a round trip.
+func Samples(cdc *amino.Codec, opts *Options) ([]interface{}, error) {
+	samples, err := newSamples(cdc, opts)
+	if err != nil {
+		return nil, err
+	}
+	var ptrs = make([]interface{}, len(samples))
+	for i, sample := range samples {
+		ptrs[i] = sample.ptr
+	}
+	return ptrs, nil
+}
+
+type fuzzedSample struct {
+	info  *amino.TypeInfo
+	index int
+	ptr   interface{}
+}
+
+func newSamples(cdc *amino.Codec, vopts *Options) ([]fuzzedSample, error) {
+	var opts Options
+	if vopts != nil {
+		opts = *vopts
+	}
+	if opts.Samples <= 0 {
+		opts.Samples = 4
+	}
+
+	cinfos := cdc.ConcreteTypes()
+	fuzzFuncs, err := fuzzFuncs(cdc, cinfos)
+	if err != nil {
+		return nil, err
+	}
+	var samples []fuzzedSample
+	for _, cinfo := range cinfos {
+		f := fuzz.New().
+			RandSource(rand.NewSource(opts.Seed)).
+			NilChance(nilChance).
+			NumElements(0, 3).
+			MaxDepth(10).
+			Funcs(fuzzFuncs...)
+		for i := 0; i < opts.Samples; i++ {
+			ptr, err := fuzzSample(f, cinfo.Type)
+			if err != nil {
+				return nil, errors.Wrapf(err, "sample %v of %v", i, cinfo.Name)
+			}
+			samples = append(samples, fuzzedSample{cinfo, i, ptr})
+		}
+	}
+	return samples, nil
+}
+
+// Generate writes the Vectors to dir, one JSON file per sample, named after
+// the registered name, e.g. "com.tendermint_PubKeyEd25519-0.json".  Vector
+// files already in dir are removed first, so that none are left stale.
+func Generate(cdc *amino.Codec, dir string, opts *Options) error {
+	vectors, err := Vectors(cdc, opts)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	stale, err := vectorFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range stale {
+		if err = os.Remove(file); err != nil {
+			return err
+		}
+	}
+	var counts = make(map[string]int)
+	for _, v := range vectors {
+		name := fileRE.ReplaceAllString(v.Name, "_")
+		file := filepath.Join(dir, fmt.Sprintf("%v-%v.json", name, counts[name]))
+		counts[name]++
+		bz, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(file, append(bz, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads the Vectors written to dir by Generate, in order of their
+// registered names and then of their samples.
+func Read(dir string) ([]Vector, error) {
+	files, err := vectorFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no vectors in %v", dir)
+	}
+	// Order "x-2.json" before "x-10.json".
+	sort.Slice(files, func(i, j int) bool {
+		ni, si := splitFile(files[i])
+		nj, sj := splitFile(files[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return si < sj
+	})
+	var vectors = make([]Vector, 0, len(files))
+	for _, file := range files {
+		bz, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var v Vector
+		if err = json.Unmarshal(bz, &v); err != nil {
+			return nil, errors.Wrapf(err, "invalid vector %v", file)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+// Returns the files in dir named as by Generate.
+func vectorFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var vfiles []string
+	for _, file := range files {
+		if _, index := splitFile(file); index >= 0 {
+			vfiles = append(vfiles, file)
+		}
+	}
+	return vfiles, nil
+}
+
+// Splits a vector file name into the name and the sample index, or -1 if it
+// isn't named as by Generate.
+func splitFile(file string) (string, int) {
+	base := strings.TrimSuffix(filepath.Base(file), ".json")
+	i := strings.LastIndex(base, "-")
+	if i < 0 {
+		return base, -1
+	}
+	index, err := strconv.Atoi(base[i+1:])
+	if err != nil {
+		return base, -1
+	}
+	return base[:i], index
+}
+
+const nilChance = 0.2
+
+// Characters not allowed in vector file names.
+var fileRE = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// The range of times amino can encode, as seconds since the Unix epoch.
+var (
+	minSeconds = time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	maxSeconds = time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+)
+
+// Returns a pointer to a fuzzed and normalized value of type rt.
+func fuzzSample(f *fuzz.Fuzzer, rt reflect.Type) (ptr interface{}, err error) {
+	defer func() {
+		// gofuzz panics on types it can't handle, e.g. chans.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot fuzz: %v", r)
+		}
+	}()
+
+	var rv = reflect.New(rt)
+	f.Fuzz(rv.Interface())
+	normalizeSample(rv.Elem())
+	return rv.Interface(), nil
+}
+
+// Sets to nil what amino decodes as nil: empty slices, and pointers to
+// default values and zero structs, as they are not encoded.
+func normalizeSample(rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if !rv.IsNil() {
+			normalizeSample(rv.Elem())
+		}
+	case reflect.Interface:
+		if rv.IsNil() || !rv.CanSet() {
+			return
+		}
+		// The concrete value isn't addressable, so normalize a copy.
+		crv := reflect.New(rv.Elem().Type()).Elem()
+		crv.Set(rv.Elem())
+		normalizeSample(crv)
+		rv.Set(crv)
+	case reflect.Array, reflect.Slice:
+		if rv.Kind() == reflect.Slice && rv.Len() == 0 && rv.CanSet() {
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		for i := 0; i < rv.Len(); i++ {
+			erv := rv.Index(i)
+			normalizeSample(erv)
+			if erv.CanSet() && isZeroPointer(erv) {
+				erv.Set(reflect.Zero(erv.Type()))
+			}
+		}
+	case reflect.Map:
+		for _, key := range rv.MapKeys() {
+			vrv := reflect.New(rv.Type().Elem()).Elem()
+			vrv.Set(rv.MapIndex(key))
+			normalizeSample(vrv)
+			rv.SetMapIndex(key, vrv)
+		}
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			return
+		}
+		for i := 0; i < rv.NumField(); i++ {
+			frv := rv.Field(i)
+			if !frv.CanSet() {
+				continue // Unexported.
+			}
+			normalizeSample(frv)
+			if isZeroPointer(frv) {
+				frv.Set(reflect.Zero(frv.Type()))
+			}
+		}
+	}
+}
+
+// Returns whether rv is a non-nil (possibly nested) pointer to a default
+// value or a zero struct.
+func isZeroPointer(rv reflect.Value) bool {
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false
+	}
+	erv := reflect.Indirect(rv)
+	for erv.Kind() == reflect.Ptr && !erv.IsNil() {
+		erv = erv.Elem()
+	}
+	if isDefaultValue(erv) {
+		return true
+	}
+	if erv.Kind() != reflect.Struct || erv.Type() == timeType {
+		return false
+	}
+	return reflect.DeepEqual(erv.Interface(), reflect.Zero(erv.Type()).Interface())
+}
+
+// Encodes the sample ptr of cinfo.
+func newVector(cdc *amino.Codec, cinfo *amino.TypeInfo, ptr interface{}) (v Vector, err error) {
+	defer func() {
+		// The binary encoder panics on e.g. maps.
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot encode: %v", r)
+		}
+	}()
+
+	v.Name = cinfo.Name
+	v.GoType = amino.TypeString(cinfo.Type)
+	if v.JSON, err = cdc.MarshalJSON(ptr); err != nil {
+		return
+	}
+	var bz []byte
+	if bz, err = cdc.MarshalBinaryBare(ptr); err != nil {
+		return
+	}
+	v.BinaryHex = hex.EncodeToString(bz)
+	if bz, err = cdc.MarshalBinaryLengthPrefixed(ptr); err != nil {
+		return
+	}
+	v.LengthPrefixedHex = hex.EncodeToString(bz)
+	return
+}
+
+// Returns the gofuzz functions for amino: times within the range amino can
+// encode, complex numbers, and a function for each interface type reachable
+// from cinfos, which gofuzz can't fuzz by itself.
+func fuzzFuncs(cdc *amino.Codec, cinfos []*amino.TypeInfo) ([]interface{}, error) {
+	var fuzzFuncs = []interface{}{
+		func(t *time.Time, c fuzz.Continue) {
+			s := minSeconds + c.Int63n(maxSeconds-minSeconds)
+			*t = time.Unix(s, c.Int63n(1e9)).UTC()
+		},
+		func(x *complex64, c fuzz.Continue) {
+			*x = complex(c.Float32(), c.Float32())
+		},
+		func(x *complex128, c fuzz.Continue) {
+			*x = complex(c.Float64(), c.Float64())
+		},
+	}
+
+	var seen = make(map[reflect.Type]bool)
+	var irts []reflect.Type
+	for _, cinfo := range cinfos {
+		irts = collectInterfaceTypes(cinfo.Type, seen, irts)
+	}
+	for _, irt := range irts {
+		fn, err := interfaceFuzzFunc(cdc, irt)
+		if err != nil {
+			return nil, err
+		}
+		fuzzFuncs = append(fuzzFuncs, fn)
+	}
+	return fuzzFuncs, nil
+}
+
+// Returns a gofuzz function of type func(*I, fuzz.Continue) for the
+// interface type irt, which sets it to a fuzzed registered implementer.
+func interfaceFuzzFunc(cdc *amino.Codec, irt reflect.Type) (interface{}, error) {
+	var cinfos []*amino.TypeInfo
+	impls, err := cdc.Implementers(irt)
+	if err == nil {
+		for _, cinfo := range impls {
+			// Skip those that only implement irt by pointer, yet would be
+			// decoded as values.
+			if cinfo.PointerPreferred || cinfo.Type.Implements(irt) {
+				cinfos = append(cinfos, cinfo)
+			}
+		}
+	} // else unregistered interfaces can only be nil.
+
+	var fnType = reflect.FuncOf([]reflect.Type{reflect.PtrTo(irt), reflect.TypeOf(fuzz.Continue{})}, nil, false)
+	var fn = reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		irv := args[0].Elem()
+		c := args[1].Interface().(fuzz.Continue)
+		if len(cinfos) == 0 || c.Float64() < nilChance {
+			irv.Set(reflect.Zero(irt))
+			return nil
+		}
+		cinfo := cinfos[c.Intn(len(cinfos))]
+		cptr := reflect.New(cinfo.Type)
+		c.Fuzz(cptr.Interface())
+		if cinfo.PointerPreferred {
+			irv.Set(cptr)
+		} else {
+			irv.Set(cptr.Elem())
+		}
+		return nil
+	})
+	return fn.Interface(), nil
+}
+
+// Appends to irts the interface types reachable from rt through its
+// elements and exported fields, unless already seen.
+func collectInterfaceTypes(rt reflect.Type, seen map[reflect.Type]bool, irts []reflect.Type) []reflect.Type {
+	if seen[rt] {
+		return irts
+	}
+	seen[rt] = true
+
+	switch rt.Kind() {
+	case reflect.Interface:
+		irts = append(irts, rt)
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		irts = collectInterfaceTypes(rt.Elem(), seen, irts)
+	case reflect.Map:
+		irts = collectInterfaceTypes(rt.Key(), seen, irts)
+		irts = collectInterfaceTypes(rt.Elem(), seen, irts)
+	case reflect.Struct:
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if field.PkgPath != "" {
+				continue // Unexported, so not fuzzed.
+			}
+			irts = collectInterfaceTypes(field.Type, seen, irts)
+		}
+	}
+	return irts
+}
+
+// Returns whether rv is a default value, which amino doesn't encode, e.g. 0
+// or "".
+func isDefaultValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint() == 0
+	case reflect.String:
+		return rv.Len() == 0
+	case reflect.Chan, reflect.Map, reflect.Slice:
+		return rv.IsNil() || rv.Len() == 0
+	case reflect.Func, reflect.Interface, reflect.Ptr:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
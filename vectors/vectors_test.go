@@ -0,0 +1,145 @@
+package vectors_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/go-amino/tests"
+	"github.com/tendermint/go-amino/vectors"
+)
+
+type Transport struct {
+	Vehicle
+	Capacity int
+}
+
+type Vehicle interface {
+	Move() error
+}
+
+type Car string
+type Plane struct {
+	Name        string
+	MaxAltitude int64
+}
+
+func (c Car) Move() error   { return nil }
+func (p Plane) Move() error { return nil }
+
+func registerTransports(cdc *amino.Codec) {
+	cdc.RegisterConcrete(&Transport{}, "our/transport", nil)
+	cdc.RegisterInterface((*Vehicle)(nil), nil)
+	cdc.RegisterConcrete(Car(""), "car", nil)
+	cdc.RegisterConcrete(Plane{}, "plane", nil)
+}
+
+func TestVectors(t *testing.T) {
+	// Only struct implementers, since binary decoding doesn't support e.g.
+	// Car in an interface field.
+	var cdc = amino.NewCodec()
+	cdc.RegisterConcrete(&Transport{}, "our/transport", nil)
+	cdc.RegisterInterface((*Vehicle)(nil), nil)
+	cdc.RegisterConcrete(Plane{}, "plane", nil)
+	var types = map[string]reflect.Type{}
+	for _, ptr := range tests.StructTypes {
+		rt := reflect.TypeOf(ptr).Elem()
+		cdc.RegisterConcrete(ptr, "tests/"+rt.Name(), nil)
+		types["tests/"+rt.Name()] = rt
+	}
+	types["our/transport"] = reflect.TypeOf(Transport{})
+	types["plane"] = reflect.TypeOf(Plane{})
+
+	vs, err := vectors.Vectors(cdc, &vectors.Options{Seed: 1, Samples: 3})
+	require.NoError(t, err)
+	again, err := vectors.Vectors(cdc, &vectors.Options{Seed: 1, Samples: 3})
+	require.NoError(t, err)
+	assert.Equal(t, vs, again, "vectors should be deterministic")
+	other, err := vectors.Vectors(cdc, &vectors.Options{Seed: 2, Samples: 3})
+	require.NoError(t, err)
+	assert.NotEqual(t, vs, other)
+
+	// Every vector decodes from each encoding and encodes the same way again.
+	var haveInterfaces bool
+	for _, v := range vs {
+		rt := types[v.Name]
+		fromJSON := reflect.New(rt).Interface()
+		require.NoError(t, cdc.UnmarshalJSON(v.JSON, fromJSON), v.Name)
+		bz, err := cdc.MarshalBinaryBare(fromJSON)
+		require.NoError(t, err, v.Name)
+		assert.Equal(t, v.BinaryHex, hex.EncodeToString(bz), v.Name)
+
+		lp, err := hex.DecodeString(v.LengthPrefixedHex)
+		require.NoError(t, err)
+		fromBinary := reflect.New(rt).Interface()
+		require.NoError(t, cdc.UnmarshalBinaryLengthPrefixed(lp, fromBinary), v.Name)
+		bz, err = cdc.MarshalBinaryBare(fromBinary)
+		require.NoError(t, err, v.Name)
+		assert.Equal(t, v.BinaryHex, hex.EncodeToString(bz), v.Name)
+
+		if tr, ok := fromJSON.(*Transport); ok && tr.Vehicle != nil {
+			haveInterfaces = true
+		}
+	}
+	assert.True(t, haveInterfaces, "expected some non-nil interface fields")
+
+	// Types that can't be encoded in binary, e.g. maps, are errors.
+	cdc = amino.NewCodec()
+	cdc.RegisterConcrete(struct{ M map[string]string }{}, "map", nil)
+	_, err = vectors.Vectors(cdc, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "sample 0 of map: cannot encode: unsupported type map")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+
+	dir, err := ioutil.TempDir("", "vectors")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// Stale vectors are removed, but not other files.
+	for _, file := range []string{"our_transport-9.json", "registry.json"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, file), []byte("{}"), 0644))
+	}
+	require.NoError(t, vectors.Generate(cdc, dir, &vectors.Options{Samples: 2}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 7)
+	assert.NotContains(t, files, filepath.Join(dir, "our_transport-9.json"))
+	assert.Contains(t, files, filepath.Join(dir, "registry.json"))
+
+	bz, err := ioutil.ReadFile(filepath.Join(dir, "our_transport-1.json"))
+	require.NoError(t, err)
+	var v vectors.Vector
+	require.NoError(t, json.Unmarshal(bz, &v))
+	assert.Equal(t, "our/transport", v.Name)
+	assert.Equal(t, "github.com/tendermint/go-amino/vectors_test.Transport", v.GoType)
+	vs, err := vectors.Vectors(cdc, &vectors.Options{Samples: 2})
+	require.NoError(t, err)
+	assert.JSONEq(t, string(vs[1].JSON), string(v.JSON))
+	assert.Equal(t, vs[1].BinaryHex, v.BinaryHex)
+	assert.Equal(t, vs[1].LengthPrefixedHex, v.LengthPrefixedHex)
+
+	// They are read back in order of name.
+	sort.SliceStable(vs, func(i, j int) bool { return vs[i].Name < vs[j].Name })
+	read, err := vectors.Read(dir)
+	require.NoError(t, err)
+	require.Len(t, read, len(vs))
+	for i, v := range read {
+		assert.Equal(t, vs[i].Name, v.Name)
+		assert.JSONEq(t, string(vs[i].JSON), string(v.JSON), v.Name)
+		assert.Equal(t, vs[i].BinaryHex, v.BinaryHex, v.Name)
+	}
+}
@@ -0,0 +1,200 @@
+package amino
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+//----------------------------------------
+// Walk
+
+// WalkAction is returned by a WalkFunc to tell Walk how to go on.
+type WalkAction int
+
+const (
+	WalkContinue WalkAction = iota // Step into the value, if it has fields or elements.
+	WalkSkip                       // Don't step into the value.
+	WalkStop                       // Stop walking.
+)
+
+// WalkFunc is called by Walk for each value.  Path is the path of v (see
+// ParsePath), and info is the struct field v is, or of the list or map v is
+// an element of, or nil.  If Walk was given a pointer, v can be set in place,
+// and Walk then steps into the new value.  Otherwise what is set in map
+// elements and interface values, which are copies, is not set back.
+type WalkFunc func(path Path, v reflect.Value, info *FieldInfo) (WalkAction, error)
+
+// Walk calls fn for o, then for each value within it, in amino field order,
+// outermost first.  Its paths are those of the amino JSON of o, as GetPath,
+// so `amino:"-json"` fields are not walked:
+//   - A non-nil interface value steps into "type", the registered name of its
+//     concrete value as a string (which can't be set), then into "value", its
+//     concrete value.
+//   - The fields or elements of a value with `.MarshalAmino()` are those of
+//     its repr.  Values set within the repr are set in the value by
+//     `.UnmarshalAmino()`.
+//   - Map elements are stepped into in order of their keys as strings.
+//
+// Nil pointers and interface values are not stepped into.  If fn returns an
+// error, Walk stops and returns it.
+func (cdc *Codec) Walk(o interface{}, fn WalkFunc) error {
+	var rv = reflect.ValueOf(o)
+	var set = rv.Kind() == reflect.Ptr
+	if set {
+		if rv.IsNil() {
+			return errors.New("Walk cannot walk a nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	err := cdc.walk(nil, rv, nil, set, fn)
+	if err == errWalkStop {
+		return nil
+	}
+	return err
+}
+
+var errWalkStop = errors.New("stop walking")
+
+// If set, rv is settable, and what fn sets within it is set back.
+func (cdc *Codec) walk(path Path, rv reflect.Value, finfo *FieldInfo, set bool, fn WalkFunc) error {
+	action, err := fn(path, rv, finfo)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case WalkSkip:
+		return nil
+	case WalkStop:
+		return errWalkStop
+	}
+	return cdc.walkWithin(path, rv, finfo, set, fn)
+}
+
+// Walks the values within rv, but not rv itself.
+func (cdc *Codec) walkWithin(path Path, rv reflect.Value, finfo *FieldInfo, set bool, fn WalkFunc) error {
+	var drv, _, isNilPtr = derefPointers(rv)
+	if isNilPtr {
+		return nil
+	}
+
+	if drv.Kind() == reflect.Interface {
+		if drv.IsNil() {
+			return nil
+		}
+		var crv, _, isNilPtr = derefPointers(drv.Elem())
+		if isNilPtr {
+			return fmt.Errorf("cannot walk nil-pointer interface value at %v", path.describe())
+		}
+		cinfo, err := cdc.getTypeInfoWlock(crv.Type())
+		if err != nil {
+			return err
+		}
+		if !cinfo.Registered {
+			return fmt.Errorf("cannot walk unregistered concrete type %v at %v", crv.Type(), path.describe())
+		}
+		action, err := fn(path.child(PathStep{Name: "type"}), reflect.ValueOf(cinfo.Name), nil)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case WalkSkip:
+			return nil
+		case WalkStop:
+			return errWalkStop
+		}
+		// Walk a settable copy, and set it back.
+		var cpy = reflect.New(drv.Elem().Type()).Elem()
+		cpy.Set(drv.Elem())
+		if err = cdc.walk(path.child(PathStep{Name: "value"}), cpy, nil, set, fn); err != nil && err != errWalkStop {
+			return err
+		}
+		if set {
+			drv.Set(cpy)
+		}
+		return err
+	}
+
+	info, err := cdc.getTypeInfoWlock(drv.Type())
+	if err != nil {
+		return err
+	}
+
+	if info.IsAminoMarshaler {
+		rrv, err := toReprObject(drv)
+		if err != nil {
+			return err
+		}
+		// Walk a settable copy of the repr, and unmarshal it if changed.
+		var repr = reflect.New(rrv.Type()).Elem()
+		repr.Set(rrv)
+		var orig = DeepCopy(rrv.Interface())
+		if err = cdc.walkWithin(path, repr, finfo, set, fn); err != nil && err != errWalkStop {
+			return err
+		}
+		if !reflect.DeepEqual(orig, repr.Interface()) {
+			if !set || !info.IsAminoUnmarshaler {
+				return fmt.Errorf("cannot set the repr of %v at %v", drv.Type(), path.describe())
+			}
+			uwrm := drv.Addr().MethodByName("UnmarshalAmino")
+			uwouts := uwrm.Call([]reflect.Value{repr})
+			if erri := uwouts[0].Interface(); erri != nil {
+				return erri.(error)
+			}
+		}
+		return err
+	}
+
+	switch drv.Kind() {
+	case reflect.Array, reflect.Slice:
+		if drv.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < drv.Len(); i++ {
+			if err = cdc.walk(path.child(PathStep{Index: i}), drv.Index(i), finfo, set, fn); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		var keys = drv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			// Walk a settable copy, and set it back.
+			var cpy = reflect.New(drv.Type().Elem()).Elem()
+			cpy.Set(drv.MapIndex(key))
+			err = cdc.walk(path.child(PathStep{Name: fmt.Sprint(key.Interface())}), cpy, finfo, set, fn)
+			if err != nil && err != errWalkStop {
+				return err
+			}
+			if set {
+				drv.SetMapIndex(key, cpy)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		if info.Type == timeType {
+			return nil
+		}
+		for i := range info.Fields {
+			var field = &info.Fields[i]
+			if field.JSONSkip {
+				continue // e.g. amino:"-json", as in paths.
+			}
+			err = cdc.walk(path.child(PathStep{Name: field.JSONName}), drv.Field(field.Index), field, set, fn)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package amino_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// walkRepr is encoded as its words.
+type walkRepr struct {
+	words string
+}
+
+func (r walkRepr) MarshalAmino() ([]string, error) { return strings.Fields(r.words), nil }
+func (r *walkRepr) UnmarshalAmino(words []string) error {
+	r.words = strings.Join(words, " ")
+	return nil
+}
+
+type walkStruct struct {
+	Name     string      `json:"name"`
+	Secret   string      `json:"secret"`
+	Vehicles []Vehicle   `json:"vehicles"`
+	Repr     walkRepr    `json:"repr"`
+	Next     *walkStruct `json:"next"`
+	Bytes    []byte
+	Skipped  string `json:"-"`
+	Hidden   string `amino:"-json"`
+}
+
+func TestWalk(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+
+	var o = walkStruct{
+		Name:     "a",
+		Vehicles: []Vehicle{Car("Tesla"), &Transport{Vehicle: Plane{Name: "Concorde"}}, nil},
+		Repr:     walkRepr{"b c"},
+		Next:     &walkStruct{Secret: "s"},
+		Bytes:    []byte{1},
+		Hidden:   "h",
+	}
+
+	var visits, names []string
+	err := cdc.Walk(o, func(path amino.Path, v reflect.Value, info *amino.FieldInfo) (amino.WalkAction, error) {
+		var field = "-"
+		if info != nil {
+			field = info.Name
+		}
+		visits = append(visits, fmt.Sprintf("%v (%v) %v", path, field, v.Type()))
+		if strings.HasSuffix(path.String(), ".type") {
+			names = append(names, v.String())
+		}
+		return amino.WalkContinue, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		" (-) amino_test.walkStruct",
+		"name (Name) string",
+		"secret (Secret) string",
+		"vehicles (Vehicles) []amino_test.Vehicle",
+		"vehicles[0] (Vehicles) amino_test.Vehicle",
+		"vehicles[0].type (-) string",
+		"vehicles[0].value (-) amino_test.Car",
+		"vehicles[1] (Vehicles) amino_test.Vehicle",
+		"vehicles[1].type (-) string",
+		"vehicles[1].value (-) *amino_test.Transport",
+		"vehicles[1].value.Vehicle (Vehicle) amino_test.Vehicle",
+		"vehicles[1].value.Vehicle.type (-) string",
+		"vehicles[1].value.Vehicle.value (-) amino_test.Plane",
+		"vehicles[1].value.Vehicle.value.Name (Name) string",
+		"vehicles[1].value.Vehicle.value.MaxAltitude (MaxAltitude) int64",
+		"vehicles[1].value.Capacity (Capacity) int",
+		"vehicles[2] (Vehicles) amino_test.Vehicle",
+		"repr (Repr) amino_test.walkRepr",
+		"repr[0] (Repr) string",
+		"repr[1] (Repr) string",
+		"next (Next) *amino_test.walkStruct",
+		"next.name (Name) string",
+		"next.secret (Secret) string",
+		"next.vehicles (Vehicles) []amino_test.Vehicle",
+		"next.repr (Repr) amino_test.walkRepr",
+		"next.next (Next) *amino_test.walkStruct",
+		"next.Bytes (Bytes) []uint8",
+		"Bytes (Bytes) []uint8",
+	}, visits)
+	assert.Equal(t, []string{"car", "our/transport", "plane"}, names)
+
+	// The paths are those of GetPath.
+	err = cdc.Walk(o, func(path amino.Path, v reflect.Value, info *amino.FieldInfo) (amino.WalkAction, error) {
+		got, err := cdc.GetPath(o, path.String())
+		if assert.NoError(t, err, path.String()) {
+			assert.Equal(t, v.Interface(), got, path.String())
+		}
+		return amino.WalkContinue, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestWalkSet(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+
+	var o = walkStruct{
+		Secret:   "s1",
+		Vehicles: []Vehicle{Car("Tesla"), &Transport{Vehicle: Boat("Poseidon")}},
+		Repr:     walkRepr{"secret words"},
+		Next:     &walkStruct{Secret: "s2"},
+	}
+	// Redact secrets, cars and words.
+	err := cdc.Walk(&o, func(path amino.Path, v reflect.Value, info *amino.FieldInfo) (amino.WalkAction, error) {
+		switch {
+		case info != nil && info.JSONName == "secret":
+			v.SetString("***")
+		case v.Type() == reflect.TypeOf(Car("")):
+			v.Set(reflect.ValueOf(Car("***")))
+		case info != nil && info.JSONName == "repr" && v.Kind() == reflect.String:
+			v.SetString("***")
+		case path.String() == "vehicles[1].value":
+			v.Elem().FieldByName("Capacity").SetInt(5)
+		}
+		return amino.WalkContinue, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, walkStruct{
+		Secret:   "***",
+		Vehicles: []Vehicle{Car("***"), &Transport{Vehicle: Boat("Poseidon"), Capacity: 5}},
+		Repr:     walkRepr{"*** ***"},
+		Next:     &walkStruct{Secret: "***"},
+	}, o)
+
+	// The set value is walked.
+	o = walkStruct{}
+	var names []string
+	err = cdc.Walk(&o, func(path amino.Path, v reflect.Value, info *amino.FieldInfo) (amino.WalkAction, error) {
+		if path.String() == "next" {
+			v.Set(reflect.ValueOf(&walkStruct{Name: "new"}))
+		}
+		if info != nil && info.JSONName == "name" {
+			names = append(names, v.String())
+		}
+		return amino.WalkContinue, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "new"}, names)
+
+	// Without a pointer, map elements and interface values aren't set back.
+	var tags = map[string]string{"k": "v"}
+	o = walkStruct{Vehicles: []Vehicle{Car("Tesla")}}
+	redact := func(path amino.Path, v reflect.Value, info *amino.FieldInfo) (amino.WalkAction, error) {
+		if v.Kind() == reflect.String && v.CanSet() { // Not types.
+			v.SetString("***")
+		}
+		return amino.WalkContinue, nil
+	}
+	require.NoError(t, cdc.Walk(tags, redact))
+	assert.Equal(t, map[string]string{"k": "v"}, tags)
+	require.NoError(t, cdc.Walk(o, redact))
+	assert.Equal(t, []Vehicle{Car("Tesla")}, o.Vehicles)
+
+	// Types can't be set.
+	o = walkStruct{Vehicles: []Vehicle{Car("Tesla")}}
+	assert.Panics(t, func() {
+		_ = cdc.Walk(&o, func(path amino.Path, v reflect.Value, info *amino.FieldInfo) (amino.WalkAction, error) {
+			if path.String() == "vehicles[0].type" {
+				v.SetString("boat")
+			}
+			return amino.WalkContinue, nil
+		})
+	})
+}
+
+func TestWalkActions(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+
+	var o = walkStruct{
+		Vehicles: []Vehicle{Car("Tesla"), Boat("Poseidon")},
+		Next:     &walkStruct{},
+	}
+	var paths []string
+	walk := func(action func(path string) amino.WalkAction) error {
+		paths = nil
+		return cdc.Walk(o, func(path amino.Path, v reflect.Value, info *amino.FieldInfo) (amino.WalkAction, error) {
+			paths = append(paths, path.String())
+			if path.String() == "Bytes" {
+				return amino.WalkContinue, errors.New("uh oh")
+			}
+			return action(path.String()), nil
+		})
+	}
+
+	err := walk(func(path string) amino.WalkAction {
+		if path == "vehicles" || path == "next" {
+			return amino.WalkSkip
+		}
+		return amino.WalkContinue
+	})
+	assert.EqualError(t, err, "uh oh")
+	assert.Equal(t, []string{"", "name", "secret", "vehicles", "repr", "next", "Bytes"}, paths)
+
+	err = walk(func(path string) amino.WalkAction {
+		if path == "vehicles[0].type" {
+			return amino.WalkSkip
+		}
+		if path == "vehicles[1].value" {
+			return amino.WalkStop
+		}
+		return amino.WalkContinue
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "name", "secret", "vehicles",
+		"vehicles[0]", "vehicles[0].type", "vehicles[1]", "vehicles[1].type", "vehicles[1].value"}, paths)
+
+	err = amino.NewCodec().Walk(o, func(amino.Path, reflect.Value, *amino.FieldInfo) (amino.WalkAction, error) {
+		return amino.WalkContinue, nil
+	})
+	assert.EqualError(t, err, "cannot walk unregistered concrete type amino_test.Car at vehicles[0]")
+}
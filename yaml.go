@@ -0,0 +1,223 @@
+package amino
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+//----------------------------------------
+// YAML
+
+// MarshalYAML encodes o as YAML.  It is a bridge over amino JSON, not a
+// Format: o is encoded by MarshalJSON, and the JSON is converted to YAML.  So
+// the YAML follows the same amino rules: interface values are wrapped in
+// type/value maps, 64-bit ints are quoted, times are in UTC, and fields are
+// named by their JSONName, in order.
+func (cdc *Codec) MarshalYAML(o interface{}) ([]byte, error) {
+	bz, err := cdc.MarshalJSON(o)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	node, err := jsonToYAMLNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err = enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err = enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// MustMarshalYAML panics if an error occurs. Besides that behaves exactly like MarshalYAML.
+func (cdc *Codec) MustMarshalYAML(o interface{}) []byte {
+	bz, err := cdc.MarshalYAML(o)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// UnmarshalYAML decodes the YAML document bz into ptr by converting it to
+// JSON and decoding that by UnmarshalJSON.  Comments, anchors and aliases are
+// allowed, but merge keys (<<) are not, and the amino rules still apply, e.g.
+// 64-bit ints must be quoted.
+func (cdc *Codec) UnmarshalYAML(bz []byte, ptr interface{}) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(bz, &doc); err != nil {
+		return err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 {
+		return errors.New("cannot decode empty YAML document")
+	}
+	var jsonBz bytes.Buffer
+	if err := yamlNodeToJSON(&jsonBz, doc.Content[0]); err != nil {
+		return err
+	}
+	return cdc.UnmarshalJSON(jsonBz.Bytes(), ptr)
+}
+
+// MustUnmarshalYAML panics if an error occurs. Besides that behaves exactly like UnmarshalYAML.
+func (cdc *Codec) MustUnmarshalYAML(bz []byte, ptr interface{}) {
+	if err := cdc.UnmarshalYAML(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+//----------------------------------------
+// JSON <-> YAML
+
+// Reads the next JSON value from dec as a YAML node, keeping the order of
+// object keys.
+func jsonToYAMLNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		var node = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if tok == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				var key json.Token
+				key, err = dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			var elem *yaml.Node
+			elem, err = jsonToYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, elem)
+		}
+		// Consume the closing delimiter.
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		// Strings that look like other values, e.g. quoted ints, get quoted.
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tok}, nil
+	case json.Number:
+		var tag = "!!int"
+		if strings.ContainsAny(tok.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: tok.String()}, nil
+	case bool:
+		var value = "false"
+		if tok {
+			value = "true"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	default:
+		return nil, errors.Errorf("unexpected JSON token %v", tok)
+	}
+}
+
+// Writes node as JSON to w.
+func yamlNodeToJSON(w io.Writer, node *yaml.Node) (err error) {
+	switch node.Kind {
+
+	case yaml.AliasNode:
+		return yamlNodeToJSON(w, node.Alias)
+
+	case yaml.MappingNode:
+		if err = writeStr(w, `{`); err != nil {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return errors.Errorf("line %v: YAML map keys must be scalars", key.Line)
+			}
+			if key.ShortTag() == "!!merge" {
+				return errors.Errorf("line %v: YAML merge keys (<<) are not supported", key.Line)
+			}
+			if i > 0 {
+				if err = writeStr(w, `,`); err != nil {
+					return
+				}
+			}
+			if err = writeJSONValue(w, key.Value); err != nil {
+				return
+			}
+			if err = writeStr(w, `:`); err != nil {
+				return
+			}
+			if err = yamlNodeToJSON(w, node.Content[i+1]); err != nil {
+				return
+			}
+		}
+		return writeStr(w, `}`)
+
+	case yaml.SequenceNode:
+		if err = writeStr(w, `[`); err != nil {
+			return
+		}
+		for i, elem := range node.Content {
+			if i > 0 {
+				if err = writeStr(w, `,`); err != nil {
+					return
+				}
+			}
+			if err = yamlNodeToJSON(w, elem); err != nil {
+				return
+			}
+		}
+		return writeStr(w, `]`)
+
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			return writeStr(w, `null`)
+		case "!!str", "!!timestamp":
+			return writeJSONValue(w, node.Value)
+		case "!!binary":
+			// Amino JSON bytes are base64 too.
+			return writeJSONValue(w, strings.Join(strings.Fields(node.Value), ""))
+		case "!!bool", "!!int", "!!float":
+			// Decode to normalize, e.g. 0x10 or yes.
+			var v interface{}
+			if err = node.Decode(&v); err != nil {
+				return
+			}
+			return writeJSONValue(w, v)
+		default:
+			return errors.Errorf("line %v: unsupported YAML tag %v", node.Line, node.Tag)
+		}
+
+	default:
+		return errors.Errorf("line %v: unexpected YAML node", node.Line)
+	}
+}
+
+func writeJSONValue(w io.Writer, v interface{}) error {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bz)
+	return err
+}
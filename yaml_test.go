@@ -0,0 +1,125 @@
+package amino_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+type yamlGenesis struct {
+	ChainID    string          `json:"chain_id"`
+	Genesis    time.Time       `json:"genesis_time"`
+	Height     int64           `json:"height"`
+	Validators []yamlValidator `json:"validators"`
+	Transports []*Transport    `json:"transports,omitempty"`
+}
+
+type yamlValidator struct {
+	PubKey []byte `json:"pub_key"`
+	Power  int32  `json:"power"`
+	Asset  Asset  `json:"asset"`
+}
+
+func TestMarshalYAML(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+	cdc.RegisterConcrete(yamlGenesis{}, "our/genesis", nil)
+
+	genesis := yamlGenesis{
+		ChainID: "test-1",
+		Genesis: time.Date(2019, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+		Height:  9007199254740993,
+		Validators: []yamlValidator{
+			{PubKey: []byte{1, 2, 3}, Power: 10, Asset: Car("Tesla")},
+			{Power: -1, Asset: insurancePlan(5)},
+		},
+		Transports: []*Transport{{Vehicle: Boat("Poseidon"), Capacity: 1234}},
+	}
+	bz, err := cdc.MarshalYAML(genesis)
+	require.NoError(t, err)
+	assert.Equal(t, `type: our/genesis
+value:
+  chain_id: test-1
+  genesis_time: "2019-01-02T02:04:05Z"
+  height: "9007199254740993"
+  validators:
+    - pub_key: AQID
+      power: 10
+      asset:
+        type: car
+        value: Tesla
+    - pub_key: null
+      power: -1
+      asset:
+        type: insuranceplan
+        value: "5"
+  transports:
+    - Vehicle:
+        type: boat
+        value: Poseidon
+      Capacity: "1234"
+`, string(bz))
+
+	// The YAML is the JSON, restructured.
+	jsonBz, err := cdc.MarshalJSON(genesis)
+	require.NoError(t, err)
+	var fromYAML, fromJSON yamlGenesis
+	require.NoError(t, cdc.UnmarshalYAML(bz, &fromYAML))
+	require.NoError(t, cdc.UnmarshalJSON(jsonBz, &fromJSON))
+	assert.Equal(t, fromJSON, fromYAML)
+	assert.Equal(t, genesis.Genesis.UTC(), fromYAML.Genesis)
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	var cdc = amino.NewCodec()
+	registerTransports(cdc)
+	cdc.RegisterConcrete(yamlGenesis{}, "our/genesis", nil)
+
+	// Comments, anchors, flow style and unquoted times are allowed.
+	var genesis yamlGenesis
+	err := cdc.UnmarshalYAML([]byte(`# Genesis file.
+type: our/genesis
+value:
+  chain_id: 'test-1'
+  genesis_time: 2019-01-02T03:04:05Z
+  height: "12" # 64-bit ints are quoted.
+  validators:
+    - &val
+      pub_key: !!binary AQID
+      power: 0x10
+      asset: {type: car, value: Tesla}
+    - *val
+`), &genesis)
+	require.NoError(t, err)
+	val := yamlValidator{PubKey: []byte{1, 2, 3}, Power: 16, Asset: Car("Tesla")}
+	assert.Equal(t, yamlGenesis{
+		ChainID:    "test-1",
+		Genesis:    time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+		Height:     12,
+		Validators: []yamlValidator{val, val},
+	}, genesis)
+
+	cases := []struct {
+		yaml string
+		err  string
+	}{
+		{"", "empty YAML document"},
+		{"type: car\nvalue: Tesla", "wanted to decode our/genesis but found car"},
+		{"type: our/genesis\nvalue: {height: 12}", "expects quoted values"},
+		{"type: our/genesis\nvalue: {chain_id: !foo x}", "unsupported YAML tag !foo"},
+		{"type: our/genesis\nvalue: {[a]: x}", "map keys must be scalars"},
+		{"type: our/genesis\nvalue: {validators: [&val {power: 1}, {<<: *val}]}", "line 2: YAML merge keys (<<) are not supported"},
+		{"type: our/genesis\nvalue: [", "did not find expected node content"},
+	}
+	for _, tc := range cases {
+		var genesis yamlGenesis
+		err := cdc.UnmarshalYAML([]byte(tc.yaml), &genesis)
+		if assert.Error(t, err, tc.yaml) {
+			assert.Contains(t, err.Error(), tc.err, tc.yaml)
+		}
+	}
+}